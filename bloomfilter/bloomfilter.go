@@ -0,0 +1,236 @@
+package bloomfilter
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cespare/xxhash/v2"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+const (
+	//Size of a block in bytes
+	BlockBytes = 32
+	//Bounds of the bitset size of a filter in bytes
+	MinNumBytes = BlockBytes
+	MaxNumBytes = 128 * 1024 * 1024
+	//Default false positive probability
+	DefaultFPP = 0.01
+)
+
+var salt = [8]uint32{
+	0x47b6137b, 0x44974d91, 0x8824ad5b, 0xa2b7289d,
+	0x705495c7, 0x2df1424b, 0x9efc4947, 0x5c6bfb31,
+}
+
+// Block is a 256 bits block of a split block bloom filter
+type Block [8]uint32
+
+func mask(x uint32) Block {
+	var res Block
+	for i := 0; i < len(salt); i++ {
+		res[i] = 1 << ((x * salt[i]) >> 27)
+	}
+	return res
+}
+
+func (b *Block) insert(x uint32) {
+	m := mask(x)
+	for i := 0; i < len(b); i++ {
+		b[i] |= m[i]
+	}
+}
+
+func (b *Block) check(x uint32) bool {
+	m := mask(x)
+	for i := 0; i < len(b); i++ {
+		if b[i]&m[i] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SplitBlockFilter is the split block bloom filter of the parquet format
+type SplitBlockFilter struct {
+	Blocks []Block
+}
+
+// New creates an empty filter of numBytes bytes, which is rounded up to a power of 2
+func New(numBytes int) *SplitBlockFilter {
+	if numBytes < MinNumBytes {
+		numBytes = MinNumBytes
+	}
+	if numBytes > MaxNumBytes {
+		numBytes = MaxNumBytes
+	}
+	n := MinNumBytes
+	for n < numBytes {
+		n <<= 1
+	}
+	return &SplitBlockFilter{Blocks: make([]Block, n/BlockBytes)}
+}
+
+// OptimalNumBytes returns the size of a filter for ndv distinct values with a
+// false positive probability of fpp
+func OptimalNumBytes(ndv int64, fpp float64) int {
+	if fpp <= 0 || fpp >= 1 {
+		fpp = DefaultFPP
+	}
+	bits := -8 * float64(ndv) / math.Log(1-math.Pow(fpp, 1.0/8))
+	if bits >= MaxNumBytes*8 {
+		return MaxNumBytes
+	}
+	return New(int(bits/8) + 1).NumBytes()
+}
+
+// NumBytes returns the size of the bitset of the filter
+func (f *SplitBlockFilter) NumBytes() int {
+	return len(f.Blocks) * BlockBytes
+}
+
+func (f *SplitBlockFilter) block(hash uint64) *Block {
+	return &f.Blocks[((hash>>32)*uint64(len(f.Blocks)))>>32]
+}
+
+// Insert adds a hash to the filter
+func (f *SplitBlockFilter) Insert(hash uint64) {
+	f.block(hash).insert(uint32(hash))
+}
+
+// Check returns false if the hash was never inserted in the filter
+func (f *SplitBlockFilter) Check(hash uint64) bool {
+	return f.block(hash).check(uint32(hash))
+}
+
+// Hash returns the xxHash of the plain encoding of a value. The value must have
+// the go type of a parquet physical type; the length of byte arrays isn't hashed.
+func Hash(value interface{}) uint64 {
+	var buf [8]byte
+	switch v := value.(type) {
+	case bool:
+		if v {
+			buf[0] = 1
+		}
+		return xxhash.Sum64(buf[:1])
+	case int32:
+		binary.LittleEndian.PutUint32(buf[:4], uint32(v))
+		return xxhash.Sum64(buf[:4])
+	case int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		return xxhash.Sum64(buf[:])
+	case float32:
+		binary.LittleEndian.PutUint32(buf[:4], math.Float32bits(v))
+		return xxhash.Sum64(buf[:4])
+	case float64:
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		return xxhash.Sum64(buf[:])
+	case string:
+		return xxhash.Sum64String(v)
+	case []byte:
+		return xxhash.Sum64(v)
+	}
+	panic(fmt.Errorf("unsupported bloom filter value type %T", value))
+}
+
+func newHeader(numBytes int32) *parquet.BloomFilterHeader {
+	header := parquet.NewBloomFilterHeader()
+	header.NumBytes = numBytes
+	header.Algorithm = parquet.NewBloomFilterAlgorithm()
+	header.Algorithm.BLOCK = parquet.NewSplitBlockAlgorithm()
+	header.Hash = parquet.NewBloomFilterHash()
+	header.Hash.XXHASH = parquet.NewXxHash()
+	header.Compression = parquet.NewBloomFilterCompression()
+	header.Compression.UNCOMPRESSED = parquet.NewUncompressed()
+	return header
+}
+
+// MarshalHeader returns the BloomFilterHeader of the filter
+func (f *SplitBlockFilter) MarshalHeader() ([]byte, error) {
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	return ts.Write(context.TODO(), newHeader(int32(f.NumBytes())))
+}
+
+// MarshalBitset returns the bitset of the filter
+func (f *SplitBlockFilter) MarshalBitset() []byte {
+	res := make([]byte, 0, f.NumBytes())
+	var buf [4]byte
+	for i := range f.Blocks {
+		for _, word := range f.Blocks[i] {
+			binary.LittleEndian.PutUint32(buf[:], word)
+			res = append(res, buf[:]...)
+		}
+	}
+	return res
+}
+
+// Marshal returns the BloomFilterHeader followed by the bitset of the filter
+func (f *SplitBlockFilter) Marshal() ([]byte, error) {
+	headerBuf, err := f.MarshalHeader()
+	if err != nil {
+		return nil, err
+	}
+	return append(headerBuf, f.MarshalBitset()...), nil
+}
+
+// Check that a filter is supported and return the size of its bitset
+func checkHeader(header *parquet.BloomFilterHeader) (int32, error) {
+	if !header.GetAlgorithm().IsSetBLOCK() || !header.GetHash().IsSetXXHASH() || !header.GetCompression().IsSetUNCOMPRESSED() {
+		return 0, fmt.Errorf("unsupported bloom filter: %v", header)
+	}
+	numBytes := header.GetNumBytes()
+	if numBytes < MinNumBytes || numBytes > MaxNumBytes || numBytes%BlockBytes != 0 {
+		return 0, fmt.Errorf("invalid bloom filter size %v", numBytes)
+	}
+	return numBytes, nil
+}
+
+// UnmarshalHeader reads a BloomFilterHeader and returns the size of the bitset
+func UnmarshalHeader(buf []byte) (int32, error) {
+	td := thrift.NewTDeserializer()
+	td.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(td.Transport)
+	header := parquet.NewBloomFilterHeader()
+	if err := td.Read(context.TODO(), header, buf); err != nil {
+		return 0, err
+	}
+	return checkHeader(header)
+}
+
+// FromBitset creates a filter from a bitset written by MarshalBitset
+func FromBitset(buf []byte) (*SplitBlockFilter, error) {
+	if len(buf) < MinNumBytes || len(buf) > MaxNumBytes || len(buf)%BlockBytes != 0 {
+		return nil, fmt.Errorf("invalid bloom filter size %v", len(buf))
+	}
+	res := &SplitBlockFilter{Blocks: make([]Block, len(buf)/BlockBytes)}
+	for i := range res.Blocks {
+		for j := range res.Blocks[i] {
+			res.Blocks[i][j] = binary.LittleEndian.Uint32(buf[(i*8+j)*4:])
+		}
+	}
+	return res, nil
+}
+
+// Read reads a filter written by Marshal
+func Read(r io.Reader) (*SplitBlockFilter, error) {
+	transport := thrift.NewStreamTransportR(r)
+	protocol := thrift.NewTCompactProtocolFactory().GetProtocol(transport)
+	header := parquet.NewBloomFilterHeader()
+	if err := header.Read(context.TODO(), protocol); err != nil {
+		return nil, err
+	}
+	numBytes, err := checkHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, numBytes)
+	if _, err := io.ReadFull(transport, buf); err != nil {
+		return nil, err
+	}
+	return FromBitset(buf)
+}
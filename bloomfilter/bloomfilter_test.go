@@ -0,0 +1,76 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumBytes(t *testing.T) {
+	assert.Equal(t, MinNumBytes, New(0).NumBytes())
+	assert.Equal(t, 64, New(33).NumBytes())
+	assert.Equal(t, 1024, New(1024).NumBytes())
+	assert.Equal(t, MaxNumBytes, New(MaxNumBytes+1).NumBytes())
+
+	assert.Equal(t, MinNumBytes, OptimalNumBytes(0, 0.01))
+	// about 9.6 bits per value for a fpp of 1%
+	assert.Equal(t, 16384, OptimalNumBytes(10000, 0.01))
+	assert.True(t, OptimalNumBytes(10000, 0.001) > OptimalNumBytes(10000, 0.01))
+	assert.Equal(t, OptimalNumBytes(10000, DefaultFPP), OptimalNumBytes(10000, 2))
+	assert.Equal(t, MaxNumBytes, OptimalNumBytes(1<<40, 0.01))
+}
+
+func TestInsertCheck(t *testing.T) {
+	n := 10000
+	filter := New(OptimalNumBytes(int64(n), 0.01))
+	for i := 0; i < n; i++ {
+		filter.Insert(Hash(int64(i)))
+	}
+	for i := 0; i < n; i++ {
+		assert.True(t, filter.Check(Hash(int64(i))))
+	}
+
+	falsePositives := 0
+	for i := n; i < 2*n; i++ {
+		if filter.Check(Hash(int64(i))) {
+			falsePositives++
+		}
+	}
+	assert.True(t, falsePositives < n/50, "too many false positives: %v", falsePositives)
+}
+
+func TestHash(t *testing.T) {
+	assert.Equal(t, uint64(0xef46db3751d8e999), Hash(""))
+	assert.Equal(t, Hash("abc"), Hash([]byte("abc")))
+	assert.NotEqual(t, Hash(int32(1)), Hash(int64(1)))
+	assert.Equal(t, Hash(int32(1)), Hash(string([]byte{1, 0, 0, 0})))
+	assert.Panics(t, func() { Hash(1) })
+}
+
+func TestMarshalRead(t *testing.T) {
+	filter := New(256)
+	for i := 0; i < 100; i++ {
+		filter.Insert(Hash(fmt.Sprintf("value_%d", i)))
+	}
+	buf, err := filter.Marshal()
+	assert.NoError(t, err)
+
+	res, err := Read(bytes.NewReader(append(buf, 1, 2, 3)))
+	assert.NoError(t, err)
+	assert.Equal(t, filter, res)
+
+	_, err = Read(bytes.NewReader(buf[:len(buf)-1]))
+	assert.Error(t, err)
+
+	// the size of the bitset must be a multiple of the block size
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	header, err := ts.Write(context.TODO(), newHeader(33))
+	assert.NoError(t, err)
+	_, err = Read(bytes.NewReader(append(header, make([]byte, 33)...)))
+	assert.Error(t, err)
+}
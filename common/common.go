@@ -56,6 +56,31 @@ type Tag struct {
 	KeyOmitStats   bool
 	ValueOmitStats bool
 
+	BloomFilter         bool
+	KeyBloomFilter      bool
+	ValueBloomFilter    bool
+	BloomFilterFPP      float64
+	KeyBloomFilterFPP   float64
+	ValueBloomFilterFPP float64
+
+	MaxDictionarySize         int64
+	KeyMaxDictionarySize      int64
+	ValueMaxDictionarySize    int64
+	MaxDictionaryEntries      int64
+	KeyMaxDictionaryEntries   int64
+	ValueMaxDictionaryEntries int64
+	FallbackEncoding          parquet.Encoding
+	KeyFallbackEncoding       parquet.Encoding
+	ValueFallbackEncoding     parquet.Encoding
+
+	//Compression codec of the column, nil for the codec of the writer
+	Compression           *parquet.CompressionCodec
+	KeyCompression        *parquet.CompressionCodec
+	ValueCompression      *parquet.CompressionCodec
+	CompressionLevel      int
+	KeyCompressionLevel   int
+	ValueCompressionLevel int
+
 	RepetitionType      parquet.FieldRepetitionType
 	KeyRepetitionType   parquet.FieldRepetitionType
 	ValueRepetitionType parquet.FieldRepetitionType
@@ -185,6 +210,90 @@ func StringToTag(tag string) (*Tag, error) {
 			if mp.ValueOmitStats, err = Str2Bool(val); err != nil {
 				return nil, fmt.Errorf("failed to parse valueomitstats: %s", err.Error())
 			}
+		case "bloomfilter":
+			if mp.BloomFilter, err = Str2Bool(val); err != nil {
+				return nil, fmt.Errorf("failed to parse bloomfilter: %s", err.Error())
+			}
+		case "keybloomfilter":
+			if mp.KeyBloomFilter, err = Str2Bool(val); err != nil {
+				return nil, fmt.Errorf("failed to parse keybloomfilter: %s", err.Error())
+			}
+		case "valuebloomfilter":
+			if mp.ValueBloomFilter, err = Str2Bool(val); err != nil {
+				return nil, fmt.Errorf("failed to parse valuebloomfilter: %s", err.Error())
+			}
+		case "bloomfilterfpp":
+			if mp.BloomFilterFPP, err = Str2FPP(val); err != nil {
+				return nil, fmt.Errorf("failed to parse bloomfilterfpp: %s", err.Error())
+			}
+		case "keybloomfilterfpp":
+			if mp.KeyBloomFilterFPP, err = Str2FPP(val); err != nil {
+				return nil, fmt.Errorf("failed to parse keybloomfilterfpp: %s", err.Error())
+			}
+		case "valuebloomfilterfpp":
+			if mp.ValueBloomFilterFPP, err = Str2FPP(val); err != nil {
+				return nil, fmt.Errorf("failed to parse valuebloomfilterfpp: %s", err.Error())
+			}
+		case "maxdictionarysize":
+			if mp.MaxDictionarySize, err = Str2Int64(val); err != nil {
+				return nil, fmt.Errorf("failed to parse maxdictionarysize: %s", err.Error())
+			}
+		case "keymaxdictionarysize":
+			if mp.KeyMaxDictionarySize, err = Str2Int64(val); err != nil {
+				return nil, fmt.Errorf("failed to parse keymaxdictionarysize: %s", err.Error())
+			}
+		case "valuemaxdictionarysize":
+			if mp.ValueMaxDictionarySize, err = Str2Int64(val); err != nil {
+				return nil, fmt.Errorf("failed to parse valuemaxdictionarysize: %s", err.Error())
+			}
+		case "maxdictionaryentries":
+			if mp.MaxDictionaryEntries, err = Str2Int64(val); err != nil {
+				return nil, fmt.Errorf("failed to parse maxdictionaryentries: %s", err.Error())
+			}
+		case "keymaxdictionaryentries":
+			if mp.KeyMaxDictionaryEntries, err = Str2Int64(val); err != nil {
+				return nil, fmt.Errorf("failed to parse keymaxdictionaryentries: %s", err.Error())
+			}
+		case "valuemaxdictionaryentries":
+			if mp.ValueMaxDictionaryEntries, err = Str2Int64(val); err != nil {
+				return nil, fmt.Errorf("failed to parse valuemaxdictionaryentries: %s", err.Error())
+			}
+		case "fallbackencoding":
+			if mp.FallbackEncoding, err = Str2FallbackEncoding(val); err != nil {
+				return nil, fmt.Errorf("failed to parse fallbackencoding: %s", err.Error())
+			}
+		case "keyfallbackencoding":
+			if mp.KeyFallbackEncoding, err = Str2FallbackEncoding(val); err != nil {
+				return nil, fmt.Errorf("failed to parse keyfallbackencoding: %s", err.Error())
+			}
+		case "valuefallbackencoding":
+			if mp.ValueFallbackEncoding, err = Str2FallbackEncoding(val); err != nil {
+				return nil, fmt.Errorf("failed to parse valuefallbackencoding: %s", err.Error())
+			}
+		case "compression":
+			if mp.Compression, err = Str2Compression(val); err != nil {
+				return nil, fmt.Errorf("failed to parse compression: %s", err.Error())
+			}
+		case "keycompression":
+			if mp.KeyCompression, err = Str2Compression(val); err != nil {
+				return nil, fmt.Errorf("failed to parse keycompression: %s", err.Error())
+			}
+		case "valuecompression":
+			if mp.ValueCompression, err = Str2Compression(val); err != nil {
+				return nil, fmt.Errorf("failed to parse valuecompression: %s", err.Error())
+			}
+		case "compressionlevel":
+			if mp.CompressionLevel, err = strconv.Atoi(val); err != nil {
+				return nil, fmt.Errorf("failed to parse compressionlevel: %s", err.Error())
+			}
+		case "keycompressionlevel":
+			if mp.KeyCompressionLevel, err = strconv.Atoi(val); err != nil {
+				return nil, fmt.Errorf("failed to parse keycompressionlevel: %s", err.Error())
+			}
+		case "valuecompressionlevel":
+			if mp.ValueCompressionLevel, err = strconv.Atoi(val); err != nil {
+				return nil, fmt.Errorf("failed to parse valuecompressionlevel: %s", err.Error())
+			}
 		case "repetitiontype":
 			switch strings.ToLower(val) {
 			case "repeated":
@@ -545,6 +654,13 @@ func GetKeyTagMap(src *Tag) *Tag {
 	res.FieldID = src.KeyFieldID
 	res.Encoding = src.KeyEncoding
 	res.OmitStats = src.KeyOmitStats
+	res.BloomFilter = src.KeyBloomFilter
+	res.BloomFilterFPP = src.KeyBloomFilterFPP
+	res.MaxDictionarySize = src.KeyMaxDictionarySize
+	res.MaxDictionaryEntries = src.KeyMaxDictionaryEntries
+	res.FallbackEncoding = src.KeyFallbackEncoding
+	res.Compression = src.KeyCompression
+	res.CompressionLevel = src.KeyCompressionLevel
 	res.RepetitionType = parquet.FieldRepetitionType_REQUIRED
 	return res
 }
@@ -563,6 +679,13 @@ func GetValueTagMap(src *Tag) *Tag {
 	res.FieldID = src.ValueFieldID
 	res.Encoding = src.ValueEncoding
 	res.OmitStats = src.ValueOmitStats
+	res.BloomFilter = src.ValueBloomFilter
+	res.BloomFilterFPP = src.ValueBloomFilterFPP
+	res.MaxDictionarySize = src.ValueMaxDictionarySize
+	res.MaxDictionaryEntries = src.ValueMaxDictionaryEntries
+	res.FallbackEncoding = src.ValueFallbackEncoding
+	res.Compression = src.ValueCompression
+	res.CompressionLevel = src.ValueCompressionLevel
 	res.RepetitionType = src.ValueRepetitionType
 	return res
 }
@@ -764,6 +887,50 @@ func Str2Bool(val string) (bool, error) {
 	return valBoolean, nil
 }
 
+func Str2Int64(val string) (int64, error) {
+	return strconv.ParseInt(val, 10, 64)
+}
+
+// Parse the encoding used when a dictionary is too large, which can't be a dictionary encoding
+func Str2FallbackEncoding(val string) (parquet.Encoding, error) {
+	switch strings.ToLower(val) {
+	case "plain":
+		return parquet.Encoding_PLAIN, nil
+	case "rle":
+		return parquet.Encoding_RLE, nil
+	case "delta_binary_packed":
+		return parquet.Encoding_DELTA_BINARY_PACKED, nil
+	case "delta_length_byte_array":
+		return parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY, nil
+	case "delta_byte_array":
+		return parquet.Encoding_DELTA_BYTE_ARRAY, nil
+	case "byte_stream_split":
+		return parquet.Encoding_BYTE_STREAM_SPLIT, nil
+	}
+	return 0, fmt.Errorf("unknown fallback encoding type: '%v'", val)
+}
+
+// Parse a compression codec, like zstd or lz4_raw
+func Str2Compression(val string) (*parquet.CompressionCodec, error) {
+	codec, err := parquet.CompressionCodecFromString(strings.ToUpper(val))
+	if err != nil {
+		return nil, fmt.Errorf("unknown compression codec: '%v'", val)
+	}
+	return &codec, nil
+}
+
+// Parse a false positive probability, which must be in (0, 1)
+func Str2FPP(val string) (float64, error) {
+	fpp, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, err
+	}
+	if fpp <= 0 || fpp >= 1 {
+		return 0, fmt.Errorf("%v is not in (0, 1)", fpp)
+	}
+	return fpp, nil
+}
+
 type FuncTable interface {
 	LessThan(a interface{}, b interface{}) bool
 	MinMaxSize(minVal interface{}, maxVal interface{}, val interface{}) (interface{}, interface{}, int32)
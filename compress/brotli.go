@@ -0,0 +1,59 @@
+//go:build !no_brotli
+// +build !no_brotli
+
+package compress
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// Pools of the writers of the compression levels
+var brotliWriterPools [brotli.BestCompression + 1]sync.Pool
+
+func init() {
+	for level := brotli.BestSpeed; level <= brotli.BestCompression; level++ {
+		level := level
+		brotliWriterPools[level].New = func() interface{} {
+			return brotli.NewWriterLevel(nil, level)
+		}
+	}
+
+	RegisterCodec(parquet.CompressionCodec_BROTLI, &Compressor{
+		Compress: func(buf []byte) []byte {
+			return brotliCompress(&brotliWriterPools[brotli.DefaultCompression], nil, buf)
+		},
+		CompressTo: func(dst, src []byte) ([]byte, error) {
+			return brotliCompress(&brotliWriterPools[brotli.DefaultCompression], dst, src), nil
+		},
+		CompressLevel: func(buf []byte, level int) []byte {
+			return brotliCompress(&brotliWriterPools[level], nil, buf)
+		},
+		MinLevel: 1,
+		MaxLevel: brotli.BestCompression,
+		Uncompress: func(buf []byte) ([]byte, error) {
+			return io.ReadAll(brotli.NewReader(bytes.NewReader(buf)))
+		},
+		UncompressTo: func(dst, src []byte) ([]byte, error) {
+			return readAllTo(dst, brotli.NewReader(bytes.NewReader(src)))
+		},
+		UncompressLimit: func(buf []byte, maxSize int64) ([]byte, error) {
+			return readAllLimit(brotli.NewReader(bytes.NewReader(buf)), maxSize)
+		},
+	})
+}
+
+func brotliCompress(pool *sync.Pool, dst, buf []byte) []byte {
+	res := bytes.NewBuffer(dst[:0])
+	brotliWriter := pool.Get().(*brotli.Writer)
+	brotliWriter.Reset(res)
+	brotliWriter.Write(buf)
+	brotliWriter.Close()
+	brotliWriter.Reset(nil)
+	pool.Put(brotliWriter)
+	return res.Bytes()
+}
@@ -0,0 +1,42 @@
+package compress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+func TestBrotliCompression(t *testing.T) {
+	brotliCompressor := compressors[parquet.CompressionCodec_BROTLI]
+	input := []byte("Peter Parker, Peter Parker, Peter Parker")
+
+	// compressed by the reference brotli library with the qualities 1 and 11
+	for _, compressed := range [][]byte{
+		{
+			0x8b, 0x13, 0x00, 0x00, 0x80, 0xaa, 0xaa, 0xaa, 0xea, 0xff, 0x74, 0x65, 0xb8, 0xcb, 0xed, 0xc0,
+			0x87, 0xab, 0x1c, 0xe8, 0x24, 0x37, 0x02, 0x81, 0x03, 0xb8, 0x1f, 0x1f, 0x52, 0x6c, 0xe2, 0x00,
+			0x38, 0x13, 0xa6, 0x3c, 0xa6, 0xeb, 0xe1, 0x64, 0xd0,
+		},
+		{
+			0x1b, 0x27, 0x00, 0xf8, 0x1d, 0x09, 0x36, 0x8e, 0xf4, 0x6d, 0x5c, 0x88, 0x82, 0x4a, 0x39, 0x28,
+			0xba, 0x06, 0x61, 0x29, 0x5b, 0x53, 0x61, 0x65, 0xc1, 0x54, 0x3c, 0x00, 0x7b, 0x88, 0x23,
+		},
+	} {
+		output, err := brotliCompressor.Uncompress(compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(input, output) {
+			t.Fatalf("expected output %s but was %s", string(input), string(output))
+		}
+	}
+
+	output, err := brotliCompressor.Uncompress(brotliCompressor.Compress(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(input, output) {
+		t.Fatalf("expected output %s but was %s", string(input), string(output))
+	}
+}
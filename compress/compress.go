@@ -1,30 +1,188 @@
 package compress
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"sync"
+
 	"github.com/xitongsys/parquet-go/parquet"
 )
 
+// Compressor is the implementation of a codec, see RegisterCodec
 type Compressor struct {
-	Compress   func(buf []byte) []byte
+	//Compress is nil for the codecs which are only uncompressed, it's optional if CompressTo is set
+	Compress func(buf []byte) []byte
+	//Compress src to dst, whose memory is reused if it's large enough.
+	//It's optional, Compress is used if it isn't set.
+	CompressTo func(dst, src []byte) ([]byte, error)
+	//Compress with a compression level between MinLevel and MaxLevel. It's optional,
+	//the codecs without levels only have Compress.
+	CompressLevel      func(buf []byte, level int) []byte
+	MinLevel, MaxLevel int
+	//Uncompress is optional if UncompressTo is set
 	Uncompress func(buf []byte) ([]byte, error)
+	//Uncompress src to dst, whose memory is reused if it's large enough.
+	//It's optional, Uncompress is used if it isn't set.
+	UncompressTo func(dst, src []byte) ([]byte, error)
+	//Uncompress at most maxSize bytes, it fails before allocating more memory if the
+	//data is larger. It's optional, Uncompress is used if it isn't set.
+	UncompressLimit func(buf []byte, maxSize int64) ([]byte, error)
 }
 
-var compressors = map[parquet.CompressionCodec]*Compressor{}
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[parquet.CompressionCodec]*Compressor{}
+)
 
-func Uncompress(buf []byte, compressMethod parquet.CompressionCodec) ([]byte, error) {
+// RegisterCodec registers the compressor of a codec, it replaces the compressor of the
+// package if there is one. It panics if the compressor can't uncompress.
+func RegisterCodec(codec parquet.CompressionCodec, c *Compressor) {
+	if c == nil || (c.Uncompress == nil && c.UncompressTo == nil) {
+		panic(fmt.Sprintf("compress: compressor of %v without Uncompress", codec))
+	}
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[codec] = c
+}
+
+// LookupCodec returns the registered compressor of a codec
+func LookupCodec(compressMethod parquet.CompressionCodec) (*Compressor, error) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
 	c, ok := compressors[compressMethod]
 	if !ok {
-		return nil, fmt.Errorf("unsupported compress method")
+		return nil, fmt.Errorf("unsupported compress method %v", compressMethod)
 	}
+	return c, nil
+}
 
-	return c.Uncompress(buf)
+// Compress and Uncompress are used without dst, they may return src
+func (c *Compressor) compress(dst, src []byte) ([]byte, error) {
+	if c.CompressTo == nil || (dst == nil && c.Compress != nil) {
+		return c.Compress(src), nil
+	}
+	return c.CompressTo(dst, src)
 }
 
-func Compress(buf []byte, compressMethod parquet.CompressionCodec) []byte {
-	c, ok := compressors[compressMethod]
-	if !ok {
+func (c *Compressor) uncompress(dst, src []byte) ([]byte, error) {
+	if c.UncompressTo == nil || (dst == nil && c.Uncompress != nil) {
+		return c.Uncompress(src)
+	}
+	return c.UncompressTo(dst, src)
+}
+
+func (c *Compressor) checkLevel(compressMethod parquet.CompressionCodec, level int) error {
+	if c.Compress == nil && c.CompressTo == nil {
+		return fmt.Errorf("compress method %v is only supported for reading", compressMethod)
+	}
+	if level == 0 {
 		return nil
 	}
-	return c.Compress(buf)
+	if c.CompressLevel == nil {
+		return fmt.Errorf("compress method %v has no compression levels", compressMethod)
+	}
+	if level < c.MinLevel || level > c.MaxLevel {
+		return fmt.Errorf("compression level %v of %v isn't between %v and %v", level, compressMethod, c.MinLevel, c.MaxLevel)
+	}
+	return nil
+}
+
+func Uncompress(buf []byte, compressMethod parquet.CompressionCodec) ([]byte, error) {
+	return UncompressTo(nil, buf, compressMethod)
+}
+
+// Uncompress src to dst, whose memory is reused if it's large enough
+func UncompressTo(dst, src []byte, compressMethod parquet.CompressionCodec) ([]byte, error) {
+	c, err := LookupCodec(compressMethod)
+	if err != nil {
+		return nil, err
+	}
+	return c.uncompress(dst, src)
+}
+
+// Uncompress buf, it returns an error if the uncompressed data is larger than maxSize.
+// It protects the reader from the compressed data of malicious files.
+func UncompressWithLimit(buf []byte, compressMethod parquet.CompressionCodec, maxSize int64) ([]byte, error) {
+	c, err := LookupCodec(compressMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []byte
+	if c.UncompressLimit != nil {
+		res, err = c.UncompressLimit(buf, maxSize)
+	} else {
+		res, err = c.uncompress(nil, buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(res)) > maxSize {
+		return nil, errTooLarge(maxSize)
+	}
+	return res, nil
+}
+
+func errTooLarge(maxSize int64) error {
+	return fmt.Errorf("uncompressed data is larger than %v bytes", maxSize)
+}
+
+// Read r to the end in dst, whose memory is reused if it's large enough
+func readAllTo(dst []byte, r io.Reader) ([]byte, error) {
+	res := bytes.NewBuffer(dst[:0])
+	_, err := res.ReadFrom(r)
+	return res.Bytes(), err
+}
+
+// Read r to the end, at most maxSize bytes are read
+func readAllLimit(r io.Reader, maxSize int64) ([]byte, error) {
+	res, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(res)) > maxSize {
+		return nil, errTooLarge(maxSize)
+	}
+	return res, nil
+}
+
+func Compress(buf []byte, compressMethod parquet.CompressionCodec) ([]byte, error) {
+	return CompressTo(nil, buf, compressMethod)
+}
+
+// Compress src to dst, whose memory is reused if it's large enough
+func CompressTo(dst, src []byte, compressMethod parquet.CompressionCodec) ([]byte, error) {
+	c, err := LookupCodec(compressMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.checkLevel(compressMethod, 0); err != nil {
+		return nil, err
+	}
+	return c.compress(dst, src)
+}
+
+// Compress buf with a compression level of the codec, the level 0 is the default level of the codec
+func CompressWithLevel(buf []byte, compressMethod parquet.CompressionCodec, level int) ([]byte, error) {
+	c, err := LookupCodec(compressMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.checkLevel(compressMethod, level); err != nil {
+		return nil, err
+	}
+	if level == 0 {
+		return c.compress(nil, buf)
+	}
+	return c.CompressLevel(buf, level), nil
+}
+
+// Check that the codec is supported for writing and has the compression level, the level 0 is always valid
+func CheckLevel(compressMethod parquet.CompressionCodec, level int) error {
+	c, err := LookupCodec(compressMethod)
+	if err != nil {
+		return err
+	}
+	return c.checkLevel(compressMethod, level)
 }
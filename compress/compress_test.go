@@ -0,0 +1,136 @@
+package compress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+func TestUncompressWithLimit(t *testing.T) {
+	input := bytes.Repeat([]byte("test data "), 100)
+	for codec, c := range compressors {
+		if c.Compress == nil {
+			continue
+		}
+		compressed := c.Compress(input)
+
+		output, err := UncompressWithLimit(compressed, codec, int64(len(input)))
+		if err != nil {
+			t.Fatalf("%v: %v", codec, err)
+		}
+		if !bytes.Equal(input, output) {
+			t.Fatalf("%v: expected output %s but was %s", codec, string(input), string(output))
+		}
+
+		if _, err = UncompressWithLimit(compressed, codec, int64(len(input))-1); err == nil {
+			t.Fatalf("%v: expected an error for data larger than the limit", codec)
+		}
+	}
+}
+
+func TestCompressWithLevel(t *testing.T) {
+	input := bytes.Repeat([]byte("test data "), 100)
+	for codec, c := range compressors {
+		if c.CompressLevel == nil {
+			if err := CheckLevel(codec, 1); err == nil {
+				t.Fatalf("%v: expected an error for a codec without levels", codec)
+			}
+			continue
+		}
+		for level := c.MinLevel; level <= c.MaxLevel; level++ {
+			if err := CheckLevel(codec, level); err != nil {
+				t.Fatalf("%v: %v", codec, err)
+			}
+			compressed, err := CompressWithLevel(input, codec, level)
+			if err != nil {
+				t.Fatalf("%v level %v: %v", codec, level, err)
+			}
+			output, err := Uncompress(compressed, codec)
+			if err != nil {
+				t.Fatalf("%v level %v: %v", codec, level, err)
+			}
+			if !bytes.Equal(input, output) {
+				t.Fatalf("%v level %v: expected output %s but was %s", codec, level, string(input), string(output))
+			}
+		}
+		if err := CheckLevel(codec, c.MaxLevel+1); err == nil {
+			t.Fatalf("%v: expected an error for level %v", codec, c.MaxLevel+1)
+		}
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	codec := parquet.CompressionCodec(100)
+	if _, err := Compress([]byte("test data"), codec); err == nil {
+		t.Fatal("expected an error for an unknown codec")
+	}
+
+	xor := func(dst, src []byte) ([]byte, error) {
+		dst = dst[:0]
+		for _, b := range src {
+			dst = append(dst, b^0xff)
+		}
+		return dst, nil
+	}
+	RegisterCodec(codec, &Compressor{CompressTo: xor, UncompressTo: xor})
+	defer func() {
+		compressorsMu.Lock()
+		delete(compressors, codec)
+		compressorsMu.Unlock()
+	}()
+
+	input := []byte("test data")
+	compressed, err := Compress(input, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := make([]byte, 0, 64)
+	output, err := UncompressTo(dst, compressed, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(input, output) || &output[0] != &dst[:1][0] {
+		t.Fatalf("expected output %s in dst but was %s", string(input), string(output))
+	}
+	if output, err = UncompressWithLimit(compressed, codec, int64(len(input))); err != nil || !bytes.Equal(input, output) {
+		t.Fatalf("expected output %s but was %s, %v", string(input), string(output), err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected a panic for a compressor without Uncompress")
+			}
+		}()
+		RegisterCodec(codec, &Compressor{CompressTo: xor})
+	}()
+}
+
+func TestCompressTo(t *testing.T) {
+	input := bytes.Repeat([]byte("test data "), 100)
+	for codec, c := range compressors {
+		if c.Compress == nil && c.CompressTo == nil {
+			continue
+		}
+		dst := make([]byte, 0, 4096)
+		compressed, err := CompressTo(dst, input, codec)
+		if err != nil {
+			t.Fatalf("%v: %v", codec, err)
+		}
+		if c.CompressTo != nil && &compressed[0] != &dst[:1][0] {
+			t.Fatalf("%v: expected the compressed data in dst", codec)
+		}
+		uncompressed := make([]byte, 0, 4096)
+		output, err := UncompressTo(uncompressed, compressed, codec)
+		if err != nil {
+			t.Fatalf("%v: %v", codec, err)
+		}
+		if !bytes.Equal(input, output) {
+			t.Fatalf("%v: expected output %s but was %s", codec, string(input), string(output))
+		}
+		if c.UncompressTo != nil && &output[0] != &uncompressed[:1][0] {
+			t.Fatalf("%v: expected the uncompressed data in dst", codec)
+		}
+	}
+}
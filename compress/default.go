@@ -3,12 +3,18 @@ package compress
 import "github.com/xitongsys/parquet-go/parquet"
 
 func init() {
-	compressors[parquet.CompressionCodec_UNCOMPRESSED] = &Compressor{
+	RegisterCodec(parquet.CompressionCodec_UNCOMPRESSED, &Compressor{
 		Compress: func(buf []byte) []byte {
 			return buf
 		},
+		CompressTo: func(dst, src []byte) ([]byte, error) {
+			return append(dst[:0], src...), nil
+		},
 		Uncompress: func(buf []byte) (bytes []byte, err error) {
 			return buf, nil
 		},
-	}
+		UncompressTo: func(dst, src []byte) ([]byte, error) {
+			return append(dst[:0], src...), nil
+		},
+	})
 }
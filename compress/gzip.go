@@ -14,29 +14,65 @@ import (
 
 var gzipWriterPool sync.Pool
 
+// Pools of the writers of the compression levels
+var gzipLevelWriterPools [gzip.BestCompression + 1]sync.Pool
+
 func init() {
 	gzipWriterPool = sync.Pool{
 		New: func() interface{} {
 			return gzip.NewWriter(nil)
 		},
 	}
+	for level := gzip.BestSpeed; level <= gzip.BestCompression; level++ {
+		level := level
+		gzipLevelWriterPools[level].New = func() interface{} {
+			gzipWriter, _ := gzip.NewWriterLevel(nil, level)
+			return gzipWriter
+		}
+	}
 
-	compressors[parquet.CompressionCodec_GZIP] = &Compressor{
+	RegisterCodec(parquet.CompressionCodec_GZIP, &Compressor{
 		Compress: func(buf []byte) []byte {
-			res := new(bytes.Buffer)
-			gzipWriter := gzipWriterPool.Get().(*gzip.Writer)
-			gzipWriter.Reset(res)
-			gzipWriter.Write(buf)
-			gzipWriter.Close()
-			gzipWriter.Reset(nil)
-			gzipWriterPool.Put(gzipWriter)
-			return res.Bytes()
+			return gzipCompress(&gzipWriterPool, nil, buf)
+		},
+		CompressTo: func(dst, src []byte) ([]byte, error) {
+			return gzipCompress(&gzipWriterPool, dst, src), nil
 		},
+		CompressLevel: func(buf []byte, level int) []byte {
+			return gzipCompress(&gzipLevelWriterPools[level], nil, buf)
+		},
+		MinLevel: gzip.BestSpeed,
+		MaxLevel: gzip.BestCompression,
 		Uncompress: func(buf []byte) (i []byte, err error) {
 			rbuf := bytes.NewReader(buf)
 			gzipReader, _ := gzip.NewReader(rbuf)
 			res, err := io.ReadAll(gzipReader)
 			return res, err
 		},
-	}
+		UncompressTo: func(dst, src []byte) ([]byte, error) {
+			gzipReader, err := gzip.NewReader(bytes.NewReader(src))
+			if err != nil {
+				return nil, err
+			}
+			return readAllTo(dst, gzipReader)
+		},
+		UncompressLimit: func(buf []byte, maxSize int64) ([]byte, error) {
+			gzipReader, err := gzip.NewReader(bytes.NewReader(buf))
+			if err != nil {
+				return nil, err
+			}
+			return readAllLimit(gzipReader, maxSize)
+		},
+	})
+}
+
+func gzipCompress(pool *sync.Pool, dst, buf []byte) []byte {
+	res := bytes.NewBuffer(dst[:0])
+	gzipWriter := pool.Get().(*gzip.Writer)
+	gzipWriter.Reset(res)
+	gzipWriter.Write(buf)
+	gzipWriter.Close()
+	gzipWriter.Reset(nil)
+	pool.Put(gzipWriter)
+	return res.Bytes()
 }
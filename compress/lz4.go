@@ -18,22 +18,50 @@ func init() {
 			return lz4.NewWriter(nil)
 		},
 	}
-	compressors[parquet.CompressionCodec_LZ4] = &Compressor{
+	//the compression levels 1 to 9 are lz4.Level1 to lz4.Level9
+	var lz4LevelWriterPools [10]sync.Pool
+	for level := 1; level <= 9; level++ {
+		option := lz4.CompressionLevelOption(lz4.CompressionLevel(1 << (8 + level)))
+		lz4LevelWriterPools[level].New = func() interface{} {
+			lz4Writer := lz4.NewWriter(nil)
+			lz4Writer.Apply(option)
+			return lz4Writer
+		}
+	}
+	RegisterCodec(parquet.CompressionCodec_LZ4, &Compressor{
 		Compress: func(buf []byte) []byte {
-			lz4Writer := lz4WriterPool.Get().(*lz4.Writer)
-			res := new(bytes.Buffer)
-			lz4Writer.Reset(res)
-			lz4Writer.Write(buf)
-			lz4Writer.Close()
-			lz4Writer.Reset(nil)
-			lz4WriterPool.Put(lz4Writer)
-			return res.Bytes()
+			return lz4Compress(&lz4WriterPool, nil, buf)
+		},
+		CompressTo: func(dst, src []byte) ([]byte, error) {
+			return lz4Compress(&lz4WriterPool, dst, src), nil
 		},
+		CompressLevel: func(buf []byte, level int) []byte {
+			return lz4Compress(&lz4LevelWriterPools[level], nil, buf)
+		},
+		MinLevel: 1,
+		MaxLevel: 9,
 		Uncompress: func(buf []byte) (i []byte, err error) {
 			rbuf := bytes.NewReader(buf)
 			lz4Reader := lz4.NewReader(rbuf)
 			res, err := io.ReadAll(lz4Reader)
 			return res, err
 		},
-	}
+		UncompressTo: func(dst, src []byte) ([]byte, error) {
+			return readAllTo(dst, lz4.NewReader(bytes.NewReader(src)))
+		},
+		UncompressLimit: func(buf []byte, maxSize int64) ([]byte, error) {
+			return readAllLimit(lz4.NewReader(bytes.NewReader(buf)), maxSize)
+		},
+	})
+}
+
+func lz4Compress(pool *sync.Pool, dst, buf []byte) []byte {
+	lz4Writer := pool.Get().(*lz4.Writer)
+	res := bytes.NewBuffer(dst[:0])
+	lz4Writer.Reset(res)
+	lz4Writer.Write(buf)
+	lz4Writer.Close()
+	lz4Writer.Reset(nil)
+	pool.Put(lz4Writer)
+	return res.Bytes()
 }
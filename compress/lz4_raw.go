@@ -4,25 +4,67 @@
 package compress
 
 import (
+	"sync"
+
 	"github.com/pierrec/lz4/v4"
 	"github.com/xitongsys/parquet-go/parquet"
 )
 
 func init() {
-	lz4hc := lz4.CompressorHC{
-		Level: lz4.CompressionLevel(9),
+	//the compression levels 1 to 9 are lz4.Level1 to lz4.Level9, a CompressorHC can't be used concurrently,
+	//the pool 0 is of the default level
+	var lz4hcLevelPools [10]sync.Pool
+	lz4hcLevelPools[0].New = func() interface{} {
+		return &lz4.CompressorHC{Level: lz4.CompressionLevel(9)}
+	}
+	for level := 1; level <= 9; level++ {
+		hcLevel := lz4.CompressionLevel(1 << (8 + level))
+		lz4hcLevelPools[level].New = func() interface{} {
+			return &lz4.CompressorHC{Level: hcLevel}
+		}
 	}
-	compressors[parquet.CompressionCodec_LZ4_RAW] = &Compressor{
+	RegisterCodec(parquet.CompressionCodec_LZ4_RAW, &Compressor{
 		Compress: func(buf []byte) []byte {
-			res := make([]byte, lz4.CompressBlockBound(len(buf)))
-			count, _ := lz4hc.CompressBlock(buf, res)
-			return res[:count]
+			res, _ := lz4RawCompress(&lz4hcLevelPools[0], nil, buf)
+			return res
+		},
+		CompressTo: func(dst, src []byte) ([]byte, error) {
+			return lz4RawCompress(&lz4hcLevelPools[0], dst, src)
 		},
+		CompressLevel: func(buf []byte, level int) []byte {
+			res, _ := lz4RawCompress(&lz4hcLevelPools[level], nil, buf)
+			return res
+		},
+		MinLevel: 1,
+		MaxLevel: 9,
 		Uncompress: func(buf []byte) (i []byte, err error) {
 			res := make([]byte, 255*len(buf))
 			count, err := lz4.UncompressBlock(buf, res)
 			res = res[:count]
 			return res[:count], err
 		},
+		UncompressLimit: func(buf []byte, maxSize int64) ([]byte, error) {
+			size := 255 * int64(len(buf))
+			if size > maxSize {
+				size = maxSize
+			}
+			res := make([]byte, size)
+			count, err := lz4.UncompressBlock(buf, res)
+			if err == lz4.ErrInvalidSourceShortBuffer && size == maxSize {
+				return nil, errTooLarge(maxSize)
+			}
+			return res[:count], err
+		},
+	})
+}
+
+// Compress src to dst, which is reallocated if it's too small, with a CompressorHC of the pool
+func lz4RawCompress(pool *sync.Pool, dst, src []byte) ([]byte, error) {
+	if bound := lz4.CompressBlockBound(len(src)); cap(dst) < bound {
+		dst = make([]byte, bound)
 	}
+	compressor := pool.Get().(*lz4.CompressorHC)
+	count, err := compressor.CompressBlock(src, dst[:cap(dst)])
+	pool.Put(compressor)
+	return dst[:count], err
 }
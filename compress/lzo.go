@@ -0,0 +1,227 @@
+//go:build !no_lzo
+// +build !no_lzo
+
+package compress
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// LZO is only decompressed, its pages are written by parquet-mr with the LzoCodec of
+// hadoop-lzo: blocks of LZO1X data in the framing of the Hadoop BlockCompressorStream.
+func init() {
+	RegisterCodec(parquet.CompressionCodec_LZO, &Compressor{
+		Uncompress: func(buf []byte) ([]byte, error) {
+			return lzoUncompress(nil, buf, math.MaxInt32)
+		},
+		UncompressTo: func(dst, src []byte) ([]byte, error) {
+			return lzoUncompress(dst, src, math.MaxInt32)
+		},
+		UncompressLimit: func(buf []byte, maxSize int64) ([]byte, error) {
+			return lzoUncompress(nil, buf, maxSize)
+		},
+	})
+}
+
+var errLzoCorrupt = errors.New("lzo: corrupt input")
+
+// Uncompress the Hadoop framing: each block is its big endian uncompressed size followed by
+// chunks of LZO1X data, each of them prefixed by its big endian compressed size.
+// The data is uncompressed to dst, whose memory is reused if it's large enough.
+func lzoUncompress(dst, buf []byte, maxSize int64) ([]byte, error) {
+	res := dst[:0]
+	for len(buf) > 0 {
+		if len(buf) < 4 {
+			return nil, errLzoCorrupt
+		}
+		blockSize := int64(binary.BigEndian.Uint32(buf))
+		buf = buf[4:]
+		if int64(len(res))+blockSize > maxSize {
+			return nil, errTooLarge(maxSize)
+		}
+		blockEnd := len(res) + int(blockSize)
+		for len(res) < blockEnd {
+			if len(buf) < 4 {
+				return nil, errLzoCorrupt
+			}
+			chunkSize := int64(binary.BigEndian.Uint32(buf))
+			buf = buf[4:]
+			if chunkSize > int64(len(buf)) {
+				return nil, errLzoCorrupt
+			}
+			var err error
+			if res, err = lzo1xDecompress(res, buf[:chunkSize], blockEnd); err != nil {
+				return nil, err
+			}
+			buf = buf[chunkSize:]
+		}
+	}
+	return res, nil
+}
+
+// Decoder of the LZO1X format, as lzo1x_decompress_safe of liblzo
+type lzoDecoder struct {
+	src []byte
+	ip  int
+	dst []byte
+	//Max length of dst
+	maxLen int
+}
+
+// Decompress a LZO1X stream, which is appended to dst. It fails if dst would be longer than maxLen.
+func lzo1xDecompress(dst []byte, src []byte, maxLen int) ([]byte, error) {
+	d := &lzoDecoder{src: src, dst: dst, maxLen: maxLen}
+	if err := d.decode(); err != nil {
+		return nil, err
+	}
+	if d.ip != len(d.src) {
+		return nil, errLzoCorrupt
+	}
+	return d.dst, nil
+}
+
+func (d *lzoDecoder) decode() error {
+	//number of literals copied by the previous instruction, which determines the
+	//meaning of the next instruction if it's lower than 16
+	prevLiterals := 0
+	if len(d.src) > 0 && d.src[0] > 17 {
+		d.ip++
+		prevLiterals = int(d.src[0]) - 17
+		if err := d.literals(prevLiterals); err != nil {
+			return err
+		}
+	}
+
+	for {
+		t, err := d.next()
+		if err != nil {
+			return err
+		}
+
+		var dist, length, state int
+		switch {
+		case t >= 64:
+			b, err := d.next()
+			if err != nil {
+				return err
+			}
+			dist, length, state = 1+(t>>2)&7+b<<3, t>>5+1, t&3
+
+		case t >= 32:
+			if length = t & 31; length == 0 {
+				if length, err = d.length(31); err != nil {
+					return err
+				}
+			}
+			if dist, state, err = d.distance(); err != nil {
+				return err
+			}
+			dist, length = dist+1, length+2
+
+		case t >= 16:
+			if length = t & 7; length == 0 {
+				if length, err = d.length(7); err != nil {
+					return err
+				}
+			}
+			if dist, state, err = d.distance(); err != nil {
+				return err
+			}
+			dist += (t & 8) << 11
+			if dist == 0 {
+				//end of stream
+				return nil
+			}
+			dist, length = dist+0x4000, length+2
+
+		case prevLiterals == 0:
+			//literal run
+			if length = t; length == 0 {
+				if length, err = d.length(15); err != nil {
+					return err
+				}
+			}
+			if err = d.literals(length + 3); err != nil {
+				return err
+			}
+			prevLiterals = length + 3
+			continue
+
+		default:
+			b, err := d.next()
+			if err != nil {
+				return err
+			}
+			if prevLiterals < 4 {
+				dist, length = 1+t>>2+b<<2, 2
+			} else {
+				dist, length = 0x801+t>>2+b<<2, 3
+			}
+			state = t & 3
+		}
+
+		if err = d.match(dist, length); err != nil {
+			return err
+		}
+		if err = d.literals(state); err != nil {
+			return err
+		}
+		prevLiterals = state
+	}
+}
+
+func (d *lzoDecoder) next() (int, error) {
+	if d.ip >= len(d.src) {
+		return 0, errLzoCorrupt
+	}
+	d.ip++
+	return int(d.src[d.ip-1]), nil
+}
+
+// Length of an instruction whose length bits are 0: each zero byte adds 255, the next byte and base are added
+func (d *lzoDecoder) length(base int) (int, error) {
+	length := base
+	for d.ip < len(d.src) && d.src[d.ip] == 0 {
+		d.ip++
+		if length += 255; length > d.maxLen {
+			return 0, errLzoCorrupt
+		}
+	}
+	b, err := d.next()
+	return length + b, err
+}
+
+// Distance of the M3 and M4 matches in two little endian bytes, their 2 lowest bits are the state
+func (d *lzoDecoder) distance() (int, int, error) {
+	if d.ip+2 > len(d.src) {
+		return 0, 0, errLzoCorrupt
+	}
+	b0, b1 := int(d.src[d.ip]), int(d.src[d.ip+1])
+	d.ip += 2
+	return b0>>2 + b1<<6, b0 & 3, nil
+}
+
+func (d *lzoDecoder) literals(n int) error {
+	if n > len(d.src)-d.ip || n > d.maxLen-len(d.dst) {
+		return errLzoCorrupt
+	}
+	d.dst = append(d.dst, d.src[d.ip:d.ip+n]...)
+	d.ip += n
+	return nil
+}
+
+// Copy length bytes from dist bytes back, they may overlap
+func (d *lzoDecoder) match(dist, length int) error {
+	pos := len(d.dst) - dist
+	if pos < 0 || length > d.maxLen-len(d.dst) {
+		return errLzoCorrupt
+	}
+	for i := 0; i < length; i++ {
+		d.dst = append(d.dst, d.dst[pos+i])
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package compress
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// Frame LZO1X chunks in a Hadoop block of size bytes
+func hadoopBlock(size int, chunks ...[]byte) []byte {
+	res := make([]byte, 4)
+	binary.BigEndian.PutUint32(res, uint32(size))
+	for _, chunk := range chunks {
+		res = append(res, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(res[len(res)-4:], uint32(len(chunk)))
+		res = append(res, chunk...)
+	}
+	return res
+}
+
+func TestLzoUncompress(t *testing.T) {
+	lzoCompressor := compressors[parquet.CompressionCodec_LZO]
+
+	// 14 literals, a match of 26 bytes at distance 14 and the end of stream
+	input := []byte("Peter Parker, Peter Parker, Peter Parker")
+	chunk := append(append([]byte{0x1f}, input[:14]...), 0x38, 0x34, 0x00, 0x11, 0x00, 0x00)
+
+	// a stream with all the instructions
+	literals := make([]byte, 16500)
+	for i := range literals {
+		literals[i] = byte(i * 7 % 251)
+	}
+	expected := append([]byte{}, literals...)
+	copyMatch := func(dist, length int) {
+		for i := 0; i < length; i++ {
+			expected = append(expected, expected[len(expected)-dist])
+		}
+	}
+	stream := []byte{0x00}
+	stream = append(stream, make([]byte, 64)...)
+	stream = append(stream, byte(len(literals)-3-15-64*255))
+	stream = append(stream, literals...)
+	// a match of 3 bytes at distance 2100 after a literal run, then 1 literal
+	stream = append(stream, 0x0d, 0x0c, 'X')
+	copyMatch(2100, 3)
+	expected = append(expected, 'X')
+	// a match of 2 bytes at distance 5 after a literal, then 2 literals
+	stream = append(stream, 0x02, 0x01, 'Y', 'Z')
+	copyMatch(5, 2)
+	expected = append(expected, 'Y', 'Z')
+	// a match of 5 bytes at distance 10
+	stream = append(stream, 0x84, 0x01)
+	copyMatch(10, 5)
+	// a literal run of 8 bytes
+	stream = append(stream, 0x05)
+	stream = append(stream, "literals"...)
+	expected = append(expected, "literals"...)
+	// a match of 9 bytes at distance 16400
+	stream = append(stream, 0x17, 0x40, 0x00)
+	copyMatch(16400, 9)
+	// a match of 40 bytes at distance 100, then 3 literals
+	stream = append(stream, 0x20, 0x07, 0x8f, 0x01, 'e', 'n', 'd')
+	copyMatch(100, 40)
+	expected = append(expected, "end"...)
+	stream = append(stream, 0x11, 0x00, 0x00)
+
+	for _, c := range []struct {
+		compressed []byte
+		expected   []byte
+	}{
+		{hadoopBlock(len(input), chunk), input},
+		{append(hadoopBlock(len(input), chunk), hadoopBlock(len(expected), stream)...), append(append([]byte{}, input...), expected...)},
+		{hadoopBlock(2*len(input), chunk, chunk), append(append([]byte{}, input...), input...)},
+		{hadoopBlock(0), []byte{}},
+	} {
+		output, err := lzoCompressor.Uncompress(c.compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(c.expected, output) {
+			t.Fatalf("expected output %v but was %v", c.expected, output)
+		}
+	}
+
+	for _, compressed := range [][]byte{
+		hadoopBlock(len(input)-1, chunk),
+		hadoopBlock(len(input)+1, chunk),
+		hadoopBlock(len(input), chunk[:len(chunk)-1]),
+		hadoopBlock(len(input), append(chunk, 0x00)),
+		hadoopBlock(len(input))[:3],
+		hadoopBlock(len(input), []byte{0x1f, 'a', 0x11, 0x00, 0x00}),
+		hadoopBlock(len(input), []byte{0x11, 0x01, 0x00}),
+	} {
+		if _, err := lzoCompressor.Uncompress(compressed); err == nil {
+			t.Fatalf("expected an error for %v", compressed)
+		}
+	}
+
+	if _, err := UncompressWithLimit(hadoopBlock(len(input), chunk), parquet.CompressionCodec_LZO, int64(len(input))-1); err == nil {
+		t.Fatal("expected an error for data larger than the limit")
+	}
+	if err := CheckLevel(parquet.CompressionCodec_LZO, 0); err == nil {
+		t.Fatal("expected an error for writing LZO")
+	}
+}
+
+// The streams of testdata/lzo are compressed by liblzo2, see testdata/README.md. They aren't in the
+// repository, the test is skipped until they are generated: name.lzo1x is the raw LZO1X stream of
+// the file name, name.hadoop is its Hadoop framing by the LzoCodec of hadoop-lzo.
+func TestLzoFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "lzo", "*.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compressed []string
+	for _, path := range paths {
+		if ext := filepath.Ext(path); ext == ".lzo1x" || ext == ".hadoop" {
+			compressed = append(compressed, path)
+		}
+	}
+	if len(compressed) == 0 {
+		t.Skip("no LZO streams in testdata/lzo")
+	}
+	for _, path := range compressed {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := os.ReadFile(strings.TrimSuffix(path, filepath.Ext(path)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var output []byte
+			if filepath.Ext(path) == ".lzo1x" {
+				output, err = lzo1xDecompress(nil, src, len(expected))
+			} else {
+				output, err = lzoUncompress(nil, src, int64(len(expected)))
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(expected, output) {
+				t.Fatalf("the output of %v differs from the original of %v bytes", path, len(expected))
+			}
+		})
+	}
+}
@@ -9,12 +9,28 @@ import (
 )
 
 func init() {
-	compressors[parquet.CompressionCodec_SNAPPY] = &Compressor{
+	RegisterCodec(parquet.CompressionCodec_SNAPPY, &Compressor{
 		Compress: func(buf []byte) []byte {
 			return snappy.Encode(nil, buf)
 		},
+		CompressTo: func(dst, src []byte) ([]byte, error) {
+			return snappy.Encode(dst[:cap(dst)], src), nil
+		},
 		Uncompress: func(buf []byte) (bytes []byte, err error) {
 			return snappy.Decode(nil, buf)
 		},
-	}
+		UncompressTo: func(dst, src []byte) ([]byte, error) {
+			return snappy.Decode(dst[:cap(dst)], src)
+		},
+		UncompressLimit: func(buf []byte, maxSize int64) ([]byte, error) {
+			size, err := snappy.DecodedLen(buf)
+			if err != nil {
+				return nil, err
+			}
+			if int64(size) > maxSize {
+				return nil, errTooLarge(maxSize)
+			}
+			return snappy.Decode(nil, buf)
+		},
+	})
 }
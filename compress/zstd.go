@@ -4,20 +4,60 @@
 package compress
 
 import (
+	"sync"
+
 	"github.com/klauspost/compress/zstd"
 	"github.com/xitongsys/parquet-go/parquet"
 )
 
+// Encoders of the speeds of the compression levels, they are created when they are first used
+var (
+	zstdEncodersMu sync.Mutex
+	zstdEncoders   [zstd.SpeedBestCompression + 1]*zstd.Encoder
+)
+
 func init() {
 	// Create encoder/decoder with default parameters.
 	enc, _ := zstd.NewWriter(nil, zstd.WithZeroFrames(true))
 	dec, _ := zstd.NewReader(nil)
-	compressors[parquet.CompressionCodec_ZSTD] = &Compressor{
+	zstdEncoders[zstd.SpeedDefault] = enc
+	RegisterCodec(parquet.CompressionCodec_ZSTD, &Compressor{
 		Compress: func(buf []byte) []byte {
 			return enc.EncodeAll(buf, nil)
 		},
+		CompressTo: func(dst, src []byte) ([]byte, error) {
+			return enc.EncodeAll(src, dst[:0]), nil
+		},
+		//the levels of zstd are mapped to the 4 speeds of the encoder: 1 and 2 are SpeedFastest,
+		//3 to 5 SpeedDefault, 6 to 9 SpeedBetterCompression and 10 to 22 SpeedBestCompression,
+		//so the levels of the same speed compress to the same output
+		CompressLevel: func(buf []byte, level int) []byte {
+			return zstdEncoder(zstd.EncoderLevelFromZstd(level)).EncodeAll(buf, nil)
+		},
+		MinLevel: 1,
+		MaxLevel: 22,
 		Uncompress: func(buf []byte) (bytes []byte, err error) {
 			return dec.DecodeAll(buf, nil)
 		},
+		UncompressTo: func(dst, src []byte) ([]byte, error) {
+			return dec.DecodeAll(src, dst[:0])
+		},
+		UncompressLimit: func(buf []byte, maxSize int64) ([]byte, error) {
+			//the size is checked before decoding only if it's in the frame header
+			var header zstd.Header
+			if err := header.Decode(buf); err == nil && header.HasFCS && header.FrameContentSize > uint64(maxSize) {
+				return nil, errTooLarge(maxSize)
+			}
+			return dec.DecodeAll(buf, nil)
+		},
+	})
+}
+
+func zstdEncoder(speed zstd.EncoderLevel) *zstd.Encoder {
+	zstdEncodersMu.Lock()
+	defer zstdEncodersMu.Unlock()
+	if zstdEncoders[speed] == nil {
+		zstdEncoders[speed], _ = zstd.NewWriter(nil, zstd.WithZeroFrames(true), zstd.WithEncoderLevel(speed))
 	}
+	return zstdEncoders[speed]
 }
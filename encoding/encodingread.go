@@ -6,10 +6,24 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"unsafe"
 
 	"github.com/xitongsys/parquet-go/parquet"
 )
 
+//Max number of values read by the functions whose count of values is in the data,
+//as the number of values of a page is an int32
+const maxValues = math.MaxInt32
+
+//Check that bytesReader has the data of cnt values of size bytes, so that the
+//values of malformed data aren't allocated before reading it
+func checkSize(bytesReader *bytes.Reader, cnt uint64, size uint64) error {
+	if size > 0 && cnt > uint64(bytesReader.Len())/size {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
 func ReadPlain(bytesReader *bytes.Reader, dataType parquet.Type, cnt uint64, bitWidth uint64) ([]interface{}, error) {
 	if dataType == parquet.Type_BOOLEAN {
 		return ReadPlainBOOLEAN(bytesReader, cnt)
@@ -38,8 +52,12 @@ func ReadPlainBOOLEAN(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, err
 		err error
 	)
 
+	numBytes := (cnt + 7) / 8
+	if err = checkSize(bytesReader, numBytes, 1); err != nil {
+		return res, err
+	}
 	res = make([]interface{}, cnt)
-	resInt, err := ReadBitPacked(bytesReader, uint64(cnt<<1), 1)
+	resInt, err := readBitPacked(bytesReader, numBytes<<1, 1, cnt)
 	if err != nil {
 		return res, err
 	}
@@ -56,6 +74,9 @@ func ReadPlainBOOLEAN(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, err
 
 func ReadPlainINT32(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
 	var err error
+	if err = checkSize(bytesReader, cnt, 4); err != nil {
+		return nil, err
+	}
 	res := make([]interface{}, cnt)
 	err = BinaryReadINT32(bytesReader, res)
 	return res, err
@@ -63,6 +84,9 @@ func ReadPlainINT32(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error
 
 func ReadPlainINT64(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
 	var err error
+	if err = checkSize(bytesReader, cnt, 8); err != nil {
+		return nil, err
+	}
 	res := make([]interface{}, cnt)
 	err = BinaryReadINT64(bytesReader, res)
 	return res, err
@@ -70,6 +94,9 @@ func ReadPlainINT64(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error
 
 func ReadPlainINT96(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
 	var err error
+	if err = checkSize(bytesReader, cnt, 12); err != nil {
+		return nil, err
+	}
 	res := make([]interface{}, cnt)
 	cur := make([]byte, 12)
 	for i := 0; i < int(cnt); i++ {
@@ -83,6 +110,9 @@ func ReadPlainINT96(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error
 
 func ReadPlainFLOAT(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
 	var err error
+	if err = checkSize(bytesReader, cnt, 4); err != nil {
+		return nil, err
+	}
 	res := make([]interface{}, cnt)
 	err = BinaryReadFLOAT32(bytesReader, res)
 	return res, err
@@ -90,6 +120,9 @@ func ReadPlainFLOAT(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error
 
 func ReadPlainDOUBLE(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
 	var err error
+	if err = checkSize(bytesReader, cnt, 8); err != nil {
+		return nil, err
+	}
 	res := make([]interface{}, cnt)
 	err = BinaryReadFLOAT64(bytesReader, res)
 	return res, err
@@ -97,6 +130,10 @@ func ReadPlainDOUBLE(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, erro
 
 func ReadPlainBYTE_ARRAY(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
 	var err error
+	//each value has at least its length
+	if err = checkSize(bytesReader, cnt, 4); err != nil {
+		return nil, err
+	}
 	res := make([]interface{}, cnt)
 	for i := 0; i < int(cnt); i++ {
 		buf := make([]byte, 4)
@@ -104,6 +141,9 @@ func ReadPlainBYTE_ARRAY(bytesReader *bytes.Reader, cnt uint64) ([]interface{},
 			break
 		}
 		ln := binary.LittleEndian.Uint32(buf)
+		if err = checkSize(bytesReader, uint64(ln), 1); err != nil {
+			break
+		}
 		cur := make([]byte, ln)
 		bytesReader.Read(cur)
 		res[i] = string(cur)
@@ -113,6 +153,9 @@ func ReadPlainBYTE_ARRAY(bytesReader *bytes.Reader, cnt uint64) ([]interface{},
 
 func ReadPlainFIXED_LEN_BYTE_ARRAY(bytesReader *bytes.Reader, cnt uint64, fixedLength uint64) ([]interface{}, error) {
 	var err error
+	if err = checkSize(bytesReader, cnt, fixedLength); err != nil {
+		return nil, err
+	}
 	res := make([]interface{}, cnt)
 	for i := 0; i < int(cnt); i++ {
 		cur := make([]byte, fixedLength)
@@ -124,14 +167,165 @@ func ReadPlainFIXED_LEN_BYTE_ARRAY(bytesReader *bytes.Reader, cnt uint64, fixedL
 	return res, err
 }
 
-func ReadUnsignedVarInt(bytesReader *bytes.Reader) (uint64, error) {
+func ReadPlainBools(bytesReader *bytes.Reader, cnt uint64) ([]bool, error) {
+	numBytes := (cnt + 7) / 8
+	if err := checkSize(bytesReader, numBytes, 1); err != nil {
+		return nil, err
+	}
+	res := make([]bool, cnt)
+	resInt, err := readBitPacked(bytesReader, numBytes<<1, 1, cnt)
+	if err != nil {
+		return res, err
+	}
+
+	for i := 0; i < int(cnt); i++ {
+		res[i] = resInt[i].(int64) > 0
+	}
+	return res, err
+}
+
+func ReadPlainInt32s(bytesReader *bytes.Reader, cnt uint64) ([]int32, error) {
+	if err := checkSize(bytesReader, cnt, 4); err != nil {
+		return nil, err
+	}
+	res := make([]int32, cnt)
+	buf := make([]byte, cnt*4)
+	if _, err := io.ReadFull(bytesReader, buf); err != nil {
+		return res, err
+	}
+	for i := range res {
+		res[i] = int32(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return res, nil
+}
+
+func ReadPlainInt64s(bytesReader *bytes.Reader, cnt uint64) ([]int64, error) {
+	if err := checkSize(bytesReader, cnt, 8); err != nil {
+		return nil, err
+	}
+	res := make([]int64, cnt)
+	buf := make([]byte, cnt*8)
+	if _, err := io.ReadFull(bytesReader, buf); err != nil {
+		return res, err
+	}
+	for i := range res {
+		res[i] = int64(binary.LittleEndian.Uint64(buf[i*8:]))
+	}
+	return res, nil
+}
+
+func ReadPlainFloat32s(bytesReader *bytes.Reader, cnt uint64) ([]float32, error) {
+	if err := checkSize(bytesReader, cnt, 4); err != nil {
+		return nil, err
+	}
+	res := make([]float32, cnt)
+	buf := make([]byte, cnt*4)
+	if _, err := io.ReadFull(bytesReader, buf); err != nil {
+		return res, err
+	}
+	for i := range res {
+		res[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return res, nil
+}
+
+func ReadPlainFloat64s(bytesReader *bytes.Reader, cnt uint64) ([]float64, error) {
+	if err := checkSize(bytesReader, cnt, 8); err != nil {
+		return nil, err
+	}
+	res := make([]float64, cnt)
+	buf := make([]byte, cnt*8)
+	if _, err := io.ReadFull(bytesReader, buf); err != nil {
+		return res, err
+	}
+	for i := range res {
+		res[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[i*8:]))
+	}
+	return res, nil
+}
+
+func ReadPlainByteArrays(bytesReader *bytes.Reader, cnt uint64) ([]string, error) {
+	var err error
+	//each value has at least its length
+	if err = checkSize(bytesReader, cnt, 4); err != nil {
+		return nil, err
+	}
+	res := make([]string, cnt)
+	buf := make([]byte, 4)
+	for i := 0; i < int(cnt); i++ {
+		if _, err = bytesReader.Read(buf); err != nil {
+			break
+		}
+		ln := binary.LittleEndian.Uint32(buf)
+		if err = checkSize(bytesReader, uint64(ln), 1); err != nil {
+			break
+		}
+		cur := make([]byte, ln)
+		bytesReader.Read(cur)
+		res[i] = string(cur)
+	}
+	return res, err
+}
+
+//Read BYTE_ARRAY values as ReadPlainByteArrays, but the values are views of buf instead
+//of copies, which are only valid as long as buf isn't modified. buf is the data read by
+//bytesReader, which is moved after the values.
+func ReadPlainByteArrayViews(bytesReader *bytes.Reader, buf []byte, cnt uint64) ([]string, error) {
+	if err := checkSize(bytesReader, cnt, 4); err != nil {
+		return nil, err
+	}
+	pos := len(buf) - bytesReader.Len()
+	if pos < 0 {
+		return nil, fmt.Errorf("the reader isn't a reader of the buffer")
+	}
+	res := make([]string, cnt)
 	var err error
+	for i := range res {
+		if len(buf)-pos < 4 {
+			err = io.EOF
+			break
+		}
+		ln := int(binary.LittleEndian.Uint32(buf[pos:]))
+		pos += 4
+		if ln < 0 || ln > len(buf)-pos {
+			err = io.ErrUnexpectedEOF
+			break
+		}
+		value := buf[pos : pos+ln]
+		res[i] = *(*string)(unsafe.Pointer(&value))
+		pos += ln
+	}
+	bytesReader.Seek(int64(pos), io.SeekStart)
+	return res, err
+}
+
+//Read FIXED_LEN_BYTE_ARRAY and INT96 values
+func ReadPlainFixedLenByteArrays(bytesReader *bytes.Reader, cnt uint64, fixedLength uint64) ([]string, error) {
+	var err error
+	if err = checkSize(bytesReader, cnt, fixedLength); err != nil {
+		return nil, err
+	}
+	res := make([]string, cnt)
+	cur := make([]byte, fixedLength)
+	for i := 0; i < int(cnt); i++ {
+		if _, err = bytesReader.Read(cur); err != nil {
+			break
+		}
+		res[i] = string(cur)
+	}
+	return res, err
+}
+
+func ReadUnsignedVarInt(bytesReader *bytes.Reader) (uint64, error) {
 	var res uint64 = 0
 	var shift uint64 = 0
 	for {
 		b, err := bytesReader.ReadByte()
 		if err != nil {
-			break
+			return res, err
+		}
+		if shift >= 64 {
+			return res, fmt.Errorf("varint overflows 64 bits")
 		}
 		res |= ((uint64(b) & uint64(0x7F)) << uint64(shift))
 		if (b & 0x80) == 0 {
@@ -139,14 +333,25 @@ func ReadUnsignedVarInt(bytesReader *bytes.Reader) (uint64, error) {
 		}
 		shift += 7
 	}
-	return res, err
+	return res, nil
 }
 
 //RLE return res is []INT64
 func ReadRLE(bytesReader *bytes.Reader, header uint64, bitWidth uint64) ([]interface{}, error) {
+	return readRLE(bytesReader, header, bitWidth, maxValues)
+}
+
+//Read a RLE run, only its first maxCnt values are returned
+func readRLE(bytesReader *bytes.Reader, header uint64, bitWidth uint64, maxCnt uint64) ([]interface{}, error) {
 	var err error
 	var res []interface{}
+	if bitWidth > 64 {
+		return res, fmt.Errorf("invalid bit width %v", bitWidth)
+	}
 	cnt := header >> 1
+	if cnt > maxCnt {
+		cnt = maxCnt
+	}
 	width := (bitWidth + 7) / 8
 	data := make([]byte, width)
 	if width > 0 {
@@ -168,28 +373,65 @@ func ReadRLE(bytesReader *bytes.Reader, header uint64, bitWidth uint64) ([]inter
 
 //return res is []INT64
 func ReadBitPacked(bytesReader *bytes.Reader, header uint64, bitWidth uint64) ([]interface{}, error) {
+	return readBitPacked(bytesReader, header, bitWidth, maxValues)
+}
+
+//Read a bit-packed run, only its first maxCnt values are returned. The values
+//of the missing data at the end of a truncated run are zeros.
+func readBitPacked(bytesReader *bytes.Reader, header uint64, bitWidth uint64, maxCnt uint64) ([]interface{}, error) {
 	var err error
+	if bitWidth > 64 {
+		return nil, fmt.Errorf("invalid bit width %v", bitWidth)
+	}
 	numGroup := (header >> 1)
-	cnt := numGroup * 8
-	byteCnt := cnt * bitWidth / 8
-
-	res := make([]interface{}, 0, cnt)
+	cnt := maxCnt
+	if numGroup <= maxCnt/8 {
+		cnt = numGroup * 8
+	}
 
 	if cnt == 0 {
-		return res, nil
+		return make([]interface{}, 0), nil
 	}
 
 	if bitWidth == 0 {
-		for i := 0; i < int(cnt); i++ {
-			res = append(res, int64(0))
+		res := make([]interface{}, cnt)
+		for i := range res {
+			res[i] = int64(0)
 		}
 		return res, err
 	}
+
+	//the values are padded to a group with zeros if the data is truncated
+	available := uint64(bytesReader.Len())
+	if maxAvailable := (available*8/bitWidth + 7) / 8 * 8; cnt > maxAvailable {
+		cnt = maxAvailable
+	}
+	if cnt == 0 {
+		return make([]interface{}, 0), nil
+	}
+	byteCnt := (cnt*bitWidth + 7) / 8
+	//the values after maxCnt are skipped
+	skipCnt := available
+	if numGroup <= available/bitWidth {
+		skipCnt = numGroup * bitWidth
+	}
+	if skipCnt > byteCnt {
+		skipCnt -= byteCnt
+	} else {
+		skipCnt = 0
+	}
+
 	bytesBuf := make([]byte, byteCnt)
 	if _, err = bytesReader.Read(bytesBuf); err != nil {
-		return res, err
+		return make([]interface{}, 0), err
+	}
+	if skipCnt > uint64(bytesReader.Len()) {
+		skipCnt = uint64(bytesReader.Len())
 	}
+	bytesReader.Seek(int64(skipCnt), io.SeekCurrent)
 
+	//the last byte may have more values than cnt
+	res := make([]interface{}, 0, cnt+8)
 	i := 0
 	var resCur uint64 = 0
 	var resCurNeedBits uint64 = bitWidth
@@ -224,18 +466,33 @@ func ReadBitPacked(bytesReader *bytes.Reader, header uint64, bitWidth uint64) ([
 			used = 0
 		}
 	}
+	if uint64(len(res)) > cnt {
+		res = res[:cnt]
+	}
 	return res, err
 }
 
 //res is INT64
 func ReadRLEBitPackedHybrid(bytesReader *bytes.Reader, bitWidth uint64, length uint64) ([]interface{}, error) {
+	return ReadRLEBitPackedHybridN(bytesReader, bitWidth, length, maxValues)
+}
+
+//Read at most cnt values encoded with the RLE/bit-packing hybrid encoding,
+//the values after the first cnt are dropped. res is INT64
+func ReadRLEBitPackedHybridN(bytesReader *bytes.Reader, bitWidth uint64, length uint64, cnt uint64) ([]interface{}, error) {
 	res := make([]interface{}, 0)
+	if bitWidth > 64 {
+		return res, fmt.Errorf("invalid bit width %v", bitWidth)
+	}
 	if length <= 0 {
 		lb, err := ReadPlainINT32(bytesReader, 1)
 		if err != nil {
 			return res, err
 		}
-		length = uint64(lb[0].(int32))
+		length = uint64(uint32(lb[0].(int32)))
+	}
+	if length > uint64(bytesReader.Len()) {
+		length = uint64(bytesReader.Len())
 	}
 
 	buf := make([]byte, length)
@@ -244,20 +501,20 @@ func ReadRLEBitPackedHybrid(bytesReader *bytes.Reader, bitWidth uint64, length u
 	}
 
 	newReader := bytes.NewReader(buf)
-	for newReader.Len() > 0 {
+	for newReader.Len() > 0 && uint64(len(res)) < cnt {
 		header, err := ReadUnsignedVarInt(newReader)
 		if err != nil {
 			return res, err
 		}
 		if header&1 == 0 {
-			buf, err := ReadRLE(newReader, header, bitWidth)
+			buf, err := readRLE(newReader, header, bitWidth, cnt-uint64(len(res)))
 			if err != nil {
 				return res, err
 			}
 			res = append(res, buf...)
 
 		} else {
-			buf, err := ReadBitPacked(newReader, header, bitWidth)
+			buf, err := readBitPacked(newReader, header, bitWidth, cnt-uint64(len(res)))
 			if err != nil {
 				return res, err
 			}
@@ -268,31 +525,24 @@ func ReadRLEBitPackedHybrid(bytesReader *bytes.Reader, bitWidth uint64, length u
 }
 
 func ReadDeltaBinaryPackedINT32(bytesReader *bytes.Reader) ([]interface{}, error) {
+	return ReadDeltaBinaryPackedINT32N(bytesReader, maxValues)
+}
+
+//Read INT32 values encoded with DELTA_BINARY_PACKED, it fails if there are more than cnt values
+func ReadDeltaBinaryPackedINT32N(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
 	var (
 		err error
 		res []interface{}
 	)
 
-	blockSize, err := ReadUnsignedVarInt(bytesReader)
-	if err != nil {
-		return res, err
-	}
-	numMiniblocksInBlock, err := ReadUnsignedVarInt(bytesReader)
-	if err != nil {
-		return res, err
-	}
-	numValues, err := ReadUnsignedVarInt(bytesReader)
-	if err != nil {
-		return res, err
-	}
-	firstValueZigZag, err := ReadUnsignedVarInt(bytesReader)
+	header, err := readDeltaBinaryPackedHeader(bytesReader, cnt)
 	if err != nil {
 		return res, err
 	}
+	numValues := header.numValues
 
-	fv32 := int32(firstValueZigZag)
+	fv32 := int32(header.firstValue)
 	var firstValue int32 = int32(uint32(fv32)>>1) ^ -(fv32 & 1)
-	numValuesInMiniBlock := blockSize / numMiniblocksInBlock
 
 	res = make([]interface{}, 0)
 	res = append(res, firstValue)
@@ -304,16 +554,16 @@ func ReadDeltaBinaryPackedINT32(bytesReader *bytes.Reader) ([]interface{}, error
 
 		md32 := int32(minDeltaZigZag)
 		var minDelta int32 = int32(uint32(md32)>>1) ^ -(md32 & 1)
-		var bitWidths = make([]uint64, numMiniblocksInBlock)
-		for i := 0; uint64(i) < numMiniblocksInBlock; i++ {
+		var bitWidths = make([]uint64, header.numMiniblocksInBlock)
+		for i := 0; uint64(i) < header.numMiniblocksInBlock; i++ {
 			b, err := bytesReader.ReadByte()
 			if err != nil {
 				return res, err
 			}
 			bitWidths[i] = uint64(b)
 		}
-		for i := 0; uint64(i) < numMiniblocksInBlock && uint64(len(res)) < numValues; i++ {
-			cur, err := ReadBitPacked(bytesReader, (numValuesInMiniBlock/8)<<1, bitWidths[i])
+		for i := 0; uint64(i) < header.numMiniblocksInBlock && uint64(len(res)) < numValues; i++ {
+			cur, err := readBitPacked(bytesReader, (header.numValuesInMiniBlock/8)<<1, bitWidths[i], numValues-uint64(len(res)))
 			if err != nil {
 				return res, err
 			}
@@ -327,30 +577,24 @@ func ReadDeltaBinaryPackedINT32(bytesReader *bytes.Reader) ([]interface{}, error
 
 //res is INT64
 func ReadDeltaBinaryPackedINT64(bytesReader *bytes.Reader) ([]interface{}, error) {
+	return ReadDeltaBinaryPackedINT64N(bytesReader, maxValues)
+}
+
+//Read INT64 values encoded with DELTA_BINARY_PACKED, it fails if there are more than cnt values.
+//res is INT64
+func ReadDeltaBinaryPackedINT64N(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
 	var (
 		err error
 		res []interface{}
 	)
 
-	blockSize, err := ReadUnsignedVarInt(bytesReader)
+	header, err := readDeltaBinaryPackedHeader(bytesReader, cnt)
 	if err != nil {
 		return res, err
 	}
-	numMiniblocksInBlock, err := ReadUnsignedVarInt(bytesReader)
-	if err != nil {
-		return res, err
-	}
-	numValues, err := ReadUnsignedVarInt(bytesReader)
-	if err != nil {
-		return res, err
-	}
-	firstValueZigZag, err := ReadUnsignedVarInt(bytesReader)
-	if err != nil {
-		return res, err
-	}
-	var firstValue int64 = int64(firstValueZigZag>>1) ^ -(int64(firstValueZigZag) & 1)
+	numValues := header.numValues
 
-	numValuesInMiniBlock := blockSize / numMiniblocksInBlock
+	var firstValue int64 = int64(header.firstValue>>1) ^ -(int64(header.firstValue) & 1)
 
 	res = make([]interface{}, 0)
 	res = append(res, int64(firstValue))
@@ -360,8 +604,8 @@ func ReadDeltaBinaryPackedINT64(bytesReader *bytes.Reader) ([]interface{}, error
 			return res, err
 		}
 		var minDelta int64 = int64(minDeltaZigZag>>1) ^ -(int64(minDeltaZigZag) & 1)
-		var bitWidths = make([]uint64, numMiniblocksInBlock)
-		for i := 0; uint64(i) < numMiniblocksInBlock; i++ {
+		var bitWidths = make([]uint64, header.numMiniblocksInBlock)
+		for i := 0; uint64(i) < header.numMiniblocksInBlock; i++ {
 			b, err := bytesReader.ReadByte()
 			if err != nil {
 				return res, err
@@ -369,8 +613,8 @@ func ReadDeltaBinaryPackedINT64(bytesReader *bytes.Reader) ([]interface{}, error
 			bitWidths[i] = uint64(b)
 		}
 
-		for i := 0; uint64(i) < numMiniblocksInBlock && uint64(len(res)) < numValues; i++ {
-			cur, err := ReadBitPacked(bytesReader, (numValuesInMiniBlock/8)<<1, bitWidths[i])
+		for i := 0; uint64(i) < header.numMiniblocksInBlock && uint64(len(res)) < numValues; i++ {
+			cur, err := readBitPacked(bytesReader, (header.numValuesInMiniBlock/8)<<1, bitWidths[i], numValues-uint64(len(res)))
 			if err != nil {
 				return res, err
 			}
@@ -382,13 +626,60 @@ func ReadDeltaBinaryPackedINT64(bytesReader *bytes.Reader) ([]interface{}, error
 	return res[:numValues], err
 }
 
+type deltaBinaryPackedHeader struct {
+	numMiniblocksInBlock uint64
+	numValuesInMiniBlock uint64
+	numValues            uint64
+	firstValue           uint64
+}
+
+//Read and check the header of DELTA_BINARY_PACKED values
+func readDeltaBinaryPackedHeader(bytesReader *bytes.Reader, cnt uint64) (*deltaBinaryPackedHeader, error) {
+	blockSize, err := ReadUnsignedVarInt(bytesReader)
+	if err != nil {
+		return nil, err
+	}
+	numMiniblocksInBlock, err := ReadUnsignedVarInt(bytesReader)
+	if err != nil {
+		return nil, err
+	}
+	numValues, err := ReadUnsignedVarInt(bytesReader)
+	if err != nil {
+		return nil, err
+	}
+	firstValue, err := ReadUnsignedVarInt(bytesReader)
+	if err != nil {
+		return nil, err
+	}
+
+	//the bit widths of the miniblocks are bytes of the data
+	if blockSize == 0 || numMiniblocksInBlock == 0 || numMiniblocksInBlock > uint64(bytesReader.Len()) ||
+		blockSize%numMiniblocksInBlock != 0 || (blockSize/numMiniblocksInBlock)%8 != 0 {
+		return nil, fmt.Errorf("invalid DELTA_BINARY_PACKED block size %v with %v miniblocks", blockSize, numMiniblocksInBlock)
+	}
+	if numValues > cnt {
+		return nil, fmt.Errorf("DELTA_BINARY_PACKED data has %v values, more than %v", numValues, cnt)
+	}
+	return &deltaBinaryPackedHeader{
+		numMiniblocksInBlock: numMiniblocksInBlock,
+		numValuesInMiniBlock: blockSize / numMiniblocksInBlock,
+		numValues:            numValues,
+		firstValue:           firstValue,
+	}, nil
+}
+
 func ReadDeltaLengthByteArray(bytesReader *bytes.Reader) ([]interface{}, error) {
+	return ReadDeltaLengthByteArrayN(bytesReader, maxValues)
+}
+
+//Read values encoded with DELTA_LENGTH_BYTE_ARRAY, it fails if there are more than cnt values
+func ReadDeltaLengthByteArrayN(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
 	var (
 		res []interface{}
 		err error
 	)
 
-	lengths, err := ReadDeltaBinaryPackedINT64(bytesReader)
+	lengths, err := ReadDeltaBinaryPackedINT64N(bytesReader, cnt)
 	if err != nil {
 		return res, err
 	}
@@ -409,24 +700,38 @@ func ReadDeltaLengthByteArray(bytesReader *bytes.Reader) ([]interface{}, error)
 }
 
 func ReadDeltaByteArray(bytesReader *bytes.Reader) ([]interface{}, error) {
+	return ReadDeltaByteArrayN(bytesReader, maxValues)
+}
+
+//Read values encoded with DELTA_BYTE_ARRAY, it fails if there are more than cnt values
+func ReadDeltaByteArrayN(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
 	var (
 		res []interface{}
 		err error
 	)
 
-	prefixLengths, err := ReadDeltaBinaryPackedINT64(bytesReader)
+	prefixLengths, err := ReadDeltaBinaryPackedINT64N(bytesReader, cnt)
 	if err != nil {
 		return res, err
 	}
-	suffixes, err := ReadDeltaLengthByteArray(bytesReader)
+	suffixes, err := ReadDeltaLengthByteArrayN(bytesReader, cnt)
 	if err != nil {
 		return res, err
 	}
+	if len(suffixes) != len(prefixLengths) {
+		return res, fmt.Errorf("DELTA_BYTE_ARRAY data has %v prefixes and %v suffixes", len(prefixLengths), len(suffixes))
+	}
 	res = make([]interface{}, len(prefixLengths))
+	if len(res) == 0 {
+		return res, nil
+	}
 
 	res[0] = suffixes[0]
 	for i := 1; i < len(prefixLengths); i++ {
 		prefixLength := prefixLengths[i].(int64)
+		if prefixLength < 0 || prefixLength > int64(len(res[i-1].(string))) {
+			return res, fmt.Errorf("invalid DELTA_BYTE_ARRAY prefix length %v", prefixLength)
+		}
 		prefix := res[i-1].(string)[:prefixLength]
 		suffix := suffixes[i].(string)
 		res[i] = prefix + suffix
@@ -435,6 +740,9 @@ func ReadDeltaByteArray(bytesReader *bytes.Reader) ([]interface{}, error) {
 }
 
 func ReadByteStreamSplitFloat32(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
+	if err := checkSize(bytesReader, cnt, 4); err != nil {
+		return nil, err
+	}
 
 	res := make([]interface{}, cnt)
 	buf := make([]byte, cnt*4)
@@ -458,6 +766,9 @@ func ReadByteStreamSplitFloat32(bytesReader *bytes.Reader, cnt uint64) ([]interf
 }
 
 func ReadByteStreamSplitFloat64(bytesReader *bytes.Reader, cnt uint64) ([]interface{}, error) {
+	if err := checkSize(bytesReader, cnt, 8); err != nil {
+		return nil, err
+	}
 
 	res := make([]interface{}, cnt)
 	buf := make([]byte, cnt*8)
@@ -245,3 +245,76 @@ func TestReadBitPacked(t *testing.T) {
 		}
 	}
 }
+
+func TestReadTypedValues(t *testing.T) {
+	int32s := []int32{0, 1, -2, math.MaxInt32}
+	res32, err := ReadPlainInt32s(bytes.NewReader(WritePlainInt32s(int32s)), uint64(len(int32s)))
+	if err != nil || fmt.Sprintf("%v", res32) != fmt.Sprintf("%v", int32s) {
+		t.Errorf("ReadPlainInt32s error, expect %v, get %v, %v", int32s, res32, err)
+	}
+
+	strs := []string{"", "a", "parquet"}
+	resStrs, err := ReadPlainByteArrays(bytes.NewReader(WritePlainByteArrays(strs)), uint64(len(strs)))
+	if err != nil || fmt.Sprintf("%q", resStrs) != fmt.Sprintf("%q", strs) {
+		t.Errorf("ReadPlainByteArrays error, expect %q, get %q, %v", strs, resStrs, err)
+	}
+
+	fixed := []string{"abc", "def"}
+	resFixed, err := ReadPlainFixedLenByteArrays(bytes.NewReader(WritePlainFixedLenByteArrays(fixed)), uint64(len(fixed)), 3)
+	if err != nil || fmt.Sprintf("%q", resFixed) != fmt.Sprintf("%q", fixed) {
+		t.Errorf("ReadPlainFixedLenByteArrays error, expect %q, get %q, %v", fixed, resFixed, err)
+	}
+
+	if _, err := ReadPlainInt32s(bytes.NewReader([]byte{1, 2}), 1); err == nil {
+		t.Errorf("ReadPlainInt32s should fail on truncated data")
+	}
+}
+
+func TestReadMalformed(t *testing.T) {
+	prefixes := func(lens ...int32) []byte {
+		values := make([]interface{}, len(lens))
+		for i, l := range lens {
+			values[i] = l
+		}
+		return WriteDeltaINT32(values)
+	}
+
+	testData := []struct {
+		name string
+		read func() (interface{}, error)
+	}{
+		{"truncated INT32", func() (interface{}, error) {
+			return ReadPlainINT32(bytes.NewReader([]byte{1, 2}), 1)
+		}},
+		{"BYTE_ARRAY length", func() (interface{}, error) {
+			return ReadPlainBYTE_ARRAY(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 'a'}), 1)
+		}},
+		{"too many FIXED_LEN_BYTE_ARRAY", func() (interface{}, error) {
+			return ReadPlainFIXED_LEN_BYTE_ARRAY(bytes.NewReader([]byte{'a', 'b'}), 1<<40, 1<<20)
+		}},
+		{"varint overflow", func() (interface{}, error) {
+			return ReadUnsignedVarInt(bytes.NewReader(bytes.Repeat([]byte{0xff}, 11)))
+		}},
+		{"bit width", func() (interface{}, error) {
+			return ReadRLEBitPackedHybrid(bytes.NewReader([]byte{2, 0, 0, 0, 3, 1}), 65, 0)
+		}},
+		{"delta block size", func() (interface{}, error) {
+			return ReadDeltaBinaryPackedINT64(bytes.NewReader([]byte{0, 1, 1, 0}))
+		}},
+		{"delta values count", func() (interface{}, error) {
+			return ReadDeltaBinaryPackedINT32N(bytes.NewReader(WriteDeltaINT32([]interface{}{int32(1), int32(2), int32(3)})), 2)
+		}},
+		{"delta byte array count", func() (interface{}, error) {
+			return ReadDeltaByteArray(bytes.NewReader(append(prefixes(0, 0, 0), WriteDeltaLengthByteArray([]interface{}{"a"})...)))
+		}},
+		{"delta byte array prefix", func() (interface{}, error) {
+			return ReadDeltaByteArray(bytes.NewReader(append(prefixes(0, 5), WriteDeltaLengthByteArray([]interface{}{"a", "b"})...)))
+		}},
+	}
+
+	for _, data := range testData {
+		if res, err := data.read(); err == nil {
+			t.Errorf("%v: expect an error, get %v", data.name, res)
+		}
+	}
+}
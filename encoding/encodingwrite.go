@@ -129,6 +129,77 @@ func WritePlainFIXED_LEN_BYTE_ARRAY(arrays []interface{}) []byte {
 	return bufWriter.Bytes()
 }
 
+func WritePlainBools(vals []bool) []byte {
+	res := make([]byte, (len(vals)+7)/8)
+	for i, val := range vals {
+		if val {
+			res[i/8] = res[i/8] | (1 << uint32(i%8))
+		}
+	}
+	return res
+}
+
+func WritePlainInt32s(nums []int32) []byte {
+	buf := make([]byte, len(nums)*4)
+	for i, n := range nums {
+		binary.LittleEndian.PutUint32(buf[i*4:], uint32(n))
+	}
+	return buf
+}
+
+func WritePlainInt64s(nums []int64) []byte {
+	buf := make([]byte, len(nums)*8)
+	for i, n := range nums {
+		binary.LittleEndian.PutUint64(buf[i*8:], uint64(n))
+	}
+	return buf
+}
+
+func WritePlainFloat32s(nums []float32) []byte {
+	buf := make([]byte, len(nums)*4)
+	for i, n := range nums {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(n))
+	}
+	return buf
+}
+
+func WritePlainFloat64s(nums []float64) []byte {
+	buf := make([]byte, len(nums)*8)
+	for i, n := range nums {
+		binary.LittleEndian.PutUint64(buf[i*8:], math.Float64bits(n))
+	}
+	return buf
+}
+
+func WritePlainByteArrays(arrays []string) []byte {
+	bufLen := 0
+	for _, array := range arrays {
+		bufLen += 4 + len(array)
+	}
+
+	buf := make([]byte, bufLen)
+	pos := 0
+	for _, array := range arrays {
+		binary.LittleEndian.PutUint32(buf[pos:], uint32(len(array)))
+		pos += 4
+		pos += copy(buf[pos:], array)
+	}
+	return buf
+}
+
+func WritePlainFixedLenByteArrays(arrays []string) []byte {
+	bufLen := 0
+	for _, array := range arrays {
+		bufLen += len(array)
+	}
+
+	buf := make([]byte, 0, bufLen)
+	for _, array := range arrays {
+		buf = append(buf, array...)
+	}
+	return buf
+}
+
 func WriteUnsignedVarInt(num uint64) []byte {
 	byteNum := (bits.Len64(uint64(num)) + 6) / 7
 	if byteNum == 0 {
@@ -214,11 +285,14 @@ func WriteRLEBitPackedHybridInt32(vals []int32, bitWidths int32) []byte {
 }
 
 func WriteBitPacked(vals []interface{}, bitWidth int64, ifHeader bool) []byte {
-	ln := len(vals)
+	return WriteBitPackedInt64(ToInt64(vals), bitWidth, ifHeader)
+}
+
+func WriteBitPackedInt64(valsInt []int64, bitWidth int64, ifHeader bool) []byte {
+	ln := len(valsInt)
 	if ln <= 0 {
 		return nil
 	}
-	valsInt := ToInt64(vals)
 
 	header := ((ln/8)<<1 | 1)
 	headerBuf := WriteUnsignedVarInt(uint64(header))
@@ -285,13 +359,21 @@ func WriteDelta(nums []interface{}) []byte {
 }
 
 func WriteDeltaINT32(nums []interface{}) []byte {
+	vals := make([]int32, len(nums))
+	for i := 0; i < len(nums); i++ {
+		vals[i] = nums[i].(int32)
+	}
+	return WriteDeltaInt32s(vals)
+}
+
+func WriteDeltaInt32s(nums []int32) []byte {
 	res := make([]byte, 0)
 	var blockSize uint64 = 128
 	var numMiniBlocksInBlock uint64 = 4
 	var numValuesInMiniBlock uint64 = 32
 	var totalNumValues uint64 = uint64(len(nums))
 
-	num := nums[0].(int32)
+	num := nums[0]
 	var firstValue uint64 = uint64((num >> 31) ^ (num << 1))
 
 	res = append(res, WriteUnsignedVarInt(blockSize)...)
@@ -299,13 +381,15 @@ func WriteDeltaINT32(nums []interface{}) []byte {
 	res = append(res, WriteUnsignedVarInt(totalNumValues)...)
 	res = append(res, WriteUnsignedVarInt(firstValue)...)
 
+	blockBuf := make([]int32, 0, blockSize)
+	miniBlockBuf := make([]int64, numValuesInMiniBlock)
 	i := 1
 	for i < len(nums) {
-		blockBuf := make([]interface{}, 0)
+		blockBuf = blockBuf[:0]
 		var minDelta int32 = 0x7FFFFFFF
 
 		for i < len(nums) && uint64(len(blockBuf)) < blockSize {
-			delta := nums[i].(int32) - nums[i-1].(int32)
+			delta := nums[i] - nums[i-1]
 			blockBuf = append(blockBuf, delta)
 			if delta < minDelta {
 				minDelta = delta
@@ -322,9 +406,9 @@ func WriteDeltaINT32(nums []interface{}) []byte {
 		for j := 0; uint64(j) < numMiniBlocksInBlock; j++ {
 			var maxValue int32 = 0
 			for k := uint64(j) * numValuesInMiniBlock; k < uint64(j+1)*numValuesInMiniBlock; k++ {
-				blockBuf[k] = blockBuf[k].(int32) - minDelta
-				if blockBuf[k].(int32) > maxValue {
-					maxValue = blockBuf[k].(int32)
+				blockBuf[k] = blockBuf[k] - minDelta
+				if blockBuf[k] > maxValue {
+					maxValue = blockBuf[k]
 				}
 			}
 			bitWidths[j] = byte(bits.Len32(uint32(maxValue)))
@@ -335,7 +419,10 @@ func WriteDeltaINT32(nums []interface{}) []byte {
 		res = append(res, bitWidths...)
 
 		for j := 0; uint64(j) < numMiniBlocksInBlock; j++ {
-			res = append(res, WriteBitPacked((blockBuf[uint64(j)*numValuesInMiniBlock:uint64(j+1)*numValuesInMiniBlock]), int64(bitWidths[j]), false)...)
+			for k := range miniBlockBuf {
+				miniBlockBuf[k] = int64(blockBuf[uint64(j)*numValuesInMiniBlock+uint64(k)])
+			}
+			res = append(res, WriteBitPackedInt64(miniBlockBuf, int64(bitWidths[j]), false)...)
 		}
 
 	}
@@ -343,13 +430,21 @@ func WriteDeltaINT32(nums []interface{}) []byte {
 }
 
 func WriteDeltaINT64(nums []interface{}) []byte {
+	vals := make([]int64, len(nums))
+	for i := 0; i < len(nums); i++ {
+		vals[i] = nums[i].(int64)
+	}
+	return WriteDeltaInt64s(vals)
+}
+
+func WriteDeltaInt64s(nums []int64) []byte {
 	res := make([]byte, 0)
 	var blockSize uint64 = 128
 	var numMiniBlocksInBlock uint64 = 4
 	var numValuesInMiniBlock uint64 = 32
 	var totalNumValues uint64 = uint64(len(nums))
 
-	num := nums[0].(int64)
+	num := nums[0]
 	var firstValue uint64 = uint64((num >> 63) ^ (num << 1))
 
 	res = append(res, WriteUnsignedVarInt(blockSize)...)
@@ -357,13 +452,14 @@ func WriteDeltaINT64(nums []interface{}) []byte {
 	res = append(res, WriteUnsignedVarInt(totalNumValues)...)
 	res = append(res, WriteUnsignedVarInt(firstValue)...)
 
+	blockBuf := make([]int64, 0, blockSize)
 	i := 1
 	for i < len(nums) {
-		blockBuf := make([]interface{}, 0)
+		blockBuf = blockBuf[:0]
 		var minDelta int64 = 0x7FFFFFFFFFFFFFFF
 
 		for i < len(nums) && uint64(len(blockBuf)) < blockSize {
-			delta := nums[i].(int64) - nums[i-1].(int64)
+			delta := nums[i] - nums[i-1]
 			blockBuf = append(blockBuf, delta)
 			if delta < minDelta {
 				minDelta = delta
@@ -380,9 +476,9 @@ func WriteDeltaINT64(nums []interface{}) []byte {
 		for j := 0; uint64(j) < numMiniBlocksInBlock; j++ {
 			var maxValue int64 = 0
 			for k := uint64(j) * numValuesInMiniBlock; k < uint64(j+1)*numValuesInMiniBlock; k++ {
-				blockBuf[k] = blockBuf[k].(int64) - minDelta
-				if blockBuf[k].(int64) > maxValue {
-					maxValue = blockBuf[k].(int64)
+				blockBuf[k] = blockBuf[k] - minDelta
+				if blockBuf[k] > maxValue {
+					maxValue = blockBuf[k]
 				}
 			}
 			bitWidths[j] = byte(bits.Len64(uint64(maxValue)))
@@ -393,7 +489,7 @@ func WriteDeltaINT64(nums []interface{}) []byte {
 		res = append(res, bitWidths...)
 
 		for j := 0; uint64(j) < numMiniBlocksInBlock; j++ {
-			res = append(res, WriteBitPacked((blockBuf[uint64(j)*numValuesInMiniBlock:uint64(j+1)*numValuesInMiniBlock]), int64(bitWidths[j]), false)...)
+			res = append(res, WriteBitPackedInt64(blockBuf[uint64(j)*numValuesInMiniBlock:uint64(j+1)*numValuesInMiniBlock], int64(bitWidths[j]), false)...)
 		}
 
 	}
@@ -401,18 +497,24 @@ func WriteDeltaINT64(nums []interface{}) []byte {
 }
 
 func WriteDeltaLengthByteArray(arrays []interface{}) []byte {
+	strs := make([]string, len(arrays))
+	for i := 0; i < len(arrays); i++ {
+		strs[i] = reflect.ValueOf(arrays[i]).String()
+	}
+	return WriteDeltaLengthByteArrays(strs)
+}
+
+func WriteDeltaLengthByteArrays(arrays []string) []byte {
 	ln := len(arrays)
-	lengthArray := make([]interface{}, ln)
+	lengthArray := make([]int32, ln)
 	for i := 0; i < ln; i++ {
-		array := reflect.ValueOf(arrays[i]).String()
-		lengthArray[i] = int32(len(array))
+		lengthArray[i] = int32(len(arrays[i]))
 	}
 
-	res := WriteDeltaINT32(lengthArray)
+	res := WriteDeltaInt32s(lengthArray)
 
 	for i := 0; i < ln; i++ {
-		array := reflect.ValueOf(arrays[i]).String()
-		res = append(res, array...)
+		res = append(res, arrays[i]...)
 	}
 	return res
 }
@@ -466,19 +568,27 @@ func WriteBitPackedDeprecated(vals []interface{}, bitWidth int64) []byte {
 }
 
 func WriteDeltaByteArray(arrays []interface{}) []byte {
+	strs := make([]string, len(arrays))
+	for i := 0; i < len(arrays); i++ {
+		strs[i] = reflect.ValueOf(arrays[i]).String()
+	}
+	return WriteDeltaByteArrays(strs)
+}
+
+func WriteDeltaByteArrays(arrays []string) []byte {
 	ln := len(arrays)
 	if ln <= 0 {
 		return []byte{}
 	}
 
-	prefixLengths := make([]interface{}, ln)
-	suffixes := make([]interface{}, ln)
+	prefixLengths := make([]int32, ln)
+	suffixes := make([]string, ln)
 	prefixLengths[0] = int32(0)
 	suffixes[0] = arrays[0]
 
 	for i := 1; i < ln; i++ {
-		s1 := reflect.ValueOf(arrays[i-1]).String()
-		s2 := reflect.ValueOf(arrays[i]).String()
+		s1 := arrays[i-1]
+		s2 := arrays[i]
 		l1 := len(s1)
 		l2 := len(s2)
 		j := 0
@@ -492,8 +602,8 @@ func WriteDeltaByteArray(arrays []interface{}) []byte {
 		suffixes[i] = (s2[j:])
 	}
 
-	prefixBuf := WriteDeltaINT32(prefixLengths)
-	suffixBuf := WriteDeltaLengthByteArray(suffixes)
+	prefixBuf := WriteDeltaInt32s(prefixLengths)
+	suffixBuf := WriteDeltaLengthByteArrays(suffixes)
 
 	res := make([]byte, 0)
 	res = append(res, prefixBuf...)
@@ -517,13 +627,21 @@ func WriteByteStreamSplit(nums []interface{}) []byte {
 }
 
 func WriteByteStreamSplitFloat32(vals []interface{}) []byte {
+	nums := make([]float32, len(vals))
+	for i := 0; i < len(vals); i++ {
+		nums[i] = vals[i].(float32)
+	}
+	return WriteByteStreamSplitFloat32s(nums)
+}
+
+func WriteByteStreamSplitFloat32s(vals []float32) []byte {
 	ln := len(vals)
 	if ln <= 0 {
 		return []byte{}
 	}
 	buf := make([]byte, ln*4)
 	for i, n := range vals {
-		v := math.Float32bits(n.(float32))
+		v := math.Float32bits(n)
 		buf[i] = byte(v)
 		buf[ln+i] = byte(v >> 8)
 		buf[ln*2+i] = byte(v >> 16)
@@ -533,6 +651,14 @@ func WriteByteStreamSplitFloat32(vals []interface{}) []byte {
 }
 
 func WriteByteStreamSplitFloat64(vals []interface{}) []byte {
+	nums := make([]float64, len(vals))
+	for i := 0; i < len(vals); i++ {
+		nums[i] = vals[i].(float64)
+	}
+	return WriteByteStreamSplitFloat64s(nums)
+}
+
+func WriteByteStreamSplitFloat64s(vals []float64) []byte {
 	ln := len(vals)
 	if ln <= 0 {
 		return []byte{}
@@ -540,7 +666,7 @@ func WriteByteStreamSplitFloat64(vals []interface{}) []byte {
 
 	buf := make([]byte, ln*8)
 	for i, n := range vals {
-		v := math.Float64bits(n.(float64))
+		v := math.Float64bits(n)
 		buf[i] = byte(v)
 		buf[ln+i] = byte(v >> 8)
 		buf[ln*2+i] = byte(v >> 16)
@@ -300,3 +300,31 @@ func TestWriteBitPackedDeprecated(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteTypedValues(t *testing.T) {
+	int32s, int32Values := []int32{1, -2, 3, 1 << 30, -1 << 31}, []interface{}{int32(1), int32(-2), int32(3), int32(1 << 30), int32(-1 << 31)}
+	int64s, int64Values := []int64{1, -2, 3, 1 << 40, -1 << 63}, []interface{}{int64(1), int64(-2), int64(3), int64(1 << 40), int64(-1 << 63)}
+	strs, strValues := []string{"", "abc", "abd", "b", "parquet"}, []interface{}{"", "abc", "abd", "b", "parquet"}
+	float64s, float64Values := []float64{0, -1.5, 3.25}, []interface{}{float64(0), float64(-1.5), float64(3.25)}
+
+	testData := []struct {
+		name     string
+		actual   []byte
+		expected []byte
+	}{
+		{"WritePlainInt32s", WritePlainInt32s(int32s), WritePlainINT32(int32Values)},
+		{"WritePlainInt64s", WritePlainInt64s(int64s), WritePlainINT64(int64Values)},
+		{"WritePlainByteArrays", WritePlainByteArrays(strs), WritePlainBYTE_ARRAY(strValues)},
+		{"WriteDeltaInt32s", WriteDeltaInt32s(int32s), WriteDeltaINT32(int32Values)},
+		{"WriteDeltaInt64s", WriteDeltaInt64s(int64s), WriteDeltaINT64(int64Values)},
+		{"WriteDeltaLengthByteArrays", WriteDeltaLengthByteArrays(strs), WriteDeltaLengthByteArray(strValues)},
+		{"WriteDeltaByteArrays", WriteDeltaByteArrays(strs), WriteDeltaByteArray(strValues)},
+		{"WriteByteStreamSplitFloat64s", WriteByteStreamSplitFloat64s(float64s), WriteByteStreamSplitFloat64(float64Values)},
+	}
+
+	for _, data := range testData {
+		if string(data.actual) != string(data.expected) {
+			t.Errorf("%s error, expect %v, get %v", data.name, data.expected, data.actual)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+//go:build gofuzz
+// +build gofuzz
+
+package encoding
+
+import (
+	"bytes"
+
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// FuzzEncoding is a go-fuzz target of the decoding of the encodings:
+//
+//	go-fuzz-build -func FuzzEncoding github.com/xitongsys/parquet-go/encoding
+//	go-fuzz -bin encoding-fuzz.zip -workdir fuzz/encoding
+//
+// The first byte of data selects the decoder, the second one is the bit width
+// or the fixed length of the values and the remaining bytes are decoded.
+func FuzzEncoding(data []byte) int {
+	if len(data) < 2 {
+		return -1
+	}
+	const cnt = 1024
+	bitWidth := uint64(data[1])
+	bytesReader := bytes.NewReader(data[2:])
+
+	var err error
+	switch data[0] % 16 {
+	case 0:
+		_, err = ReadPlain(bytesReader, parquet.Type_BOOLEAN, cnt, bitWidth)
+	case 1:
+		_, err = ReadPlain(bytesReader, parquet.Type_INT32, cnt, bitWidth)
+	case 2:
+		_, err = ReadPlain(bytesReader, parquet.Type_INT96, cnt, bitWidth)
+	case 3:
+		_, err = ReadPlain(bytesReader, parquet.Type_BYTE_ARRAY, cnt, bitWidth)
+	case 4:
+		_, err = ReadPlain(bytesReader, parquet.Type_FIXED_LEN_BYTE_ARRAY, cnt, bitWidth)
+	case 5:
+		_, err = ReadPlainByteArrays(bytesReader, cnt)
+	case 6:
+		_, err = ReadPlainFixedLenByteArrays(bytesReader, cnt, bitWidth)
+	case 7:
+		_, err = ReadPlainBools(bytesReader, cnt)
+	case 8:
+		_, err = ReadRLEBitPackedHybridN(bytesReader, bitWidth, 0, cnt)
+	case 9:
+		_, err = ReadRLEBitPackedHybridN(bytesReader, bitWidth, uint64(bytesReader.Len()), cnt)
+	case 10:
+		_, err = ReadDeltaBinaryPackedINT32N(bytesReader, cnt)
+	case 11:
+		_, err = ReadDeltaBinaryPackedINT64N(bytesReader, cnt)
+	case 12:
+		_, err = ReadDeltaLengthByteArrayN(bytesReader, cnt)
+	case 13:
+		_, err = ReadDeltaByteArrayN(bytesReader, cnt)
+	case 14:
+		_, err = ReadByteStreamSplitFloat32(bytesReader, cnt)
+	case 15:
+		_, err = ReadByteStreamSplitFloat64(bytesReader, cnt)
+	}
+	if err != nil {
+		return 0
+	}
+	return 1
+}
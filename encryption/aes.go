@@ -0,0 +1,200 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Module types, used in the AADs of the encrypted modules
+const (
+	ModuleFooter int8 = iota
+	ModuleColumnMetaData
+	ModuleDataPage
+	ModuleDictionaryPage
+	ModuleDataPageHeader
+	ModuleDictionaryPageHeader
+	ModuleColumnIndex
+	ModuleOffsetIndex
+	ModuleBloomFilterHeader
+	ModuleBloomFilterBitset
+)
+
+const (
+	NonceLength  = 12
+	TagLength    = 16
+	LengthLength = 4
+	//Size of the signature of a plaintext footer: nonce and tag
+	SignatureLength = NonceLength + TagLength
+
+	aadFileUniqueLength = 8
+)
+
+// Source of the nonces and of the file unique AADs
+var randReader io.Reader = rand.Reader
+
+func newBlock(key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 16, 24, 32:
+		return aes.NewCipher(key)
+	}
+	return nil, fmt.Errorf("invalid AES key length %v, it must be 16, 24 or 32 bytes", len(key))
+}
+
+func newNonce() ([]byte, error) {
+	nonce := make([]byte, NonceLength)
+	if _, err := io.ReadFull(randReader, nonce); err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+// Create the AAD of a module. The page ordinal is only used for data pages and
+// their headers, and the footer AAD has no ordinal.
+func ModuleAAD(fileAAD []byte, moduleType int8, rowGroupOrdinal, columnOrdinal, pageOrdinal int16) []byte {
+	res := make([]byte, 0, len(fileAAD)+7)
+	res = append(res, fileAAD...)
+	res = append(res, byte(moduleType))
+	if moduleType == ModuleFooter {
+		return res
+	}
+
+	var buf [2]byte
+	binary.LittleEndian.PutUint16(buf[:], uint16(rowGroupOrdinal))
+	res = append(res, buf[:]...)
+	binary.LittleEndian.PutUint16(buf[:], uint16(columnOrdinal))
+	res = append(res, buf[:]...)
+	if moduleType == ModuleDataPage || moduleType == ModuleDataPageHeader {
+		binary.LittleEndian.PutUint16(buf[:], uint16(pageOrdinal))
+		res = append(res, buf[:]...)
+	}
+	return res
+}
+
+// Check that an ordinal fits in the 2 bytes of the AADs
+func checkOrdinal(name string, ordinal int) error {
+	if ordinal < 0 || ordinal > math.MaxInt16 {
+		return fmt.Errorf("encrypted files can't have more than %v %ss", math.MaxInt16+1, name)
+	}
+	return nil
+}
+
+func appendLength(res []byte, length int) []byte {
+	var buf [LengthLength]byte
+	binary.LittleEndian.PutUint32(buf[:], uint32(length))
+	return append(res, buf[:]...)
+}
+
+// Encrypt with AES GCM. The result is length, nonce, ciphertext and tag.
+func gcmEncrypt(key, nonce, plaintext, aad []byte) ([]byte, error) {
+	block, err := newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	length := NonceLength + len(plaintext) + TagLength
+	res := make([]byte, 0, LengthLength+length)
+	res = appendLength(res, length)
+	res = append(res, nonce...)
+	return gcm.Seal(res, nonce, plaintext, aad), nil
+}
+
+// Decrypt a module encrypted with AES GCM
+func gcmDecrypt(key, module, aad []byte) ([]byte, error) {
+	ciphertext, err := moduleData(module, NonceLength+TagLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	res, err := gcm.Open(nil, ciphertext[:NonceLength], ciphertext[NonceLength:], aad)
+	if err != nil {
+		return nil, errors.New("failed to decrypt module: wrong key or corrupted data")
+	}
+	return res, nil
+}
+
+func ctrIV(nonce []byte) []byte {
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, nonce)
+	iv[aes.BlockSize-1] = 1
+	return iv
+}
+
+// Encrypt with AES CTR. The result is length, nonce and ciphertext.
+func ctrEncrypt(key, nonce, plaintext []byte) ([]byte, error) {
+	block, err := newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+
+	length := NonceLength + len(plaintext)
+	res := make([]byte, LengthLength+length)
+	binary.LittleEndian.PutUint32(res, uint32(length))
+	copy(res[LengthLength:], nonce)
+	cipher.NewCTR(block, ctrIV(nonce)).XORKeyStream(res[LengthLength+NonceLength:], plaintext)
+	return res, nil
+}
+
+// Decrypt a module encrypted with AES CTR
+func ctrDecrypt(key, module []byte) ([]byte, error) {
+	ciphertext, err := moduleData(module, NonceLength)
+	if err != nil {
+		return nil, err
+	}
+	block, err := newBlock(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]byte, len(ciphertext)-NonceLength)
+	cipher.NewCTR(block, ctrIV(ciphertext[:NonceLength])).XORKeyStream(res, ciphertext[NonceLength:])
+	return res, nil
+}
+
+// Check the length of a module and return its data after the length
+func moduleData(module []byte, minLength int) ([]byte, error) {
+	if len(module) < LengthLength {
+		return nil, fmt.Errorf("encrypted module is too short: %v bytes", len(module))
+	}
+	length := binary.LittleEndian.Uint32(module)
+	if int64(length) != int64(len(module)-LengthLength) || int(length) < minLength {
+		return nil, fmt.Errorf("invalid encrypted module length %v", length)
+	}
+	return module[LengthLength:], nil
+}
+
+// ReadModule reads an encrypted module, which starts with its length
+func ReadModule(r io.Reader) ([]byte, error) {
+	var buf [LengthLength]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, err
+	}
+	length := binary.LittleEndian.Uint32(buf[:])
+	if length < NonceLength || length > math.MaxInt32 {
+		return nil, fmt.Errorf("invalid encrypted module length %v", length)
+	}
+	res := make([]byte, LengthLength+int(length))
+	copy(res, buf[:])
+	if _, err := io.ReadFull(r, res[LengthLength:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,196 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// FileDecryptor decrypts the modules of a read file. It's safe for concurrent use.
+type FileDecryptor struct {
+	props             *FileDecryptionProperties
+	fileAAD           []byte
+	aadErr            error
+	ctr               bool
+	footerKeyMetadata []byte
+
+	mutex sync.Mutex
+	keys  map[string][]byte
+}
+
+// NewFileDecryptor creates the decryptor of a file from its encryption
+// algorithm and footer key metadata. The properties may be nil, in which case
+// only the plaintext columns of files with a plaintext footer can be read.
+func NewFileDecryptor(props *FileDecryptionProperties, algorithm *parquet.EncryptionAlgorithm, footerKeyMetadata []byte) (*FileDecryptor, error) {
+	if props == nil {
+		props = &FileDecryptionProperties{}
+	}
+
+	var aadPrefix, aadFileUnique []byte
+	var supplyAADPrefix bool
+	switch {
+	case algorithm == nil:
+		return nil, errors.New("no encryption algorithm")
+	case algorithm.IsSetAES_GCM_V1():
+		aadPrefix, aadFileUnique = algorithm.AES_GCM_V1.AadPrefix, algorithm.AES_GCM_V1.AadFileUnique
+		supplyAADPrefix = algorithm.AES_GCM_V1.GetSupplyAadPrefix()
+	case algorithm.IsSetAES_GCM_CTR_V1():
+		aadPrefix, aadFileUnique = algorithm.AES_GCM_CTR_V1.AadPrefix, algorithm.AES_GCM_CTR_V1.AadFileUnique
+		supplyAADPrefix = algorithm.AES_GCM_CTR_V1.GetSupplyAadPrefix()
+	default:
+		return nil, errors.New("unknown encryption algorithm")
+	}
+
+	res := &FileDecryptor{
+		props:             props,
+		ctr:               algorithm.IsSetAES_GCM_CTR_V1(),
+		footerKeyMetadata: footerKeyMetadata,
+		keys:              make(map[string][]byte),
+	}
+
+	if len(props.AADPrefix) > 0 {
+		if len(aadPrefix) > 0 && !bytes.Equal(aadPrefix, props.AADPrefix) {
+			return nil, errors.New("supplied AAD prefix doesn't match the AAD prefix of the file")
+		}
+		aadPrefix = props.AADPrefix
+	} else if supplyAADPrefix {
+		//the plaintext columns can still be read
+		res.aadErr = errors.New("the file requires an AAD prefix, which isn't supplied")
+	}
+	res.fileAAD = append(append([]byte{}, aadPrefix...), aadFileUnique...)
+	return res, nil
+}
+
+func (d *FileDecryptor) key(path string, keyMetadata []byte) ([]byte, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if key, ok := d.keys[path]; ok {
+		return key, nil
+	}
+
+	var key []byte
+	if path == "" {
+		key = d.props.FooterKey
+	} else {
+		key = d.props.ColumnKeys[path]
+	}
+	if key == nil && d.props.KeyRetriever != nil {
+		var err error
+		if key, err = d.props.KeyRetriever.GetKey(keyMetadata); err != nil {
+			return nil, err
+		}
+	}
+	if key == nil {
+		if path == "" {
+			return nil, errors.New("no footer key to decrypt the file")
+		}
+		return nil, fmt.Errorf("no key to decrypt column %v", path)
+	}
+	if _, err := newBlock(key); err != nil {
+		return nil, err
+	}
+	d.keys[path] = key
+	return key, nil
+}
+
+func (d *FileDecryptor) footerKey() ([]byte, error) {
+	if d.aadErr != nil {
+		return nil, d.aadErr
+	}
+	return d.key("", d.footerKeyMetadata)
+}
+
+// DecryptFooter decrypts an encrypted footer module
+func (d *FileDecryptor) DecryptFooter(module []byte) ([]byte, error) {
+	key, err := d.footerKey()
+	if err != nil {
+		return nil, err
+	}
+	return gcmDecrypt(key, module, ModuleAAD(d.fileAAD, ModuleFooter, 0, 0, 0))
+}
+
+// VerifyFooterSignature verifies the signature of a plaintext footer
+func (d *FileDecryptor) VerifyFooterSignature(footer, signature []byte) error {
+	if d.props.DisableFooterSignatureVerification {
+		return nil
+	}
+	if len(signature) != SignatureLength {
+		return fmt.Errorf("invalid footer signature length %v", len(signature))
+	}
+	key, err := d.footerKey()
+	if err != nil {
+		return err
+	}
+	encrypted, err := gcmEncrypt(key, signature[:NonceLength], footer, ModuleAAD(d.fileAAD, ModuleFooter, 0, 0, 0))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(encrypted[len(encrypted)-TagLength:], signature[NonceLength:]) != 1 {
+		return errors.New("footer signature verification failed")
+	}
+	return nil
+}
+
+// ColumnDecryptor returns the decryptor of a column chunk, it's nil if the column isn't encrypted
+func (d *FileDecryptor) ColumnDecryptor(crypto *parquet.ColumnCryptoMetaData, rowGroupOrdinal, columnOrdinal int) (*ColumnDecryptor, error) {
+	if crypto == nil {
+		return nil, nil
+	}
+	if err := checkOrdinal("row group", rowGroupOrdinal); err != nil {
+		return nil, err
+	}
+	if err := checkOrdinal("column", columnOrdinal); err != nil {
+		return nil, err
+	}
+
+	var key []byte
+	var err error
+	switch {
+	case crypto.IsSetENCRYPTION_WITH_FOOTER_KEY():
+		key, err = d.footerKey()
+	case crypto.IsSetENCRYPTION_WITH_COLUMN_KEY():
+		if d.aadErr != nil {
+			return nil, d.aadErr
+		}
+		column := crypto.ENCRYPTION_WITH_COLUMN_KEY
+		key, err = d.key(ColumnPath(column.PathInSchema), column.KeyMetadata)
+	default:
+		err = errors.New("unknown column encryption")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &ColumnDecryptor{
+		key:             key,
+		fileAAD:         d.fileAAD,
+		ctr:             d.ctr,
+		rowGroupOrdinal: int16(rowGroupOrdinal),
+		columnOrdinal:   int16(columnOrdinal),
+	}, nil
+}
+
+// ColumnDecryptor decrypts the modules of a column chunk
+type ColumnDecryptor struct {
+	key             []byte
+	fileAAD         []byte
+	ctr             bool
+	rowGroupOrdinal int16
+	columnOrdinal   int16
+}
+
+// DecryptModule decrypts a module of the column chunk. The page ordinal is the
+// index of the data page in the column chunk, only used for the data pages.
+func (d *ColumnDecryptor) DecryptModule(moduleType int8, pageOrdinal int, module []byte) ([]byte, error) {
+	if err := checkOrdinal("page", pageOrdinal); err != nil {
+		return nil, err
+	}
+	if d.ctr && (moduleType == ModuleDataPage || moduleType == ModuleDictionaryPage) {
+		return ctrDecrypt(d.key, module)
+	}
+	return gcmDecrypt(d.key, module, ModuleAAD(d.fileAAD, moduleType, d.rowGroupOrdinal, d.columnOrdinal, int16(pageOrdinal)))
+}
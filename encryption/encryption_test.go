@@ -0,0 +1,186 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+func unhex(s string) []byte {
+	res, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// Test case 4 of the GCM specification
+func TestGCMVector(t *testing.T) {
+	key := unhex("feffe9928665731c6d6a8f9467308308")
+	nonce := unhex("cafebabefacedbaddecaf888")
+	aad := unhex("feedfacedeadbeeffeedfacedeadbeefabaddad2")
+	plaintext := unhex("d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39")
+	ciphertext := unhex("42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091")
+	tag := unhex("5bc94fbc3221a5db94fae95ae7121a47")
+
+	module, err := gcmEncrypt(key, nonce, plaintext, aad)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{12 + 60 + 16, 0, 0, 0}, module[:LengthLength])
+	assert.Equal(t, nonce, module[LengthLength:LengthLength+NonceLength])
+	assert.Equal(t, ciphertext, module[LengthLength+NonceLength:len(module)-TagLength])
+	assert.Equal(t, tag, module[len(module)-TagLength:])
+
+	res, err := gcmDecrypt(key, module, aad)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, res)
+
+	_, err = gcmDecrypt(key, module, aad[1:])
+	assert.Error(t, err)
+	module[len(module)-1]++
+	_, err = gcmDecrypt(key, module, aad)
+	assert.Error(t, err)
+	_, err = gcmDecrypt(key, module[:10], aad)
+	assert.Error(t, err)
+}
+
+func TestCTR(t *testing.T) {
+	key := unhex("2b7e151628aed2a6abf7158809cf4f3c")
+	nonce := unhex("f0f1f2f3f4f5f6f7f8f9fafb")
+	plaintext := bytes.Repeat([]byte("parquet"), 10)
+
+	module, err := ctrEncrypt(key, nonce, plaintext)
+	assert.NoError(t, err)
+	assert.Equal(t, LengthLength+NonceLength+len(plaintext), len(module))
+	assert.NotEqual(t, plaintext, module[LengthLength+NonceLength:])
+
+	res, err := ctrDecrypt(key, module)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, res)
+
+	// the first counter block is the nonce followed by 1
+	block, err := aes.NewCipher(key)
+	assert.NoError(t, err)
+	keyStream := make([]byte, aes.BlockSize)
+	block.Encrypt(keyStream, append(append([]byte{}, nonce...), 0, 0, 0, 1))
+	for i := 0; i < aes.BlockSize; i++ {
+		assert.Equal(t, plaintext[i]^keyStream[i], module[LengthLength+NonceLength+i])
+	}
+}
+
+func TestModuleAAD(t *testing.T) {
+	fileAAD := []byte("file")
+	assert.Equal(t, []byte("file\x00"), ModuleAAD(fileAAD, ModuleFooter, 1, 2, 3))
+	assert.Equal(t, []byte("file\x01\x01\x00\x02\x00"), ModuleAAD(fileAAD, ModuleColumnMetaData, 1, 2, 3))
+	assert.Equal(t, []byte("file\x02\x01\x00\x02\x00\x03\x01"), ModuleAAD(fileAAD, ModuleDataPage, 1, 2, 259))
+	assert.Equal(t, []byte("file\x04\x01\x00\x02\x00\x03\x00"), ModuleAAD(fileAAD, ModuleDataPageHeader, 1, 2, 3))
+	assert.Equal(t, []byte("file\x03\x01\x00\x02\x00"), ModuleAAD(fileAAD, ModuleDictionaryPage, 1, 2, 3))
+}
+
+func TestReadModule(t *testing.T) {
+	module, err := gcmEncrypt(make([]byte, 16), make([]byte, NonceLength), []byte("data"), nil)
+	assert.NoError(t, err)
+	res, err := ReadModule(bytes.NewReader(append(module, 1, 2, 3)))
+	assert.NoError(t, err)
+	assert.Equal(t, module, res)
+
+	_, err = ReadModule(bytes.NewReader(module[:len(module)-1]))
+	assert.Error(t, err)
+	_, err = ReadModule(bytes.NewReader([]byte{1, 0, 0, 0, 0}))
+	assert.Error(t, err)
+}
+
+func TestFileEncryptorDecryptor(t *testing.T) {
+	footerKey := []byte("0123456789012345")
+	columnKey := []byte("1234567890123450")
+	props := &FileEncryptionProperties{
+		FooterKey:               footerKey,
+		FooterKeyMetadata:       []byte("kf"),
+		AADPrefix:               []byte("prefix"),
+		DisableAADPrefixStorage: true,
+		Columns: map[string]*ColumnEncryptionProperties{
+			"a":   {Key: columnKey, KeyMetadata: []byte("kc")},
+			"b.c": {},
+		},
+	}
+	encryptor, err := NewFileEncryptor(props)
+	assert.NoError(t, err)
+	assert.Nil(t, encryptor.Algorithm().AES_GCM_V1.AadPrefix)
+	assert.True(t, encryptor.Algorithm().AES_GCM_V1.GetSupplyAadPrefix())
+	assert.Error(t, encryptor.CheckColumns([][]string{{"a"}, {"b"}}))
+	assert.NoError(t, encryptor.CheckColumns([][]string{{"a"}, {"b", "c"}, {"d"}}))
+
+	footer, err := encryptor.EncryptFooter([]byte("footer"))
+	assert.NoError(t, err)
+	signature, err := encryptor.SignFooter([]byte("footer"))
+	assert.NoError(t, err)
+	assert.Equal(t, SignatureLength, len(signature))
+
+	a, err := encryptor.ColumnEncryptor([]string{"a"}, 1, 0)
+	assert.NoError(t, err)
+	assert.False(t, a.WithFooterKey())
+	bc, err := encryptor.ColumnEncryptor([]string{"b", "c"}, 1, 1)
+	assert.NoError(t, err)
+	assert.True(t, bc.WithFooterKey())
+	d, err := encryptor.ColumnEncryptor([]string{"d"}, 1, 2)
+	assert.NoError(t, err)
+	assert.Nil(t, d)
+	page, err := a.EncryptModule(ModuleDataPage, 5, []byte("page"))
+	assert.NoError(t, err)
+
+	// the AAD prefix must be supplied
+	decryptor, err := NewFileDecryptor(&FileDecryptionProperties{FooterKey: footerKey}, encryptor.Algorithm(), []byte("kf"))
+	assert.NoError(t, err)
+	_, err = decryptor.DecryptFooter(footer)
+	assert.Error(t, err)
+
+	keys := map[string][]byte{"kf": footerKey, "kc": columnKey}
+	decryptor, err = NewFileDecryptor(&FileDecryptionProperties{
+		AADPrefix: []byte("prefix"),
+		KeyRetriever: KeyRetrieverFunc(func(keyMetadata []byte) ([]byte, error) {
+			return keys[string(keyMetadata)], nil
+		}),
+	}, encryptor.Algorithm(), []byte("kf"))
+	assert.NoError(t, err)
+	res, err := decryptor.DecryptFooter(footer)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("footer"), res)
+	assert.NoError(t, decryptor.VerifyFooterSignature([]byte("footer"), signature))
+	assert.Error(t, decryptor.VerifyFooterSignature([]byte("footes"), signature))
+
+	ad, err := decryptor.ColumnDecryptor(a.CryptoMetaData, 1, 0)
+	assert.NoError(t, err)
+	res, err = ad.DecryptModule(ModuleDataPage, 5, page)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("page"), res)
+	// the AAD binds the module to its position
+	_, err = ad.DecryptModule(ModuleDataPage, 4, page)
+	assert.Error(t, err)
+	_, err = ad.DecryptModule(ModuleDictionaryPage, 0, page)
+	assert.Error(t, err)
+
+	decryptor, err = NewFileDecryptor(&FileDecryptionProperties{FooterKey: footerKey, AADPrefix: []byte("prefix")}, encryptor.Algorithm(), nil)
+	assert.NoError(t, err)
+	_, err = decryptor.ColumnDecryptor(a.CryptoMetaData, 1, 0)
+	assert.Error(t, err)
+	_, err = decryptor.ColumnDecryptor(bc.CryptoMetaData, 1, 1)
+	assert.NoError(t, err)
+	_, err = NewFileDecryptor(&FileDecryptionProperties{AADPrefix: []byte("other")}, &parquet.EncryptionAlgorithm{
+		AES_GCM_V1: &parquet.AesGcmV1{AadPrefix: []byte("prefix")},
+	}, nil)
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	_, err := NewFileEncryptor(&FileEncryptionProperties{FooterKey: make([]byte, 15)})
+	assert.Error(t, err)
+	_, err = NewFileEncryptor(&FileEncryptionProperties{FooterKey: make([]byte, 16), DisableAADPrefixStorage: true})
+	assert.Error(t, err)
+	_, err = NewFileEncryptor(&FileEncryptionProperties{FooterKey: make([]byte, 16), Columns: map[string]*ColumnEncryptionProperties{"a": {Key: make([]byte, 8)}}})
+	assert.Error(t, err)
+	_, err = NewFileEncryptor(&FileEncryptionProperties{FooterKey: make([]byte, 32), Algorithm: AesGcmCtrV1})
+	assert.NoError(t, err)
+}
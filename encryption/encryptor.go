@@ -0,0 +1,173 @@
+package encryption
+
+import (
+	"errors"
+	"io"
+
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// FileEncryptor encrypts the modules of a written file
+type FileEncryptor struct {
+	props     *FileEncryptionProperties
+	fileAAD   []byte
+	algorithm *parquet.EncryptionAlgorithm
+}
+
+func NewFileEncryptor(props *FileEncryptionProperties) (*FileEncryptor, error) {
+	if err := props.validate(); err != nil {
+		return nil, err
+	}
+
+	aadFileUnique := make([]byte, aadFileUniqueLength)
+	if _, err := io.ReadFull(randReader, aadFileUnique); err != nil {
+		return nil, err
+	}
+	res := &FileEncryptor{props: props}
+	res.fileAAD = append(append([]byte{}, props.AADPrefix...), aadFileUnique...)
+
+	var aadPrefix []byte
+	var supplyAADPrefix *bool
+	if len(props.AADPrefix) > 0 {
+		if props.DisableAADPrefixStorage {
+			supply := true
+			supplyAADPrefix = &supply
+		} else {
+			aadPrefix = props.AADPrefix
+		}
+	}
+
+	res.algorithm = parquet.NewEncryptionAlgorithm()
+	if props.Algorithm == AesGcmCtrV1 {
+		res.algorithm.AES_GCM_CTR_V1 = &parquet.AesGcmCtrV1{AadPrefix: aadPrefix, AadFileUnique: aadFileUnique, SupplyAadPrefix: supplyAADPrefix}
+	} else {
+		res.algorithm.AES_GCM_V1 = &parquet.AesGcmV1{AadPrefix: aadPrefix, AadFileUnique: aadFileUnique, SupplyAadPrefix: supplyAADPrefix}
+	}
+	return res, nil
+}
+
+// PlaintextFooter reports whether the footer is written in plaintext
+func (e *FileEncryptor) PlaintextFooter() bool {
+	return e.props.PlaintextFooter
+}
+
+// Algorithm returns the encryption algorithm stored in the file
+func (e *FileEncryptor) Algorithm() *parquet.EncryptionAlgorithm {
+	return e.algorithm
+}
+
+// FooterKeyMetadata returns the key metadata of the footer key
+func (e *FileEncryptor) FooterKeyMetadata() []byte {
+	return e.props.FooterKeyMetadata
+}
+
+// FileCryptoMetaData returns the metadata written before an encrypted footer
+func (e *FileEncryptor) FileCryptoMetaData() *parquet.FileCryptoMetaData {
+	res := parquet.NewFileCryptoMetaData()
+	res.EncryptionAlgorithm = e.algorithm
+	res.KeyMetadata = e.props.FooterKeyMetadata
+	return res
+}
+
+// EncryptFooter encrypts a serialized FileMetaData with the footer key
+func (e *FileEncryptor) EncryptFooter(footer []byte) ([]byte, error) {
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, err
+	}
+	return gcmEncrypt(e.props.FooterKey, nonce, footer, ModuleAAD(e.fileAAD, ModuleFooter, 0, 0, 0))
+}
+
+// SignFooter returns the signature of a plaintext footer, which is the nonce
+// and the tag of the footer encrypted with the footer key
+func (e *FileEncryptor) SignFooter(footer []byte) ([]byte, error) {
+	encrypted, err := e.EncryptFooter(footer)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]byte, 0, SignatureLength)
+	res = append(res, encrypted[LengthLength:LengthLength+NonceLength]...)
+	return append(res, encrypted[len(encrypted)-TagLength:]...), nil
+}
+
+// ColumnEncryptor returns the encryptor of a column chunk, it's nil if the column isn't encrypted
+func (e *FileEncryptor) ColumnEncryptor(pathInSchema []string, rowGroupOrdinal, columnOrdinal int) (*ColumnEncryptor, error) {
+	if err := checkOrdinal("row group", rowGroupOrdinal); err != nil {
+		return nil, err
+	}
+	if err := checkOrdinal("column", columnOrdinal); err != nil {
+		return nil, err
+	}
+
+	res := &ColumnEncryptor{
+		fileAAD:         e.fileAAD,
+		ctr:             e.props.Algorithm == AesGcmCtrV1,
+		rowGroupOrdinal: int16(rowGroupOrdinal),
+		columnOrdinal:   int16(columnOrdinal),
+		CryptoMetaData:  parquet.NewColumnCryptoMetaData(),
+	}
+
+	column, ok := e.props.Columns[ColumnPath(pathInSchema)]
+	if len(e.props.Columns) > 0 && !ok {
+		return nil, nil
+	}
+	if column == nil || column.Key == nil {
+		res.key = e.props.FooterKey
+		res.CryptoMetaData.ENCRYPTION_WITH_FOOTER_KEY = parquet.NewEncryptionWithFooterKey()
+	} else {
+		res.key = column.Key
+		res.CryptoMetaData.ENCRYPTION_WITH_COLUMN_KEY = &parquet.EncryptionWithColumnKey{
+			PathInSchema: append([]string{}, pathInSchema...),
+			KeyMetadata:  column.KeyMetadata,
+		}
+	}
+	return res, nil
+}
+
+// ColumnEncryptor encrypts the modules of a column chunk
+type ColumnEncryptor struct {
+	key             []byte
+	fileAAD         []byte
+	ctr             bool
+	rowGroupOrdinal int16
+	columnOrdinal   int16
+
+	//Metadata stored in the ColumnChunk
+	CryptoMetaData *parquet.ColumnCryptoMetaData
+}
+
+// WithFooterKey reports whether the column is encrypted with the footer key
+func (e *ColumnEncryptor) WithFooterKey() bool {
+	return e.CryptoMetaData.IsSetENCRYPTION_WITH_FOOTER_KEY()
+}
+
+// EncryptModule encrypts a module of the column chunk. The page ordinal is the
+// index of the data page in the column chunk, only used for the data pages.
+func (e *ColumnEncryptor) EncryptModule(moduleType int8, pageOrdinal int, data []byte) ([]byte, error) {
+	if err := checkOrdinal("page", pageOrdinal); err != nil {
+		return nil, err
+	}
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, err
+	}
+	if e.ctr && (moduleType == ModuleDataPage || moduleType == ModuleDictionaryPage) {
+		return ctrEncrypt(e.key, nonce, data)
+	}
+	return gcmEncrypt(e.key, nonce, data, ModuleAAD(e.fileAAD, moduleType, e.rowGroupOrdinal, e.columnOrdinal, int16(pageOrdinal)))
+}
+
+// CheckColumns checks that the encrypted columns of the properties are in the
+// paths in schema of the file, so that no column is left unencrypted by mistake
+func (e *FileEncryptor) CheckColumns(pathsInSchema [][]string) error {
+	paths := make(map[string]bool, len(pathsInSchema))
+	for _, path := range pathsInSchema {
+		paths[ColumnPath(path)] = true
+	}
+	for path := range e.props.Columns {
+		if !paths[path] {
+			return errors.New("encrypted column " + path + " isn't in the schema")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package encryption implements the Parquet Modular Encryption: the modules of
+// the encrypted columns (page headers, pages, column metadata, page indexes and
+// bloom filters) and the footer are encrypted with AES GCM, or AES CTR for the
+// pages with the AES_GCM_CTR_V1 algorithm.
+//
+// Columns are identified by their path in the schema without the root, with
+// the names of the file and '.' as separator, e.g. "name" or "tags.list.element".
+package encryption
+
+import (
+	"errors"
+	"strings"
+)
+
+// Algorithm is the encryption algorithm of a file
+type Algorithm int
+
+const (
+	AesGcmV1 Algorithm = iota
+	AesGcmCtrV1
+)
+
+// KeyRetriever gets the keys of the footer and the columns from their key metadata
+type KeyRetriever interface {
+	GetKey(keyMetadata []byte) ([]byte, error)
+}
+
+// KeyRetrieverFunc is a function implementing KeyRetriever
+type KeyRetrieverFunc func(keyMetadata []byte) ([]byte, error)
+
+func (f KeyRetrieverFunc) GetKey(keyMetadata []byte) ([]byte, error) {
+	return f(keyMetadata)
+}
+
+// ColumnEncryptionProperties are the encryption properties of a column
+type ColumnEncryptionProperties struct {
+	//Key of the column, the column is encrypted with the footer key if it's nil
+	Key []byte
+	//Stored in the file so that readers can retrieve the key
+	KeyMetadata []byte
+}
+
+// FileEncryptionProperties are the encryption properties of a written file
+type FileEncryptionProperties struct {
+	Algorithm Algorithm
+	//Key used to encrypt or sign the footer and the columns without their own key
+	FooterKey         []byte
+	FooterKeyMetadata []byte
+	//Write the footer in plaintext, signed with the footer key, so that the
+	//unencrypted columns can be read by readers without the keys
+	PlaintextFooter bool
+
+	//Prefix of the AADs, to check that files aren't swapped or renamed
+	AADPrefix []byte
+	//Don't store AADPrefix in the file, readers have to supply it
+	DisableAADPrefixStorage bool
+
+	//Encrypted columns. All the columns are encrypted with the footer key if it's empty.
+	Columns map[string]*ColumnEncryptionProperties
+}
+
+// FileDecryptionProperties are the decryption properties of a read file
+type FileDecryptionProperties struct {
+	//Explicit keys, used before KeyRetriever
+	FooterKey  []byte
+	ColumnKeys map[string][]byte
+	//Retrieves the keys from the key metadata of the file
+	KeyRetriever KeyRetriever
+
+	//Supplied AAD prefix, required if it isn't stored in the file
+	AADPrefix []byte
+	//Don't verify the signature of plaintext footers
+	DisableFooterSignatureVerification bool
+	//Allow reading unencrypted files
+	PlaintextFilesAllowed bool
+}
+
+// ColumnPath converts a path in schema to the column paths of the properties
+func ColumnPath(pathInSchema []string) string {
+	return strings.Join(pathInSchema, ".")
+}
+
+func (p *FileEncryptionProperties) validate() error {
+	if _, err := newBlock(p.FooterKey); err != nil {
+		return err
+	}
+	if p.Algorithm != AesGcmV1 && p.Algorithm != AesGcmCtrV1 {
+		return errors.New("unknown encryption algorithm")
+	}
+	if p.DisableAADPrefixStorage && len(p.AADPrefix) == 0 {
+		return errors.New("AAD prefix storage is disabled but there is no AAD prefix")
+	}
+	for path, column := range p.Columns {
+		if column == nil {
+			return errors.New("no encryption properties for column " + path)
+		}
+		if column.Key != nil {
+			if _, err := newBlock(column.Key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
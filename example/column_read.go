@@ -85,7 +85,10 @@ func main() {
 	log.Println("parquet_go_root.scores_key", scores_key, err)
 	log.Println("parquet_go_root.scores_value", scores_value, err)
 
-	pr.SkipRowsByIndex(2, 5) //skip the first five rows
+	if err = pr.SkipRowsByIndex(2, 5); err != nil { //skip the first five rows
+		log.Println("Can't skip", err)
+		return
+	}
 	ids, _, _, _ = pr.ReadColumnByIndex(2, num)
 	log.Println(ids)
 
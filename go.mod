@@ -1,14 +1,33 @@
 module github.com/xitongsys/parquet-go
 
-go 1.16
+go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.0.4
 	github.com/apache/arrow/go/v12 v12.0.1
 	github.com/apache/thrift v0.16.0
 	github.com/aws/aws-sdk-go v1.30.19
+	github.com/cespare/xxhash/v2 v2.1.2
 	github.com/goccy/go-reflect v1.2.0
 	github.com/klauspost/compress v1.16.7
 	github.com/pierrec/lz4/v4 v4.1.15
 	github.com/stretchr/testify v1.8.0
 	github.com/xitongsys/parquet-go-source v0.0.0-20200817004010-026bad9b25d0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/goccy/go-json v0.9.11 // indirect
+	github.com/golang/snappy v0.0.4 // indirect
+	github.com/google/flatbuffers v2.0.8+incompatible // indirect
+	github.com/jmespath/go-jmespath v0.3.0 // indirect
+	github.com/klauspost/asmfmt v1.3.2 // indirect
+	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
+	github.com/minio/asm2plan9s v0.0.0-20200509001527-cdd76441f9d8 // indirect
+	github.com/minio/c2goasm v0.0.0-20190812172519-36a3d3bbc4f3 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/zeebo/xxh3 v1.0.2 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+	golang.org/x/xerrors v0.0.0-20220609144429-65e65417b02f // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
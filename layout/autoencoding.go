@@ -0,0 +1,116 @@
+package layout
+
+import (
+	"math/bits"
+
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/compress"
+	"github.com/xitongsys/parquet-go/encoding"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+//PLAIN is selected unless another encoding is at least this much smaller
+const minEncodingGain = 0.9
+
+//Select the encoding of a column from the non-null values of the first page of a table,
+//using their cardinality, their sortedness and their sizes once encoded and compressed
+//with compressType. It's the smallest of:
+//PLAIN, preferred unless another encoding is 10% smaller; RLE_DICTIONARY if at most half
+//of the values are distinct; DELTA_BINARY_PACKED or DELTA_BYTE_ARRAY, preferred like PLAIN
+//if the values are sorted; BYTE_STREAM_SPLIT for FLOAT and DOUBLE.
+func SelectEncoding(table *Table, pageSize int32, compressType parquet.CompressionCodec) parquet.Encoding {
+	pT := table.Schema.GetType()
+	values := firstPageValues(table, pageSize)
+	if len(values) == 0 || pT == parquet.Type_BOOLEAN {
+		return parquet.Encoding_PLAIN
+	}
+
+	size := func(buf []byte) float64 {
+		if compressed, err := compress.Compress(buf, compressType); err == nil {
+			buf = compressed
+		}
+		return float64(len(buf))
+	}
+	res, resSize := parquet.Encoding_PLAIN, minEncodingGain*size(encoding.WritePlain(values, pT))
+	selectSmaller := func(encoding parquet.Encoding, size float64) {
+		if size < resSize {
+			res, resSize = encoding, size
+		}
+	}
+
+	if dictValues, indexes := dictionaryEncode(values); len(dictValues)*2 <= len(values) {
+		selectSmaller(parquet.Encoding_RLE_DICTIONARY, size(encoding.WritePlain(dictValues, pT))+size(indexes))
+	}
+
+	var deltaEncoding parquet.Encoding = -1
+	var deltaSize float64
+	switch pT {
+	case parquet.Type_INT32:
+		deltaEncoding, deltaSize = parquet.Encoding_DELTA_BINARY_PACKED, size(encoding.WriteDeltaINT32(values))
+	case parquet.Type_INT64:
+		deltaEncoding, deltaSize = parquet.Encoding_DELTA_BINARY_PACKED, size(encoding.WriteDeltaINT64(values))
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		deltaEncoding, deltaSize = parquet.Encoding_DELTA_BYTE_ARRAY, size(encoding.WriteDeltaByteArray(values))
+	case parquet.Type_FLOAT, parquet.Type_DOUBLE:
+		selectSmaller(parquet.Encoding_BYTE_STREAM_SPLIT, size(encoding.WriteByteStreamSplit(values)))
+	}
+	if deltaEncoding >= 0 {
+		funcTable := common.FindFuncTable(table.Schema.Type, table.Schema.ConvertedType, table.Schema.LogicalType)
+		if isSorted(values, funcTable) {
+			deltaSize *= minEncodingGain
+		}
+		selectSmaller(deltaEncoding, deltaSize)
+	}
+	return res
+}
+
+//Non-null values of the first page of a table
+func firstPageValues(table *Table, pageSize int32) []interface{} {
+	if table.Vector != nil {
+		var size int32
+		n := 0
+		for n < table.Vector.Len() && size < pageSize {
+			size += table.Vector.Size(n)
+			n++
+		}
+		return vectorValues(table.Vector.Slice(0, n))
+	}
+
+	pT := table.Schema.GetType()
+	var size int64
+	values := make([]interface{}, 0)
+	for i := 0; i < len(table.Values) && size < int64(pageSize); i++ {
+		if table.DefinitionLevels[i] == table.MaxDefinitionLevel && table.Values[i] != nil {
+			values = append(values, table.Values[i])
+			size += dictValueSize(pT, table.Values[i])
+		}
+	}
+	return values
+}
+
+//Distinct values and encoded indexes of the values, as the dict data pages have them
+func dictionaryEncode(values []interface{}) ([]interface{}, []byte) {
+	dictMap := make(map[interface{}]int32)
+	dictValues := make([]interface{}, 0)
+	indexes := make([]int32, len(values))
+	for i, value := range values {
+		index, ok := dictMap[value]
+		if !ok {
+			index = int32(len(dictValues))
+			dictMap[value] = index
+			dictValues = append(dictValues, value)
+		}
+		indexes[i] = index
+	}
+	bitWidth := int32(bits.Len32(uint32(len(dictValues) - 1)))
+	return dictValues, append([]byte{byte(bitWidth)}, encoding.WriteRLEInt32(indexes, bitWidth)...)
+}
+
+func isSorted(values []interface{}, funcTable common.FuncTable) bool {
+	for i := 1; i < len(values); i++ {
+		if funcTable.LessThan(values[i], values[i-1]) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,8 @@
 package layout
 
 import (
+	"fmt"
+
 	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/xitongsys/parquet-go/common"
 	"github.com/xitongsys/parquet-go/encoding"
@@ -47,10 +49,7 @@ func PagesToChunk(pages []*Page) *Chunk {
 	chunk.ChunkHeader = parquet.NewColumnChunk()
 	metaData := parquet.NewColumnMetaData()
 	metaData.Type = *pages[0].Schema.Type
-	metaData.Encodings = append(metaData.Encodings, parquet.Encoding_RLE)
-	metaData.Encodings = append(metaData.Encodings, parquet.Encoding_BIT_PACKED)
-	metaData.Encodings = append(metaData.Encodings, parquet.Encoding_PLAIN)
-	//metaData.Encodings = append(metaData.Encodings, parquet.Encoding_DELTA_BINARY_PACKED)
+	setEncodings(metaData, pages)
 	metaData.Codec = pages[0].CompressType
 	metaData.NumValues = numValues
 	metaData.TotalCompressedSize = totalCompressedSize
@@ -114,11 +113,7 @@ func PagesToDictChunk(pages []*Page) *Chunk {
 	chunk.ChunkHeader = parquet.NewColumnChunk()
 	metaData := parquet.NewColumnMetaData()
 	metaData.Type = *pages[1].Schema.Type
-	metaData.Encodings = append(metaData.Encodings, parquet.Encoding_RLE)
-	metaData.Encodings = append(metaData.Encodings, parquet.Encoding_BIT_PACKED)
-	metaData.Encodings = append(metaData.Encodings, parquet.Encoding_PLAIN)
-	metaData.Encodings = append(metaData.Encodings, parquet.Encoding_PLAIN_DICTIONARY)
-	metaData.Encodings = append(metaData.Encodings, parquet.Encoding_RLE_DICTIONARY)
+	setEncodings(metaData, pages)
 
 	metaData.Codec = pages[1].CompressType
 	metaData.NumValues = numValues
@@ -148,20 +143,82 @@ func PagesToDictChunk(pages []*Page) *Chunk {
 	return chunk
 }
 
-//Decode a dict chunk
-func DecodeDictChunk(chunk *Chunk) {
+//Set the Encodings of a chunk and their EncodingStats from the headers of its pages.
+//The levels of the data pages are encoded with RLE.
+func setEncodings(metaData *parquet.ColumnMetaData, pages []*Page) {
+	metaData.Encodings = make([]parquet.Encoding, 0)
+	metaData.EncodingStats = make([]*parquet.PageEncodingStats, 0)
+	addEncoding := func(encoding parquet.Encoding) {
+		for _, e := range metaData.Encodings {
+			if e == encoding {
+				return
+			}
+		}
+		metaData.Encodings = append(metaData.Encodings, encoding)
+	}
+
+	for _, page := range pages {
+		var encoding parquet.Encoding
+		switch header := page.Header; header.GetType() {
+		case parquet.PageType_DICTIONARY_PAGE:
+			encoding = header.DictionaryPageHeader.GetEncoding()
+		case parquet.PageType_DATA_PAGE:
+			encoding = header.DataPageHeader.GetEncoding()
+			addEncoding(parquet.Encoding_RLE)
+		case parquet.PageType_DATA_PAGE_V2:
+			encoding = header.DataPageHeaderV2.GetEncoding()
+			addEncoding(parquet.Encoding_RLE)
+		default:
+			continue
+		}
+		addEncoding(encoding)
+
+		found := false
+		for _, stats := range metaData.EncodingStats {
+			if stats.PageType == page.Header.GetType() && stats.Encoding == encoding {
+				stats.Count++
+				found = true
+				break
+			}
+		}
+		if !found {
+			metaData.EncodingStats = append(metaData.EncodingStats, &parquet.PageEncodingStats{
+				PageType: page.Header.GetType(),
+				Encoding: encoding,
+				Count:    1,
+			})
+		}
+	}
+}
+
+//Decode a dict chunk, it fails if an index is out of the dictionary
+func DecodeDictChunk(chunk *Chunk) error {
 	dictPage := chunk.Pages[0]
 	numPages := len(chunk.Pages)
 	for i := 1; i < numPages; i++ {
 		numValues := len(chunk.Pages[i].DataTable.Values)
 		for j := 0; j < numValues; j++ {
 			if chunk.Pages[i].DataTable.Values[j] != nil {
-				index := chunk.Pages[i].DataTable.Values[j].(int64)
+				index, ok := chunk.Pages[i].DataTable.Values[j].(int64)
+				if !ok || index < 0 || index >= int64(len(dictPage.DataTable.Values)) {
+					return fmt.Errorf("dictionary index %v out of range %v", chunk.Pages[i].DataTable.Values[j], len(dictPage.DataTable.Values))
+				}
 				chunk.Pages[i].DataTable.Values[j] = dictPage.DataTable.Values[index]
 			}
 		}
 	}
 	chunk.Pages = chunk.Pages[1:] // delete the head dict page
+	return nil
+}
+
+// ChunkOffset is the offset of the first page of a column chunk. The dictionary page offset
+// is ignored if it isn't before the data page offset, as some writers set it to 0.
+func ChunkOffset(metaData *parquet.ColumnMetaData) int64 {
+	if metaData.DictionaryPageOffset != nil && *metaData.DictionaryPageOffset > 0 &&
+		*metaData.DictionaryPageOffset < metaData.DataPageOffset {
+		return *metaData.DictionaryPageOffset
+	}
+	return metaData.DataPageOffset
 }
 
 //Read one chunk from parquet file (Deprecated)
@@ -181,7 +238,9 @@ func ReadChunk(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.Sc
 	}
 
 	if len(chunk.Pages) > 0 && chunk.Pages[0].Header.GetType() == parquet.PageType_DICTIONARY_PAGE {
-		DecodeDictChunk(chunk)
+		if err := DecodeDictChunk(chunk); err != nil {
+			return nil, err
+		}
 	}
 	return chunk, nil
 }
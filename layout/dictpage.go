@@ -6,7 +6,6 @@ import (
 
 	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/xitongsys/parquet-go/common"
-	"github.com/xitongsys/parquet-go/compress"
 	"github.com/xitongsys/parquet-go/encoding"
 	"github.com/xitongsys/parquet-go/parquet"
 )
@@ -15,6 +14,19 @@ type DictRecType struct {
 	DictMap   map[interface{}]int32
 	DictSlice []interface{}
 	Type      parquet.Type
+	//Size of the dictionary page values in bytes
+	Size int64
+
+	//Max size in bytes and max number of entries of the dictionary, 0 means no limit.
+	//They are checked before each page, the pages after the dictionary exceeds them
+	//have the fallback encoding of the column.
+	MaxSize    int64
+	MaxEntries int64
+	//The dictionary exceeded its limits
+	Fallback bool
+
+	//Compression level of the dict page, 0 is the default level of the codec
+	CompressionLevel int
 }
 
 func NewDictRec(pT parquet.Type) *DictRecType {
@@ -24,7 +36,7 @@ func NewDictRec(pT parquet.Type) *DictRecType {
 	return res
 }
 
-func DictRecToDictPage(dictRec *DictRecType, pageSize int32, compressType parquet.CompressionCodec) (*Page, int64) {
+func DictRecToDictPage(dictRec *DictRecType, pageSize int32, compressType parquet.CompressionCodec) (*Page, int64, error) {
 	var totSize int64 = 0
 
 	page := NewDataPage()
@@ -39,16 +51,49 @@ func DictRecToDictPage(dictRec *DictRecType, pageSize int32, compressType parque
 		Type: &dataType,
 	}
 	page.CompressType = compressType
+	page.Info.CompressionLevel = dictRec.CompressionLevel
 
-	page.DictPageCompress(compressType, dictRec.Type)
+	if _, err := page.DictPageCompress(compressType, dictRec.Type); err != nil {
+		return nil, 0, err
+	}
 	totSize += int64(len(page.RawData))
-	return page, totSize
+	return page, totSize, nil
+}
+
+//Check if the dictionary exceeds its limits, the next pages can't be dict pages if it does
+func (dictRec *DictRecType) checkLimits() bool {
+	if (dictRec.MaxSize > 0 && dictRec.Size > dictRec.MaxSize) ||
+		(dictRec.MaxEntries > 0 && int64(len(dictRec.DictSlice)) > dictRec.MaxEntries) {
+		dictRec.Fallback = true
+	}
+	return dictRec.Fallback
+}
+
+//Size of a value in the PLAIN encoded dictionary page
+func dictValueSize(pT parquet.Type, val interface{}) int64 {
+	switch pT {
+	case parquet.Type_BOOLEAN:
+		return 1
+	case parquet.Type_INT32, parquet.Type_FLOAT:
+		return 4
+	case parquet.Type_INT64, parquet.Type_DOUBLE:
+		return 8
+	case parquet.Type_BYTE_ARRAY:
+		s, _ := val.(string)
+		return 4 + int64(len(s))
+	default:
+		s, _ := val.(string)
+		return int64(len(s))
+	}
 }
 
 //Compress the dict page to parquet file
-func (page *Page) DictPageCompress(compressType parquet.CompressionCodec, pT parquet.Type) []byte {
+func (page *Page) DictPageCompress(compressType parquet.CompressionCodec, pT parquet.Type) ([]byte, error) {
 	dataBuf := encoding.WritePlain(page.DataTable.Values, pT)
-	var dataEncodeBuf []byte = compress.Compress(dataBuf, compressType)
+	dataEncodeBuf, err := page.compress(dataBuf, compressType)
+	if err != nil {
+		return nil, err
+	}
 
 	//pageHeader/////////////////////////////////////
 	page.Header = parquet.NewPageHeader()
@@ -67,11 +112,23 @@ func (page *Page) DictPageCompress(compressType parquet.CompressionCodec, pT par
 	res = append(res, pageHeaderBuf...)
 	res = append(res, dataEncodeBuf...)
 	page.RawData = res
-	return res
+	return res, nil
 }
 
 //Convert a table to dict data pages
-func TableToDictDataPages(dictRec *DictRecType, table *Table, pageSize int32, bitWidth int32, compressType parquet.CompressionCodec) ([]*Page, int64) {
+func TableToDictDataPages(dictRec *DictRecType, table *Table, pageSize int32, bitWidth int32, compressType parquet.CompressionCodec) ([]*Page, int64, error) {
+	return tableToDictDataPages(dictRec, table, pageSize, bitWidth, compressType, false)
+}
+
+//Convert a table to dict DATA_PAGE_V2 pages, which start on row boundaries
+func TableToDictDataPagesV2(dictRec *DictRecType, table *Table, pageSize int32, bitWidth int32, compressType parquet.CompressionCodec) ([]*Page, int64, error) {
+	return tableToDictDataPages(dictRec, table, pageSize, bitWidth, compressType, true)
+}
+
+func tableToDictDataPages(dictRec *DictRecType, table *Table, pageSize int32, bitWidth int32, compressType parquet.CompressionCodec, v2 bool) ([]*Page, int64, error) {
+	//the dictionary is keyed by the boxed values
+	table.ToValues()
+
 	var totSize int64 = 0
 	totalLn := len(table.Values)
 	res := make([]*Page, 0)
@@ -80,6 +137,14 @@ func TableToDictDataPages(dictRec *DictRecType, table *Table, pageSize int32, bi
 	pT, cT, logT, omitStats := table.Schema.Type, table.Schema.ConvertedType, table.Schema.LogicalType, table.Info.OmitStats
 
 	for i < totalLn {
+		if dictRec.checkLimits() {
+			pages, size, err := fallbackDataPages(table, i, pageSize, compressType, v2)
+			if err != nil {
+				return nil, 0, err
+			}
+			return append(res, pages...), totSize + size, nil
+		}
+
 		j := i
 		var size int32 = 0
 		var numValues int32 = 0
@@ -91,7 +156,7 @@ func TableToDictDataPages(dictRec *DictRecType, table *Table, pageSize int32, bi
 
 		funcTable := common.FindFuncTable(pT, cT, logT)
 
-		for j < totalLn && size < pageSize {
+		for j < totalLn && (size < pageSize || (v2 && table.RepetitionLevels[j] != 0)) {
 			if table.DefinitionLevels[j] == table.MaxDefinitionLevel {
 				numValues++
 				var elSize int32
@@ -105,12 +170,13 @@ func TableToDictDataPages(dictRec *DictRecType, table *Table, pageSize int32, bi
 					values = append(values, idx)
 				} else {
 					dictRec.DictSlice = append(dictRec.DictSlice, table.Values[j])
+					dictRec.Size += dictValueSize(dictRec.Type, table.Values[j])
 					idx := int32(len(dictRec.DictSlice) - 1)
 					dictRec.DictMap[table.Values[j]] = idx
 					values = append(values, idx)
 				}
 			}
-			if table.Values[i] == nil {
+			if table.Values[j] == nil {
 				nullCount++
 			}
 			j++
@@ -142,17 +208,49 @@ func TableToDictDataPages(dictRec *DictRecType, table *Table, pageSize int32, bi
 		page.Path = table.Path
 		page.Info = table.Info
 
-		page.DictDataPageCompress(compressType, bitWidth, values)
+		//the indexes are encoded with the bit width of the dictionary, at most bitWidth
+		pageBitWidth := int32(bits.Len32(uint32(len(dictRec.DictSlice) - 1)))
+		if pageBitWidth > bitWidth {
+			pageBitWidth = bitWidth
+		}
+		var err error
+		if v2 {
+			_, err = page.DictDataPageV2Compress(compressType, pageBitWidth, values)
+		} else {
+			_, err = page.DictDataPageCompress(compressType, pageBitWidth, values)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
 
 		totSize += int64(len(page.RawData))
 		res = append(res, page)
 		i = j
 	}
-	return res, totSize
+	return res, totSize, nil
+}
+
+//Convert the values of a table from the index start to data pages with the fallback
+//encoding of the column
+func fallbackDataPages(table *Table, start int, pageSize int32, compressType parquet.CompressionCodec, v2 bool) ([]*Page, int64, error) {
+	info := *table.Info
+	info.Encoding = info.FallbackEncoding
+	rest := &Table{
+		Schema:             table.Schema,
+		Path:               table.Path,
+		RepetitionType:     table.RepetitionType,
+		MaxDefinitionLevel: table.MaxDefinitionLevel,
+		MaxRepetitionLevel: table.MaxRepetitionLevel,
+		Values:             table.Values[start:],
+		DefinitionLevels:   table.DefinitionLevels[start:],
+		RepetitionLevels:   table.RepetitionLevels[start:],
+		Info:               &info,
+	}
+	return tableToDataPages(rest, pageSize, compressType, v2)
 }
 
 //Compress the data page to parquet file
-func (page *Page) DictDataPageCompress(compressType parquet.CompressionCodec, bitWidth int32, values []int32) []byte {
+func (page *Page) DictDataPageCompress(compressType parquet.CompressionCodec, bitWidth int32, values []int32) ([]byte, error) {
 	//values////////////////////////////////////////////
 	valuesRawBuf := []byte{byte(bitWidth)}
 	valuesRawBuf = append(valuesRawBuf, encoding.WriteRLEInt32(values, bitWidth)...)
@@ -179,7 +277,10 @@ func (page *Page) DictDataPageCompress(compressType parquet.CompressionCodec, bi
 	dataBuf = append(dataBuf, definitionLevelBuf...)
 	dataBuf = append(dataBuf, valuesRawBuf...)
 
-	var dataEncodeBuf []byte = compress.Compress(dataBuf, compressType)
+	dataEncodeBuf, err := page.compress(dataBuf, compressType)
+	if err != nil {
+		return nil, err
+	}
 
 	//pageHeader/////////////////////////////////////
 	page.Header = parquet.NewPageHeader()
@@ -192,25 +293,7 @@ func (page *Page) DictDataPageCompress(compressType parquet.CompressionCodec, bi
 	page.Header.DataPageHeader.RepetitionLevelEncoding = parquet.Encoding_RLE
 	page.Header.DataPageHeader.Encoding = parquet.Encoding_PLAIN_DICTIONARY
 
-	page.Header.DataPageHeader.Statistics = parquet.NewStatistics()
-	if page.MaxVal != nil {
-		tmpBuf := encoding.WritePlain([]interface{}{page.MaxVal}, *page.Schema.Type)
-		if *page.Schema.Type == parquet.Type_BYTE_ARRAY {
-			tmpBuf = tmpBuf[4:]
-		}
-		page.Header.DataPageHeader.Statistics.Max = tmpBuf
-		page.Header.DataPageHeader.Statistics.MaxValue = tmpBuf
-	}
-	if page.MinVal != nil {
-		tmpBuf := encoding.WritePlain([]interface{}{page.MinVal}, *page.Schema.Type)
-		if *page.Schema.Type == parquet.Type_BYTE_ARRAY {
-			tmpBuf = tmpBuf[4:]
-		}
-		page.Header.DataPageHeader.Statistics.Min = tmpBuf
-		page.Header.DataPageHeader.Statistics.MinValue = tmpBuf
-	}
-
-	page.Header.DataPageHeader.Statistics.NullCount = page.NullCount
+	page.Header.DataPageHeader.Statistics = page.statistics()
 
 	ts := thrift.NewTSerializer()
 	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
@@ -221,5 +304,16 @@ func (page *Page) DictDataPageCompress(compressType parquet.CompressionCodec, bi
 	res = append(res, dataEncodeBuf...)
 	page.RawData = res
 
-	return res
+	return res, nil
+}
+
+//Compress the dict data page v2 to parquet file
+func (page *Page) DictDataPageV2Compress(compressType parquet.CompressionCodec, bitWidth int32, values []int32) ([]byte, error) {
+	valuesRawBuf := []byte{byte(bitWidth)}
+	valuesRawBuf = append(valuesRawBuf, encoding.WriteRLEInt32(values, bitWidth)...)
+
+	if err := page.dataPageV2(compressType, valuesRawBuf, int32(len(values)), parquet.Encoding_RLE_DICTIONARY); err != nil {
+		return nil, err
+	}
+	return page.RawData, nil
 }
@@ -0,0 +1,70 @@
+package layout
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// DefaultMaxFooterSize is the default max size of a footer in bytes
+const DefaultMaxFooterSize = 256 << 20
+
+// ReadFooterSize reads the size of the footer and the magic number at the end of a file,
+// which is PAR1, or PARE if the footer is encrypted. maxSize is the max size of the footer, 0 for no limit.
+func ReadFooterSize(pFile source.ParquetFile, maxSize int64) (uint32, string, error) {
+	pos, err := pFile.Seek(-8, io.SeekEnd)
+	if err != nil {
+		return 0, "", err
+	}
+	buf := make([]byte, 8)
+	if _, err = io.ReadFull(pFile, buf); err != nil {
+		return 0, "", err
+	}
+	magic := string(buf[4:])
+	if magic != "PAR1" && magic != "PARE" {
+		return 0, "", errors.New("invalid parquet file")
+	}
+	size := binary.LittleEndian.Uint32(buf)
+	//the file starts with the 4 bytes of the magic number
+	if int64(size) > pos-4 {
+		return 0, "", fmt.Errorf("invalid footer size %v of a file of %v bytes", size, pos+8)
+	}
+	if maxSize > 0 && int64(size) > maxSize {
+		return 0, "", fmt.Errorf("footer size %v is larger than the limit %v", size, maxSize)
+	}
+	return size, magic, nil
+}
+
+// ReadFooter reads the footer of a file and returns it with its offset in the file.
+// encrypted is true if the footer is encrypted, it then isn't a FileMetaData.
+func ReadFooter(pFile source.ParquetFile, maxSize int64) (buf []byte, encrypted bool, offset int64, err error) {
+	size, magic, err := ReadFooterSize(pFile, maxSize)
+	if err != nil {
+		return nil, false, 0, err
+	}
+	if offset, err = pFile.Seek(-(int64)(8+size), io.SeekEnd); err != nil {
+		return nil, false, 0, err
+	}
+	buf = make([]byte, size)
+	if _, err = io.ReadFull(pFile, buf); err != nil {
+		return nil, false, 0, err
+	}
+	return buf, magic == "PARE", offset, nil
+}
+
+// DecodeFooter decodes a plaintext footer, the signature of the footer of an encrypted file follows it in buf
+func DecodeFooter(buf []byte) (*parquet.FileMetaData, error) {
+	footer := parquet.NewFileMetaData()
+	td := thrift.NewTDeserializer()
+	td.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(td.Transport)
+	if err := td.Read(context.TODO(), footer, buf); err != nil {
+		return nil, err
+	}
+	return footer, nil
+}
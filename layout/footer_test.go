@@ -0,0 +1,61 @@
+package layout
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/source/mem"
+)
+
+func TestReadFooter(t *testing.T) {
+	footer := parquet.NewFileMetaData()
+	footer.Version = 1
+	footer.NumRows = 10
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	footerBuf, err := ts.Write(context.TODO(), footer)
+	assert.NoError(t, err)
+
+	file := func(footerBuf []byte, magic string) *mem.File {
+		buf := append([]byte("PAR1"), footerBuf...)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(footerBuf)))
+		buf = append(buf, magic...)
+		fs := mem.NewFS()
+		assert.NoError(t, fs.WriteFile("file", buf))
+		f, err := fs.Open("file")
+		assert.NoError(t, err)
+		return f
+	}
+
+	buf, encrypted, offset, err := ReadFooter(file(footerBuf, "PAR1"), 0)
+	assert.NoError(t, err)
+	assert.False(t, encrypted)
+	assert.Equal(t, int64(4), offset)
+	res, err := DecodeFooter(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), res.Version)
+	assert.Equal(t, int64(10), res.NumRows)
+
+	_, encrypted, _, err = ReadFooter(file(footerBuf, "PARE"), 0)
+	assert.NoError(t, err)
+	assert.True(t, encrypted)
+
+	_, _, _, err = ReadFooter(file(footerBuf, "PAR2"), 0)
+	assert.EqualError(t, err, "invalid parquet file")
+	_, _, _, err = ReadFooter(file(footerBuf, "PAR1"), int64(len(footerBuf)-1))
+	assert.Error(t, err)
+
+	//the size of the footer is larger than the file
+	f := file(footerBuf, "PAR1")
+	_, err = f.Seek(-8, io.SeekEnd)
+	assert.NoError(t, err)
+	_, err = f.Write([]byte{0xff, 0xff, 0, 0})
+	assert.NoError(t, err)
+	_, _, err = ReadFooterSize(f, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+//go:build gofuzz
+// +build gofuzz
+
+package layout
+
+import (
+	"bytes"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+)
+
+const fuzzing = true
+
+type fuzzRecord struct {
+	Bool      *bool    `parquet:"name=bool, type=BOOLEAN"`
+	Int32     int32    `parquet:"name=int32, type=INT32"`
+	Int64     []int64  `parquet:"name=int64, type=INT64, repetitiontype=REPEATED"`
+	Int96     string   `parquet:"name=int96, type=INT96"`
+	Float     *float32 `parquet:"name=float, type=FLOAT"`
+	Double    float64  `parquet:"name=double, type=DOUBLE"`
+	ByteArray *string  `parquet:"name=byte_array, type=BYTE_ARRAY, convertedtype=UTF8"`
+	FixedLen  string   `parquet:"name=fixed_len, type=FIXED_LEN_BYTE_ARRAY, length=12"`
+}
+
+var fuzzCodecs = []parquet.CompressionCodec{
+	parquet.CompressionCodec_UNCOMPRESSED,
+	parquet.CompressionCodec_SNAPPY,
+	parquet.CompressionCodec_GZIP,
+	parquet.CompressionCodec_LZ4,
+	parquet.CompressionCodec_ZSTD,
+	parquet.CompressionCodec_LZ4_RAW,
+	parquet.CompressionCodec_BROTLI,
+	parquet.CompressionCodec_LZO,
+}
+
+// FuzzPageHeader is a go-fuzz target of the reading of the pages, from their header to their values:
+//
+//	go-fuzz-build -func FuzzPageHeader github.com/xitongsys/parquet-go/layout
+//	go-fuzz -bin layout-fuzz.zip -workdir fuzz/layout
+//
+// The first byte of data selects the column and the codec of the pages, which are the remaining bytes.
+// A dictionary page is used to decode the next page.
+func FuzzPageHeader(data []byte) int {
+	if len(data) < 1 {
+		return -1
+	}
+	schemaHandler, err := schema.NewSchemaHandlerFromStruct(new(fuzzRecord))
+	if err != nil {
+		panic(err)
+	}
+	columns := schemaHandler.ValueColumns
+	pathStr := columns[int(data[0])%len(columns)]
+	colMetaData := &parquet.ColumnMetaData{
+		Type:         *schemaHandler.SchemaElements[schemaHandler.MapIndex[pathStr]].Type,
+		PathInSchema: common.StrToPath(pathStr)[1:],
+		Codec:        fuzzCodecs[int(data[0])/len(columns)%len(fuzzCodecs)],
+	}
+	limits := PageLimits{MaxPageSize: 1 << 20, MaxPageValues: 1 << 16}
+	newReader := func() *thrift.TBufferedTransport {
+		return thrift.NewTBufferedTransport(thrift.NewStreamTransportR(bytes.NewReader(data[1:])), len(data))
+	}
+
+	res := 0
+	for _, vector := range []bool{false, true} {
+		thriftReader := newReader()
+		dictPage, _, _, err := readPage(thriftReader, schemaHandler, colMetaData, vector, limits)
+		if err != nil {
+			continue
+		}
+		res = 1
+		if dictPage.Header.GetType() != parquet.PageType_DICTIONARY_PAGE {
+			continue
+		}
+		if page, _, _, err := readPage(thriftReader, schemaHandler, colMetaData, vector, limits); err == nil {
+			page.Decode(dictPage)
+		}
+	}
+
+	//the values read for skipping
+	page, err := limits.ReadPageRawData(newReader(), schemaHandler, colMetaData)
+	if err != nil {
+		return res
+	}
+	if _, _, err = page.GetRLDLFromRawData(schemaHandler); err != nil {
+		return res
+	}
+	page.GetValueFromRawData(schemaHandler)
+	return 1
+}
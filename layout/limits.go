@@ -0,0 +1,109 @@
+package layout
+
+import (
+	"fmt"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/xitongsys/parquet-go/compress"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+)
+
+//PageLimits bounds the pages read from files which may be malformed or malicious,
+//so that their headers can't make the reader allocate too much memory.
+//0 means no limit.
+type PageLimits struct {
+	//Max compressed and uncompressed size of a page
+	MaxPageSize int64
+	//Max number of values of a page
+	MaxPageValues int64
+}
+
+//Read page from parquet file, see ReadPage
+func (limits PageLimits) ReadPage(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.SchemaHandler, colMetaData *parquet.ColumnMetaData) (*Page, int64, int64, error) {
+	return readPage(thriftReader, schemaHandler, colMetaData, false, limits)
+}
+
+//Read page from parquet file to vectors, see ReadPageVector
+func (limits PageLimits) ReadPageVector(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.SchemaHandler, colMetaData *parquet.ColumnMetaData) (*Page, int64, int64, error) {
+	return readPage(thriftReader, schemaHandler, colMetaData, true, limits)
+}
+
+//Read page RawData, see ReadPageRawData
+func (limits PageLimits) ReadPageRawData(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.SchemaHandler, colMetaData *parquet.ColumnMetaData) (*Page, error) {
+	return readPageRawData(thriftReader, schemaHandler, colMetaData, limits)
+}
+
+//Read the header and data of a page without uncompressing it, it's decoded by DecodePageVector.
+//Reading and decoding the pages separately allows to decode them concurrently.
+func (limits PageLimits) ReadCompressedPage(thriftReader *thrift.TBufferedTransport) (_ *CompressedPage, err error) {
+	defer recoverError(&err)
+	return readCompressedPage(thriftReader, limits)
+}
+
+//Uncompress and decode a page read by ReadCompressedPage, as ReadPageVector
+func (limits PageLimits) DecodePageVector(page *CompressedPage, schemaHandler *schema.SchemaHandler, colMetaData *parquet.ColumnMetaData) (_ *Page, _ int64, _ int64, err error) {
+	defer recoverError(&err)
+	return decodePage(page, schemaHandler, colMetaData, true, limits)
+}
+
+//Check the sizes of a page header before its data is read
+func (limits PageLimits) checkHeader(header *parquet.PageHeader) error {
+	compressedSize, uncompressedSize := int64(header.GetCompressedPageSize()), int64(header.GetUncompressedPageSize())
+	if compressedSize < 0 || uncompressedSize < 0 {
+		return fmt.Errorf("invalid page size %v, uncompressed %v", compressedSize, uncompressedSize)
+	}
+	if limits.MaxPageSize > 0 && (compressedSize > limits.MaxPageSize || uncompressedSize > limits.MaxPageSize) {
+		return fmt.Errorf("page size %v, uncompressed %v, is larger than the limit %v", compressedSize, uncompressedSize, limits.MaxPageSize)
+	}
+
+	var numValues int64
+	switch header.GetType() {
+	case parquet.PageType_DATA_PAGE:
+		if header.DataPageHeader == nil {
+			return fmt.Errorf("data page without header")
+		}
+		numValues = int64(header.DataPageHeader.GetNumValues())
+	case parquet.PageType_DATA_PAGE_V2:
+		v2 := header.DataPageHeaderV2
+		if v2 == nil {
+			return fmt.Errorf("data page v2 without header")
+		}
+		rll, dll := int64(v2.GetRepetitionLevelsByteLength()), int64(v2.GetDefinitionLevelsByteLength())
+		if rll < 0 || dll < 0 || rll+dll > compressedSize {
+			return fmt.Errorf("invalid levels size %v and %v of a page of size %v", rll, dll, compressedSize)
+		}
+		numValues = int64(v2.GetNumValues())
+	case parquet.PageType_DICTIONARY_PAGE:
+		if header.DictionaryPageHeader == nil {
+			return fmt.Errorf("dictionary page without header")
+		}
+		numValues = int64(header.DictionaryPageHeader.GetNumValues())
+	}
+	if numValues < 0 {
+		return fmt.Errorf("invalid number of values %v", numValues)
+	}
+	if limits.MaxPageValues > 0 && numValues > limits.MaxPageValues {
+		return fmt.Errorf("page has %v values, more than the limit %v", numValues, limits.MaxPageValues)
+	}
+	return nil
+}
+
+//Uncompress the data of a page, whose size is bounded by maxSize if it isn't 0
+func uncompress(buf []byte, codec parquet.CompressionCodec, maxSize int64) ([]byte, error) {
+	if maxSize > 0 {
+		return compress.UncompressWithLimit(buf, codec, maxSize)
+	}
+	return compress.Uncompress(buf, codec)
+}
+
+//Convert a panic reading malformed data to an error. The panics aren't recovered
+//by the fuzz targets, so that they are found.
+func recoverError(err *error) {
+	if fuzzing {
+		return
+	}
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("malformed page: %v", r)
+	}
+}
@@ -39,6 +39,9 @@ type Page struct {
 	Info *common.Tag
 
 	PageSize int32
+
+	//Max uncompressed size of the RawData read from a file, 0 means no limit
+	maxSize int64
 }
 
 //Create a new page
@@ -68,7 +71,20 @@ func NewDataPage() *Page {
 }
 
 //Convert a table to data pages
-func TableToDataPages(table *Table, pageSize int32, compressType parquet.CompressionCodec) ([]*Page, int64) {
+func TableToDataPages(table *Table, pageSize int32, compressType parquet.CompressionCodec) ([]*Page, int64, error) {
+	return tableToDataPages(table, pageSize, compressType, false)
+}
+
+//Convert a table to DATA_PAGE_V2 pages, which start on row boundaries
+func TableToDataPagesV2(table *Table, pageSize int32, compressType parquet.CompressionCodec) ([]*Page, int64, error) {
+	return tableToDataPages(table, pageSize, compressType, true)
+}
+
+func tableToDataPages(table *Table, pageSize int32, compressType parquet.CompressionCodec, v2 bool) ([]*Page, int64, error) {
+	if table.Vector != nil {
+		return vectorTableToDataPages(table, pageSize, compressType, v2)
+	}
+
 	var totSize int64 = 0
 	totalLn := len(table.Values)
 	res := make([]*Page, 0)
@@ -86,7 +102,7 @@ func TableToDataPages(table *Table, pageSize int32, compressType parquet.Compres
 
 		funcTable := common.FindFuncTable(pT, cT, logT)
 
-		for j < totalLn && size < pageSize {
+		for j < totalLn && (size < pageSize || (v2 && table.RepetitionLevels[j] != 0)) {
 			if table.DefinitionLevels[j] == table.MaxDefinitionLevel {
 				numValues++
 				var elSize int32
@@ -126,41 +142,132 @@ func TableToDataPages(table *Table, pageSize int32, compressType parquet.Compres
 		page.Path = table.Path
 		page.Info = table.Info
 
-		page.DataPageCompress(compressType)
+		var err error
+		if v2 {
+			_, err = page.DataPageV2Compress(compressType)
+		} else {
+			_, err = page.DataPageCompress(compressType)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
 
 		totSize += int64(len(page.RawData))
 		res = append(res, page)
 		i = j
 	}
-	return res, totSize
+	return res, totSize, nil
 }
 
-//Decode dict page
-func (page *Page) Decode(dictPage *Page) {
+//Convert a table with a Vector to data pages, the pages have slices of the vector
+func vectorTableToDataPages(table *Table, pageSize int32, compressType parquet.CompressionCodec, v2 bool) ([]*Page, int64, error) {
+	var totSize int64 = 0
+	totalLn := len(table.DefinitionLevels)
+	res := make([]*Page, 0)
+	i, vi := 0, 0
+	pT, cT, logT, omitStats := table.Schema.Type, table.Schema.ConvertedType, table.Schema.LogicalType, table.Info.OmitStats
+	funcTable := common.FindFuncTable(pT, cT, logT)
+
+	for i < totalLn {
+		j, vj := i, vi
+		var size int32 = 0
+		var nullCount = int64(0)
+
+		for j < totalLn && (size < pageSize || (v2 && table.RepetitionLevels[j] != 0)) {
+			if table.DefinitionLevels[j] == table.MaxDefinitionLevel {
+				size += table.Vector.Size(vj)
+				vj++
+			} else {
+				nullCount++
+			}
+			j++
+		}
+
+		page := NewDataPage()
+		page.PageSize = pageSize
+		page.Header.DataPageHeader.NumValues = int32(vj - vi)
+		page.Header.Type = parquet.PageType_DATA_PAGE
+
+		page.DataTable = new(Table)
+		page.DataTable.RepetitionType = table.RepetitionType
+		page.DataTable.Path = table.Path
+		page.DataTable.MaxDefinitionLevel = table.MaxDefinitionLevel
+		page.DataTable.MaxRepetitionLevel = table.MaxRepetitionLevel
+		page.DataTable.Vector = table.Vector.Slice(vi, vj)
+		page.DataTable.DefinitionLevels = table.DefinitionLevels[i:j]
+		page.DataTable.RepetitionLevels = table.RepetitionLevels[i:j]
+		if !omitStats {
+			page.MinVal, page.MaxVal = vectorMinMax(page.DataTable.Vector, funcTable)
+			page.NullCount = &nullCount
+		}
+		page.Schema = table.Schema
+		page.CompressType = compressType
+		page.Path = table.Path
+		page.Info = table.Info
+
+		var err error
+		if v2 {
+			_, err = page.DataPageV2Compress(compressType)
+		} else {
+			_, err = page.DataPageCompress(compressType)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		totSize += int64(len(page.RawData))
+		res = append(res, page)
+		i, vi = j, vj
+	}
+	return res, totSize, nil
+}
+
+//Decode dict page, it fails if an index is out of the dictionary
+func (page *Page) Decode(dictPage *Page) error {
 	if dictPage == nil || page == nil ||
 		(page.Header.DataPageHeader == nil && page.Header.DataPageHeaderV2 == nil) {
-		return
+		return nil
 	}
 
 	if page.Header.DataPageHeader != nil &&
 		(page.Header.DataPageHeader.Encoding != parquet.Encoding_RLE_DICTIONARY &&
 			page.Header.DataPageHeader.Encoding != parquet.Encoding_PLAIN_DICTIONARY) {
-		return
+		return nil
 	}
 
 	if page.Header.DataPageHeaderV2 != nil &&
 		(page.Header.DataPageHeaderV2.Encoding != parquet.Encoding_RLE_DICTIONARY &&
 			page.Header.DataPageHeaderV2.Encoding != parquet.Encoding_PLAIN_DICTIONARY) {
-		return
+		return nil
 	}
 
+	if indexes, ok := page.DataTable.Vector.(*Int64Vector); ok && dictPage.DataTable.Vector != nil {
+		numDictValues := int64(dictPage.DataTable.Vector.Len())
+		for _, index := range indexes.Values {
+			if index < 0 || index >= numDictValues {
+				return fmt.Errorf("dictionary index %v out of range %v", index, numDictValues)
+			}
+		}
+		page.DataTable.Vector = dictPage.DataTable.Vector.Take(indexes.Values)
+		return nil
+	}
+
+	page.DataTable.ToValues()
+	dictValues := dictPage.DataTable.Values
+	if dictPage.DataTable.Vector != nil {
+		dictValues = vectorValues(dictPage.DataTable.Vector)
+	}
 	numValues := len(page.DataTable.Values)
 	for i := 0; i < numValues; i++ {
 		if page.DataTable.Values[i] != nil {
-			index := page.DataTable.Values[i].(int64)
-			page.DataTable.Values[i] = dictPage.DataTable.Values[index]
+			index, ok := page.DataTable.Values[i].(int64)
+			if !ok || index < 0 || index >= int64(len(dictValues)) {
+				return fmt.Errorf("dictionary index %v out of range %v", page.DataTable.Values[i], len(dictValues))
+			}
+			page.DataTable.Values[i] = dictValues[index]
 		}
 	}
+	return nil
 }
 
 //Encoding values
@@ -190,30 +297,96 @@ func (page *Page) EncodingValues(valuesBuf []interface{}) []byte {
 	}
 }
 
+//Encoding the values of a vector, the encodings which aren't implemented for
+//the type of the vector are done by EncodingValues
+func (page *Page) EncodingVector(vector ColumnVector) []byte {
+	encodingMethod := parquet.Encoding_PLAIN
+	if page.Info.Encoding != 0 {
+		encodingMethod = page.Info.Encoding
+	}
+
+	if vector.Len() > 0 {
+		switch v := vector.(type) {
+		case *BooleanVector:
+			if encodingMethod == parquet.Encoding_PLAIN {
+				return encoding.WritePlainBools(v.Values)
+			}
+		case *Int32Vector:
+			if encodingMethod == parquet.Encoding_PLAIN {
+				return encoding.WritePlainInt32s(v.Values)
+			} else if encodingMethod == parquet.Encoding_RLE && page.Info.Length <= 32 {
+				return encoding.WriteRLEBitPackedHybridInt32(v.Values, page.Info.Length)
+			} else if encodingMethod == parquet.Encoding_DELTA_BINARY_PACKED {
+				return encoding.WriteDeltaInt32s(v.Values)
+			}
+		case *Int64Vector:
+			if encodingMethod == parquet.Encoding_PLAIN {
+				return encoding.WritePlainInt64s(v.Values)
+			} else if encodingMethod == parquet.Encoding_DELTA_BINARY_PACKED {
+				return encoding.WriteDeltaInt64s(v.Values)
+			}
+		case *FloatVector:
+			if encodingMethod == parquet.Encoding_PLAIN {
+				return encoding.WritePlainFloat32s(v.Values)
+			} else if encodingMethod == parquet.Encoding_BYTE_STREAM_SPLIT {
+				return encoding.WriteByteStreamSplitFloat32s(v.Values)
+			}
+		case *DoubleVector:
+			if encodingMethod == parquet.Encoding_PLAIN {
+				return encoding.WritePlainFloat64s(v.Values)
+			} else if encodingMethod == parquet.Encoding_BYTE_STREAM_SPLIT {
+				return encoding.WriteByteStreamSplitFloat64s(v.Values)
+			}
+		case *ByteArrayVector:
+			if encodingMethod == parquet.Encoding_PLAIN && v.PhysicalType == parquet.Type_BYTE_ARRAY {
+				return encoding.WritePlainByteArrays(v.Values)
+			} else if encodingMethod == parquet.Encoding_PLAIN {
+				return encoding.WritePlainFixedLenByteArrays(v.Values)
+			} else if encodingMethod == parquet.Encoding_DELTA_BYTE_ARRAY {
+				return encoding.WriteDeltaByteArrays(v.Values)
+			} else if encodingMethod == parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY {
+				return encoding.WriteDeltaLengthByteArrays(v.Values)
+			}
+		}
+	}
+
+	return page.EncodingValues(vectorValues(vector))
+}
+
+//Compress the data of the page with its compression level
+func (page *Page) compress(buf []byte, compressType parquet.CompressionCodec) ([]byte, error) {
+	return compress.CompressWithLevel(buf, compressType, page.Info.CompressionLevel)
+}
+
 //Compress the data page to parquet file
-func (page *Page) DataPageCompress(compressType parquet.CompressionCodec) []byte {
+func (page *Page) DataPageCompress(compressType parquet.CompressionCodec) ([]byte, error) {
 	ln := len(page.DataTable.DefinitionLevels)
 
 	//values////////////////////////////////////////////
 	// valuesBuf == nil means "up to i, every item in DefinitionLevels was
 	// MaxDefinitionLevel". This lets us avoid allocating the array for the
 	// (somewhat) common case of "all values present".
-	var valuesBuf []interface{}
-	for i := 0; i < ln; i++ {
-		if page.DataTable.DefinitionLevels[i] == page.DataTable.MaxDefinitionLevel {
-			if valuesBuf != nil {
-				valuesBuf = append(valuesBuf, page.DataTable.Values[i])
+	var valuesRawBuf []byte
+	if page.DataTable.Vector != nil {
+		valuesRawBuf = page.EncodingVector(page.DataTable.Vector)
+	} else {
+		var valuesBuf []interface{}
+		for i := 0; i < ln; i++ {
+			if page.DataTable.DefinitionLevels[i] == page.DataTable.MaxDefinitionLevel {
+				if valuesBuf != nil {
+					valuesBuf = append(valuesBuf, page.DataTable.Values[i])
+				}
+			} else if valuesBuf == nil {
+				valuesBuf = make([]interface{}, i, ln)
+				copy(valuesBuf[:i], page.DataTable.Values[:i])
 			}
-		} else if valuesBuf == nil {
-			valuesBuf = make([]interface{}, i, ln)
-			copy(valuesBuf[:i], page.DataTable.Values[:i])
 		}
+		if valuesBuf == nil {
+			valuesBuf = page.DataTable.Values
+		}
+		//valuesRawBuf := encoding.WritePlain(valuesBuf)
+		valuesRawBuf = page.EncodingValues(valuesBuf)
 	}
-	if valuesBuf == nil {
-		valuesBuf = page.DataTable.Values
-	}
-	//valuesRawBuf := encoding.WritePlain(valuesBuf)
-	valuesRawBuf := page.EncodingValues(valuesBuf)
 
 	//definitionLevel//////////////////////////////////
 	var definitionLevelBuf []byte
@@ -237,7 +410,10 @@ func (page *Page) DataPageCompress(compressType parquet.CompressionCodec) []byte
 	dataBuf = append(dataBuf, definitionLevelBuf...)
 	dataBuf = append(dataBuf, valuesRawBuf...)
 
-	var dataEncodeBuf []byte = compress.Compress(dataBuf, compressType)
+	dataEncodeBuf, err := page.compress(dataBuf, compressType)
+	if err != nil {
+		return nil, err
+	}
 
 	//pageHeader/////////////////////////////////////
 	page.Header = parquet.NewPageHeader()
@@ -250,25 +426,7 @@ func (page *Page) DataPageCompress(compressType parquet.CompressionCodec) []byte
 	page.Header.DataPageHeader.RepetitionLevelEncoding = parquet.Encoding_RLE
 	page.Header.DataPageHeader.Encoding = page.Info.Encoding
 
-	page.Header.DataPageHeader.Statistics = parquet.NewStatistics()
-	if page.MaxVal != nil {
-		tmpBuf := encoding.WritePlain([]interface{}{page.MaxVal}, *page.Schema.Type)
-		if *page.Schema.Type == parquet.Type_BYTE_ARRAY {
-			tmpBuf = tmpBuf[4:]
-		}
-		page.Header.DataPageHeader.Statistics.Max = tmpBuf
-		page.Header.DataPageHeader.Statistics.MaxValue = tmpBuf
-	}
-	if page.MinVal != nil {
-		tmpBuf := encoding.WritePlain([]interface{}{page.MinVal}, *page.Schema.Type)
-		if *page.Schema.Type == parquet.Type_BYTE_ARRAY {
-			tmpBuf = tmpBuf[4:]
-		}
-		page.Header.DataPageHeader.Statistics.Min = tmpBuf
-		page.Header.DataPageHeader.Statistics.MinValue = tmpBuf
-	}
-
-	page.Header.DataPageHeader.Statistics.NullCount = page.NullCount
+	page.Header.DataPageHeader.Statistics = page.statistics()
 
 	ts := thrift.NewTSerializer()
 	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
@@ -277,52 +435,65 @@ func (page *Page) DataPageCompress(compressType parquet.CompressionCodec) []byte
 	res := append(pageHeaderBuf, dataEncodeBuf...)
 	page.RawData = res
 
-	return res
+	return res, nil
 }
 
-//Compress data page v2 to parquet file
-func (page *Page) DataPageV2Compress(compressType parquet.CompressionCodec) []byte {
+//Compress data page v2 to parquet file, the levels aren't compressed
+func (page *Page) DataPageV2Compress(compressType parquet.CompressionCodec) ([]byte, error) {
 	ln := len(page.DataTable.DefinitionLevels)
 
 	//values////////////////////////////////////////////
-	valuesBuf := make([]interface{}, 0)
-	for i := 0; i < ln; i++ {
-		if page.DataTable.DefinitionLevels[i] == page.DataTable.MaxDefinitionLevel {
-			valuesBuf = append(valuesBuf, page.DataTable.Values[i])
+	var valuesRawBuf []byte
+	numNotNulls := 0
+	if page.DataTable.Vector != nil {
+		numNotNulls = page.DataTable.Vector.Len()
+		valuesRawBuf = page.EncodingVector(page.DataTable.Vector)
+	} else {
+		valuesBuf := make([]interface{}, 0)
+		for i := 0; i < ln; i++ {
+			if page.DataTable.DefinitionLevels[i] == page.DataTable.MaxDefinitionLevel {
+				valuesBuf = append(valuesBuf, page.DataTable.Values[i])
+			}
 		}
+		numNotNulls = len(valuesBuf)
+		valuesRawBuf = page.EncodingValues(valuesBuf)
 	}
-	//valuesRawBuf := encoding.WritePlain(valuesBuf)
-	valuesRawBuf := page.EncodingValues(valuesBuf)
+
+	if err := page.dataPageV2(compressType, valuesRawBuf, int32(numNotNulls), page.Info.Encoding); err != nil {
+		return nil, err
+	}
+	return page.RawData, nil
+}
+
+//Set the header and the RawData of a data page v2 from its encoded values
+func (page *Page) dataPageV2(compressType parquet.CompressionCodec, valuesRawBuf []byte, numNotNulls int32, encodingMethod parquet.Encoding) error {
+	ln := len(page.DataTable.DefinitionLevels)
 
 	//definitionLevel//////////////////////////////////
 	var definitionLevelBuf []byte
 	if page.DataTable.MaxDefinitionLevel > 0 {
-		numInterfaces := make([]interface{}, ln)
-		for i := 0; i < ln; i++ {
-			numInterfaces[i] = int64(page.DataTable.DefinitionLevels[i])
-		}
-		definitionLevelBuf = encoding.WriteRLE(numInterfaces,
-			int32(bits.Len32(uint32(page.DataTable.MaxDefinitionLevel))),
-			parquet.Type_INT64)
+		definitionLevelBuf = encoding.WriteRLEInt32(page.DataTable.DefinitionLevels,
+			int32(bits.Len32(uint32(page.DataTable.MaxDefinitionLevel))))
 	}
 
 	//repetitionLevel/////////////////////////////////
-	r0Num := int32(0)
+	numRows := int32(ln)
 	var repetitionLevelBuf []byte
 	if page.DataTable.MaxRepetitionLevel > 0 {
-		numInterfaces := make([]interface{}, ln)
+		numRows = 0
 		for i := 0; i < ln; i++ {
-			numInterfaces[i] = int64(page.DataTable.RepetitionLevels[i])
 			if page.DataTable.RepetitionLevels[i] == 0 {
-				r0Num++
+				numRows++
 			}
 		}
-		repetitionLevelBuf = encoding.WriteRLE(numInterfaces,
-			int32(bits.Len32(uint32(page.DataTable.MaxRepetitionLevel))),
-			parquet.Type_INT64)
+		repetitionLevelBuf = encoding.WriteRLEInt32(page.DataTable.RepetitionLevels,
+			int32(bits.Len32(uint32(page.DataTable.MaxRepetitionLevel))))
 	}
 
-	var dataEncodeBuf []byte = compress.Compress(valuesRawBuf, compressType)
+	dataEncodeBuf, err := page.compress(valuesRawBuf, compressType)
+	if err != nil {
+		return err
+	}
 
 	//pageHeader/////////////////////////////////////
 	page.Header = parquet.NewPageHeader()
@@ -330,35 +501,16 @@ func (page *Page) DataPageV2Compress(compressType parquet.CompressionCodec) []by
 	page.Header.CompressedPageSize = int32(len(dataEncodeBuf) + len(definitionLevelBuf) + len(repetitionLevelBuf))
 	page.Header.UncompressedPageSize = int32(len(valuesRawBuf) + len(definitionLevelBuf) + len(repetitionLevelBuf))
 	page.Header.DataPageHeaderV2 = parquet.NewDataPageHeaderV2()
-	page.Header.DataPageHeaderV2.NumValues = int32(len(page.DataTable.Values))
-	page.Header.DataPageHeaderV2.NumNulls = page.Header.DataPageHeaderV2.NumValues - int32(len(valuesBuf))
-	page.Header.DataPageHeaderV2.NumRows = r0Num
-	//page.Header.DataPageHeaderV2.Encoding = parquet.Encoding_PLAIN
-	page.Header.DataPageHeaderV2.Encoding = page.Info.Encoding
+	page.Header.DataPageHeaderV2.NumValues = int32(ln)
+	page.Header.DataPageHeaderV2.NumNulls = int32(ln) - numNotNulls
+	page.Header.DataPageHeaderV2.NumRows = numRows
+	page.Header.DataPageHeaderV2.Encoding = encodingMethod
 
 	page.Header.DataPageHeaderV2.DefinitionLevelsByteLength = int32(len(definitionLevelBuf))
 	page.Header.DataPageHeaderV2.RepetitionLevelsByteLength = int32(len(repetitionLevelBuf))
-	page.Header.DataPageHeaderV2.IsCompressed = true
-
-	page.Header.DataPageHeaderV2.Statistics = parquet.NewStatistics()
-	if page.MaxVal != nil {
-		tmpBuf := encoding.WritePlain([]interface{}{page.MaxVal}, *page.Schema.Type)
-		if *page.Schema.Type == parquet.Type_BYTE_ARRAY {
-			tmpBuf = tmpBuf[4:]
-		}
-		page.Header.DataPageHeaderV2.Statistics.Max = tmpBuf
-		page.Header.DataPageHeaderV2.Statistics.MaxValue = tmpBuf
-	}
-	if page.MinVal != nil {
-		tmpBuf := encoding.WritePlain([]interface{}{page.MinVal}, *page.Schema.Type)
-		if *page.Schema.Type == parquet.Type_BYTE_ARRAY {
-			tmpBuf = tmpBuf[4:]
-		}
-		page.Header.DataPageHeaderV2.Statistics.Min = tmpBuf
-		page.Header.DataPageHeaderV2.Statistics.MinValue = tmpBuf
-	}
+	page.Header.DataPageHeaderV2.IsCompressed = compressType != parquet.CompressionCodec_UNCOMPRESSED
 
-	page.Header.DataPageHeaderV2.Statistics.NullCount = page.NullCount
+	page.Header.DataPageHeaderV2.Statistics = page.statistics()
 
 	ts := thrift.NewTSerializer()
 	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
@@ -370,8 +522,30 @@ func (page *Page) DataPageV2Compress(compressType parquet.CompressionCodec) []by
 	res = append(res, definitionLevelBuf...)
 	res = append(res, dataEncodeBuf...)
 	page.RawData = res
+	return nil
+}
 
-	return res
+//Statistics of the header of a data page
+func (page *Page) statistics() *parquet.Statistics {
+	statistics := parquet.NewStatistics()
+	if page.MaxVal != nil {
+		tmpBuf := encoding.WritePlain([]interface{}{page.MaxVal}, *page.Schema.Type)
+		if *page.Schema.Type == parquet.Type_BYTE_ARRAY {
+			tmpBuf = tmpBuf[4:]
+		}
+		statistics.Max = tmpBuf
+		statistics.MaxValue = tmpBuf
+	}
+	if page.MinVal != nil {
+		tmpBuf := encoding.WritePlain([]interface{}{page.MinVal}, *page.Schema.Type)
+		if *page.Schema.Type == parquet.Type_BYTE_ARRAY {
+			tmpBuf = tmpBuf[4:]
+		}
+		statistics.Min = tmpBuf
+		statistics.MinValue = tmpBuf
+	}
+	statistics.NullCount = page.NullCount
+	return statistics
 }
 
 //This is a test function
@@ -393,16 +567,20 @@ func ReadPage2(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.Sc
 
 //Read page RawData
 func ReadPageRawData(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.SchemaHandler, colMetaData *parquet.ColumnMetaData) (*Page, error) {
-	var (
-		err error
-	)
+	return readPageRawData(thriftReader, schemaHandler, colMetaData, PageLimits{})
+}
+
+func readPageRawData(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.SchemaHandler, colMetaData *parquet.ColumnMetaData, limits PageLimits) (page *Page, err error) {
+	defer recoverError(&err)
 
 	pageHeader, err := ReadPageHeader(thriftReader)
 	if err != nil {
 		return nil, err
 	}
+	if err = limits.checkHeader(pageHeader); err != nil {
+		return nil, err
+	}
 
-	var page *Page
 	if pageHeader.GetType() == parquet.PageType_DATA_PAGE || pageHeader.GetType() == parquet.PageType_DATA_PAGE_V2 {
 		page = NewDataPage()
 	} else if pageHeader.GetType() == parquet.PageType_DICTIONARY_PAGE {
@@ -420,6 +598,7 @@ func ReadPageRawData(thriftReader *thrift.TBufferedTransport, schemaHandler *sch
 	page.Header = pageHeader
 	page.CompressType = colMetaData.GetCodec()
 	page.RawData = buf
+	page.maxSize = limits.MaxPageSize
 	page.Path = make([]string, 0)
 	page.Path = append(page.Path, schemaHandler.GetRootInName())
 	page.Path = append(page.Path, colMetaData.GetPathInSchema()...)
@@ -430,14 +609,17 @@ func ReadPageRawData(thriftReader *thrift.TBufferedTransport, schemaHandler *sch
 }
 
 //Get RepetitionLevels and Definitions from RawData
-func (p *Page) GetRLDLFromRawData(schemaHandler *schema.SchemaHandler) (int64, int64, error) {
-	var err error
+func (p *Page) GetRLDLFromRawData(schemaHandler *schema.SchemaHandler) (numValues int64, numRows int64, err error) {
+	defer recoverError(&err)
 	bytesReader := bytes.NewReader(p.RawData)
 	buf := make([]byte, 0)
 
 	if p.Header.GetType() == parquet.PageType_DATA_PAGE_V2 {
 		dll := p.Header.DataPageHeaderV2.GetDefinitionLevelsByteLength()
 		rll := p.Header.DataPageHeaderV2.GetRepetitionLevelsByteLength()
+		if rll < 0 || dll < 0 || int(rll)+int(dll) > len(p.RawData) {
+			return 0, 0, fmt.Errorf("invalid levels size %v and %v of a page of size %v", rll, dll, len(p.RawData))
+		}
 		repetitionLevelsBuf, definitionLevelsBuf := make([]byte, rll), make([]byte, dll)
 		dataBuf := make([]byte, len(p.RawData)-int(rll)-int(dll))
 		bytesReader.Read(repetitionLevelsBuf)
@@ -460,8 +642,8 @@ func (p *Page) GetRLDLFromRawData(schemaHandler *schema.SchemaHandler) (int64, i
 		buf = append(buf, dataBuf...)
 
 	} else {
-		if buf, err = compress.Uncompress(p.RawData, p.CompressType); err != nil {
-			return 0, 0, fmt.Errorf("Unsupported compress method")
+		if buf, err = uncompress(p.RawData, p.CompressType, p.maxSize); err != nil {
+			return 0, 0, err
 		}
 	}
 
@@ -520,6 +702,9 @@ func (p *Page) GetRLDLFromRawData(schemaHandler *schema.SchemaHandler) (int64, i
 		if len(definitionLevels) > int(numValues) {
 			definitionLevels = definitionLevels[:numValues]
 		}
+		if err = checkLevels(repetitionLevels, definitionLevels, numValues, maxRepetitionLevel, maxDefinitionLevel); err != nil {
+			return 0, 0, err
+		}
 
 		table := new(Table)
 		table.Path = p.Path
@@ -559,8 +744,8 @@ func (p *Page) GetRLDLFromRawData(schemaHandler *schema.SchemaHandler) (int64, i
 }
 
 //Get values from raw data
-func (p *Page) GetValueFromRawData(schemaHandler *schema.SchemaHandler) error {
-	var err error
+func (p *Page) GetValueFromRawData(schemaHandler *schema.SchemaHandler) (err error) {
+	defer recoverError(&err)
 	var encodingType parquet.Encoding
 
 	switch p.Header.GetType() {
@@ -573,14 +758,17 @@ func (p *Page) GetValueFromRawData(schemaHandler *schema.SchemaHandler) error {
 		if err != nil {
 			return err
 		}
-	case parquet.PageType_DATA_PAGE_V2:
-		if p.RawData, err = compress.Uncompress(p.RawData, p.CompressType); err != nil {
-			return err
+	case parquet.PageType_DATA_PAGE_V2, parquet.PageType_DATA_PAGE:
+		if p.Header.GetType() == parquet.PageType_DATA_PAGE_V2 {
+			if len(p.RawData) > 0 && p.Header.DataPageHeaderV2.GetIsCompressed() {
+				if p.RawData, err = uncompress(p.RawData, p.CompressType, p.maxSize); err != nil {
+					return err
+				}
+			}
+			encodingType = p.Header.DataPageHeaderV2.GetEncoding()
+		} else {
+			encodingType = p.Header.DataPageHeader.GetEncoding()
 		}
-		encodingType = p.Header.DataPageHeader.GetEncoding()
-		fallthrough
-	case parquet.PageType_DATA_PAGE:
-		encodingType = p.Header.DataPageHeader.GetEncoding()
 		bytesReader := bytes.NewReader(p.RawData)
 
 		var numNulls uint64 = 0
@@ -605,6 +793,9 @@ func (p *Page) GetValueFromRawData(schemaHandler *schema.SchemaHandler) error {
 		if err != nil {
 			return err
 		}
+		if uint64(len(values)) < uint64(len(p.DataTable.DefinitionLevels))-numNulls {
+			return fmt.Errorf("page has %v values, fewer than its %v non-null levels", len(values), uint64(len(p.DataTable.DefinitionLevels))-numNulls)
+		}
 		j := 0
 		for i := 0; i < len(p.DataTable.DefinitionLevels); i++ {
 			if p.DataTable.DefinitionLevels[i] == p.DataTable.MaxDefinitionLevel {
@@ -629,6 +820,21 @@ func ReadPageHeader(thriftReader *thrift.TBufferedTransport) (*parquet.PageHeade
 	return pageHeader, err
 }
 
+//Check that the levels of a page are as many as its values and not larger than the max levels
+func checkLevels(repetitionLevels, definitionLevels []interface{}, numValues uint64, maxRepetitionLevel, maxDefinitionLevel int32) error {
+	if uint64(len(repetitionLevels)) != numValues || uint64(len(definitionLevels)) != numValues {
+		return fmt.Errorf("page has %v repetition levels and %v definition levels, instead of %v", len(repetitionLevels), len(definitionLevels), numValues)
+	}
+	for i := range definitionLevels {
+		rl, _ := repetitionLevels[i].(int64)
+		dl, _ := definitionLevels[i].(int64)
+		if rl < 0 || rl > int64(maxRepetitionLevel) || dl < 0 || dl > int64(maxDefinitionLevel) {
+			return fmt.Errorf("invalid levels %v and %v of value %v", rl, dl, i)
+		}
+	}
+	return nil
+}
+
 //Read data page values
 func ReadDataPageValues(bytesReader *bytes.Reader, encodingMethod parquet.Encoding, dataType parquet.Type, convertedType parquet.ConvertedType, cnt uint64, bitWidth uint64) ([]interface{}, error) {
 	var (
@@ -648,17 +854,23 @@ func ReadDataPageValues(bytesReader *bytes.Reader, encodingMethod parquet.Encodi
 		}
 		bitWidth = uint64(b)
 
-		buf, err := encoding.ReadRLEBitPackedHybrid(bytesReader, bitWidth, uint64(bytesReader.Len()))
+		buf, err := encoding.ReadRLEBitPackedHybridN(bytesReader, bitWidth, uint64(bytesReader.Len()), cnt)
 		if err != nil {
 			return res, err
 		}
+		if uint64(len(buf)) < cnt {
+			return res, fmt.Errorf("page has %v dictionary indexes, fewer than %v", len(buf), cnt)
+		}
 		return buf[:cnt], err
 
 	} else if encodingMethod == parquet.Encoding_RLE {
-		values, err := encoding.ReadRLEBitPackedHybrid(bytesReader, bitWidth, 0)
+		values, err := encoding.ReadRLEBitPackedHybridN(bytesReader, bitWidth, 0, cnt)
 		if err != nil {
 			return res, err
 		}
+		if uint64(len(values)) < cnt {
+			return res, fmt.Errorf("page has %v RLE values, fewer than %v", len(values), cnt)
+		}
 		if dataType == parquet.Type_INT32 {
 			for i := 0; i < len(values); i++ {
 				values[i] = int32(values[i].(int64))
@@ -673,16 +885,16 @@ func ReadDataPageValues(bytesReader *bytes.Reader, encodingMethod parquet.Encodi
 	} else if encodingMethod == parquet.Encoding_DELTA_BINARY_PACKED {
 
 		if dataType == parquet.Type_INT32 {
-			return encoding.ReadDeltaBinaryPackedINT32(bytesReader)
+			return encoding.ReadDeltaBinaryPackedINT32N(bytesReader, cnt)
 
 		} else if dataType == parquet.Type_INT64 {
-			return encoding.ReadDeltaBinaryPackedINT64(bytesReader)
+			return encoding.ReadDeltaBinaryPackedINT64N(bytesReader, cnt)
 
 		}
 		return res, fmt.Errorf("The encoding method DELTA_BINARY_PACKED can only be used with int32 and int64 types")
 
 	} else if encodingMethod == parquet.Encoding_DELTA_LENGTH_BYTE_ARRAY {
-		values, err := encoding.ReadDeltaLengthByteArray(bytesReader)
+		values, err := encoding.ReadDeltaLengthByteArrayN(bytesReader, cnt)
 		if err != nil {
 			return res, err
 		}
@@ -691,10 +903,13 @@ func ReadDataPageValues(bytesReader *bytes.Reader, encodingMethod parquet.Encodi
 				values[i] = values[i].(string)
 			}
 		}
+		if uint64(len(values)) < cnt {
+			return res, fmt.Errorf("page has %v DELTA_LENGTH_BYTE_ARRAY values, fewer than %v", len(values), cnt)
+		}
 		return values[:cnt], nil
 
 	} else if encodingMethod == parquet.Encoding_DELTA_BYTE_ARRAY {
-		values, err := encoding.ReadDeltaByteArray(bytesReader)
+		values, err := encoding.ReadDeltaByteArrayN(bytesReader, cnt)
 		if err != nil {
 			return res, err
 		}
@@ -703,6 +918,9 @@ func ReadDataPageValues(bytesReader *bytes.Reader, encodingMethod parquet.Encodi
 				values[i] = values[i].(string)
 			}
 		}
+		if uint64(len(values)) < cnt {
+			return res, fmt.Errorf("page has %v DELTA_BYTE_ARRAY values, fewer than %v", len(values), cnt)
+		}
 		return values[:cnt], nil
 	} else if encodingMethod == parquet.Encoding_BYTE_STREAM_SPLIT {
 		if dataType == parquet.Type_FLOAT {
@@ -719,43 +937,98 @@ func ReadDataPageValues(bytesReader *bytes.Reader, encodingMethod parquet.Encodi
 
 //Read page from parquet file
 func ReadPage(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.SchemaHandler, colMetaData *parquet.ColumnMetaData) (*Page, int64, int64, error) {
-	var (
-		err error
-	)
+	return readPage(thriftReader, schemaHandler, colMetaData, false, PageLimits{})
+}
 
-	pageHeader, err := ReadPageHeader(thriftReader)
+//Read page from parquet file, the values are read to the Vector of the DataTable
+//if the encoding of the page is read to vectors
+func ReadPageVector(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.SchemaHandler, colMetaData *parquet.ColumnMetaData) (*Page, int64, int64, error) {
+	return readPage(thriftReader, schemaHandler, colMetaData, true, PageLimits{})
+}
+
+func readPage(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.SchemaHandler, colMetaData *parquet.ColumnMetaData, vector bool, limits PageLimits) (_ *Page, _ int64, _ int64, err error) {
+	defer recoverError(&err)
+
+	compressedPage, err := readCompressedPage(thriftReader, limits)
 	if err != nil {
 		return nil, 0, 0, err
 	}
+	return decodePage(compressedPage, schemaHandler, colMetaData, vector, limits)
+}
+
+//CompressedPage is a page read from a file, whose data isn't uncompressed and decoded yet
+type CompressedPage struct {
+	Header *parquet.PageHeader
+	//Data of the page as in the file
+	Data []byte
+	//Data outlives the page and isn't modified, such as the bytes of a memory-mapped
+	//file. The BYTE_ARRAY values of the uncompressed pages with the PLAIN encoding
+	//are views of Data instead of copies when they are read to vectors.
+	Views bool
+}
+
+//Read the page at the start of buf, which holds the bytes of a file from the page.
+//The Data of the page is a sub-slice of buf instead of a copy, Views can be set if buf
+//outlives the values. It returns the page and its size in buf, including the header.
+func (limits PageLimits) ReadMappedPage(buf []byte) (_ *CompressedPage, _ int64, err error) {
+	defer recoverError(&err)
+	//the transport isn't buffered, so that the size of the header is known
+	transport := &thrift.TMemoryBuffer{Buffer: bytes.NewBuffer(buf)}
+	protocol := thrift.NewTCompactProtocol(transport)
+	pageHeader := parquet.NewPageHeader()
+	if err = pageHeader.Read(context.TODO(), protocol); err != nil {
+		return nil, 0, err
+	}
+	if err = limits.checkHeader(pageHeader); err != nil {
+		return nil, 0, err
+	}
+	start := int64(len(buf) - transport.Len())
+	end := start + int64(pageHeader.GetCompressedPageSize())
+	if end > int64(len(buf)) {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	return &CompressedPage{Header: pageHeader, Data: buf[start:end:end]}, end, nil
+}
 
-	buf := make([]byte, 0)
+//Read the header and data of a page
+func readCompressedPage(thriftReader *thrift.TBufferedTransport, limits PageLimits) (*CompressedPage, error) {
+	pageHeader, err := ReadPageHeader(thriftReader)
+	if err != nil {
+		return nil, err
+	}
+	if err = limits.checkHeader(pageHeader); err != nil {
+		return nil, err
+	}
+	data := make([]byte, pageHeader.GetCompressedPageSize())
+	if _, err = io.ReadFull(thriftReader, data); err != nil {
+		return nil, err
+	}
+	return &CompressedPage{Header: pageHeader, Data: data}, nil
+}
 
+//Uncompress and decode a page read by readCompressedPage
+func decodePage(compressedPage *CompressedPage, schemaHandler *schema.SchemaHandler, colMetaData *parquet.ColumnMetaData, vector bool, limits PageLimits) (_ *Page, _ int64, _ int64, err error) {
+	pageHeader := compressedPage.Header
+	var buf []byte
 	var page *Page
-	compressedPageSize := pageHeader.GetCompressedPageSize()
+	//the BYTE_ARRAY values of PLAIN pages are views of buf
+	views := compressedPage.Views && vector && colMetaData.GetType() == parquet.Type_BYTE_ARRAY
 
 	if pageHeader.GetType() == parquet.PageType_DATA_PAGE_V2 {
 		dll := pageHeader.DataPageHeaderV2.GetDefinitionLevelsByteLength()
 		rll := pageHeader.DataPageHeaderV2.GetRepetitionLevelsByteLength()
-		repetitionLevelsBuf := make([]byte, rll)
-		definitionLevelsBuf := make([]byte, dll)
-		dataBuf := make([]byte, compressedPageSize-rll-dll)
-
-		if _, err = io.ReadFull(thriftReader, repetitionLevelsBuf); err != nil {
-			return nil, 0, 0, err
-		}
-		if _, err = io.ReadFull(thriftReader, definitionLevelsBuf); err != nil {
-			return nil, 0, 0, err
-		}
-		if _, err = io.ReadFull(thriftReader, dataBuf); err != nil {
-			return nil, 0, 0, err
-		}
+		repetitionLevelsBuf := compressedPage.Data[:rll]
+		definitionLevelsBuf := compressedPage.Data[rll : rll+dll]
+		dataBuf := compressedPage.Data[rll+dll:]
 
 		codec := colMetaData.GetCodec()
-		if len(dataBuf) > 0 {
-			if dataBuf, err = compress.Uncompress(dataBuf, codec); err != nil {
+		if len(dataBuf) > 0 && pageHeader.DataPageHeaderV2.GetIsCompressed() {
+			if dataBuf, err = uncompress(dataBuf, codec, limits.MaxPageSize); err != nil {
 				return nil, 0, 0, err
 			}
 		}
+		//the levels are copied in buf
+		views = false
 
 		tmpBuf := make([]byte, 0)
 		if rll > 0 {
@@ -773,14 +1046,11 @@ func ReadPage(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.Sch
 		buf = append(buf, dataBuf...)
 
 	} else {
-		buf = make([]byte, compressedPageSize)
-		if _, err = io.ReadFull(thriftReader, buf); err != nil {
-			return nil, 0, 0, err
-		}
 		codec := colMetaData.GetCodec()
-		if buf, err = compress.Uncompress(buf, codec); err != nil {
+		if buf, err = uncompress(compressedPage.Data, codec, limits.MaxPageSize); err != nil {
 			return nil, 0, 0, err
 		}
+		views = views && codec == parquet.CompressionCodec_UNCOMPRESSED
 	}
 
 	bytesReader := bytes.NewReader(buf)
@@ -799,10 +1069,21 @@ func ReadPage(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.Sch
 			bitWidth = int(schemaHandler.SchemaElements[idx].GetTypeLength())
 		}
 
-		table.Values, err = encoding.ReadPlain(bytesReader,
-			colMetaData.GetType(),
-			uint64(pageHeader.DictionaryPageHeader.GetNumValues()),
-			uint64(bitWidth))
+		if views {
+			var values []string
+			values, err = encoding.ReadPlainByteArrayViews(bytesReader, buf, uint64(pageHeader.DictionaryPageHeader.GetNumValues()))
+			table.Vector = &ByteArrayVector{PhysicalType: parquet.Type_BYTE_ARRAY, Values: values}
+		} else if vector {
+			table.Vector, err = ReadPlainVector(bytesReader,
+				colMetaData.GetType(),
+				uint64(pageHeader.DictionaryPageHeader.GetNumValues()),
+				uint64(bitWidth))
+		} else {
+			table.Values, err = encoding.ReadPlain(bytesReader,
+				colMetaData.GetType(),
+				uint64(pageHeader.DictionaryPageHeader.GetNumValues()),
+				uint64(bitWidth))
+		}
 		if err != nil {
 			return nil, 0, 0, err
 		}
@@ -879,6 +1160,9 @@ func ReadPage(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.Sch
 		if len(definitionLevels) > int(numValues) {
 			definitionLevels = definitionLevels[:numValues]
 		}
+		if err = checkLevels(repetitionLevels, definitionLevels, numValues, maxRepetitionLevel, maxDefinitionLevel); err != nil {
+			return nil, 0, 0, err
+		}
 
 		var numNulls uint64 = 0
 		for i := 0; i < len(definitionLevels); i++ {
@@ -888,26 +1172,47 @@ func ReadPage(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.Sch
 		}
 
 		var values []interface{}
+		var valuesVector ColumnVector
 		var ct parquet.ConvertedType = -1
 		if schemaHandler.SchemaElements[schemaHandler.MapIndex[name]].IsSetConvertedType() {
 			ct = schemaHandler.SchemaElements[schemaHandler.MapIndex[name]].GetConvertedType()
 		}
-		values, err = ReadDataPageValues(bytesReader,
-			encodingType,
-			colMetaData.GetType(),
-			ct,
-			uint64(len(definitionLevels))-numNulls,
-			uint64(schemaHandler.SchemaElements[schemaHandler.MapIndex[name]].GetTypeLength()))
+		if views && encodingType == parquet.Encoding_PLAIN {
+			var values []string
+			values, err = encoding.ReadPlainByteArrayViews(bytesReader, buf, uint64(len(definitionLevels))-numNulls)
+			valuesVector = &ByteArrayVector{PhysicalType: parquet.Type_BYTE_ARRAY, Values: values}
+		} else if vector {
+			valuesVector, err = ReadDataPageVector(bytesReader,
+				encodingType,
+				colMetaData.GetType(),
+				uint64(len(definitionLevels))-numNulls,
+				uint64(schemaHandler.SchemaElements[schemaHandler.MapIndex[name]].GetTypeLength()))
+		}
+		if valuesVector == nil && err == nil {
+			values, err = ReadDataPageValues(bytesReader,
+				encodingType,
+				colMetaData.GetType(),
+				ct,
+				uint64(len(definitionLevels))-numNulls,
+				uint64(schemaHandler.SchemaElements[schemaHandler.MapIndex[name]].GetTypeLength()))
+		}
 		if err != nil {
 			return nil, 0, 0, err
 		}
+		numNotNull := uint64(len(definitionLevels)) - numNulls
+		if (valuesVector != nil && uint64(valuesVector.Len()) < numNotNull) || (valuesVector == nil && uint64(len(values)) < numNotNull) {
+			return nil, 0, 0, fmt.Errorf("page has fewer values than its %v non-null levels", numNotNull)
+		}
 
 		table := new(Table)
 		table.Path = path
 		table.RepetitionType = schemaHandler.SchemaElements[schemaHandler.MapIndex[name]].GetRepetitionType()
 		table.MaxRepetitionLevel = maxRepetitionLevel
 		table.MaxDefinitionLevel = maxDefinitionLevel
-		table.Values = make([]interface{}, len(definitionLevels))
+		table.Vector = valuesVector
+		if valuesVector == nil {
+			table.Values = make([]interface{}, len(definitionLevels))
+		}
 		table.RepetitionLevels = make([]int32, len(definitionLevels))
 		table.DefinitionLevels = make([]int32, len(definitionLevels))
 
@@ -918,7 +1223,7 @@ func ReadPage(thriftReader *thrift.TBufferedTransport, schemaHandler *schema.Sch
 			rl, _ := repetitionLevels[i].(int64)
 			table.RepetitionLevels[i] = int32(rl)
 			table.DefinitionLevels[i] = int32(dl)
-			if table.DefinitionLevels[i] == maxDefinitionLevel {
+			if table.DefinitionLevels[i] == maxDefinitionLevel && valuesVector == nil {
 				table.Values[i] = values[j]
 				j++
 			}
@@ -0,0 +1,43 @@
+package layout
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+func TestTableToPagesCompressError(t *testing.T) {
+	pT := parquet.Type_INT64
+	newTable := func() *Table {
+		return &Table{
+			Schema:           &parquet.SchemaElement{Type: &pT, Name: "id"},
+			Path:             []string{"root", "id"},
+			Values:           []interface{}{int64(1), int64(2), int64(1)},
+			DefinitionLevels: []int32{0, 0, 0},
+			RepetitionLevels: []int32{0, 0, 0},
+			Info:             &common.Tag{},
+		}
+	}
+
+	//LZO is only supported for reading
+	for _, toPages := range []func(*Table, int32, parquet.CompressionCodec) ([]*Page, int64, error){TableToDataPages, TableToDataPagesV2} {
+		_, _, err := toPages(newTable(), 1024, parquet.CompressionCodec_LZO)
+		assert.Error(t, err)
+		pages, _, err := toPages(newTable(), 1024, parquet.CompressionCodec_SNAPPY)
+		assert.NoError(t, err)
+		assert.Len(t, pages, 1)
+	}
+
+	dictRec := NewDictRec(pT)
+	_, _, err := TableToDictDataPages(dictRec, newTable(), 1024, 32, parquet.CompressionCodec_LZO)
+	assert.Error(t, err)
+	pages, _, err := TableToDictDataPages(dictRec, newTable(), 1024, 32, parquet.CompressionCodec_SNAPPY)
+	assert.NoError(t, err)
+	assert.Len(t, pages, 1)
+	_, _, err = DictRecToDictPage(dictRec, 1024, parquet.CompressionCodec_LZO)
+	assert.Error(t, err)
+	_, _, err = DictRecToDictPage(dictRec, 1024, parquet.CompressionCodec_SNAPPY)
+	assert.NoError(t, err)
+}
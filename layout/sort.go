@@ -0,0 +1,117 @@
+package layout
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/xitongsys/parquet-go/common"
+)
+
+//SortKey is a sort key of the rows of tables
+type SortKey struct {
+	//Path of a column which isn't repeated
+	Path       string
+	Descending bool
+	//Null values are before the other values
+	NullsFirst bool
+}
+
+//Sort the rows of the tables by the keys, the rows with equal keys keep their order
+func SortTables(tables map[string]*Table, keys []SortKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	keyValues := make([][]interface{}, len(keys))
+	funcTables := make([]common.FuncTable, len(keys))
+	numRows := -1
+	for i, key := range keys {
+		table, ok := tables[key.Path]
+		if !ok {
+			return fmt.Errorf("sort key %v isn't a column", key.Path)
+		}
+		if table.MaxRepetitionLevel > 0 {
+			return fmt.Errorf("sort key %v is repeated", key.Path)
+		}
+		keyValues[i] = table.values()
+		funcTables[i] = common.FindFuncTable(table.Schema.Type, table.Schema.ConvertedType, table.Schema.LogicalType)
+		if numRows >= 0 && len(keyValues[i]) != numRows {
+			return fmt.Errorf("sort key %v has %v rows instead of %v", key.Path, len(keyValues[i]), numRows)
+		}
+		numRows = len(keyValues[i])
+	}
+
+	rows := make([]int, numRows)
+	for i := range rows {
+		rows[i] = i
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		for i, key := range keys {
+			va, vb := keyValues[i][rows[a]], keyValues[i][rows[b]]
+			if va == nil || vb == nil {
+				if va == nil && vb == nil {
+					continue
+				}
+				return (va == nil) == key.NullsFirst
+			}
+			if funcTables[i].LessThan(va, vb) {
+				return !key.Descending
+			} else if funcTables[i].LessThan(vb, va) {
+				return key.Descending
+			}
+		}
+		return false
+	})
+
+	for path, table := range tables {
+		if err := table.permuteRows(rows); err != nil {
+			return fmt.Errorf("column %v: %v", path, err)
+		}
+	}
+	return nil
+}
+
+//Reorder the rows of the table, the row i is the row rows[i] of the table
+func (t *Table) permuteRows(rows []int) error {
+	//levels and values of the rows, which start with a repetition level 0
+	var levelStarts, valueStarts []int
+	numValues := 0
+	for i, rl := range t.RepetitionLevels {
+		if rl == 0 {
+			levelStarts = append(levelStarts, i)
+			valueStarts = append(valueStarts, numValues)
+		}
+		if t.DefinitionLevels[i] == t.MaxDefinitionLevel {
+			numValues++
+		}
+	}
+	if len(levelStarts) != len(rows) {
+		return fmt.Errorf("%v rows instead of %v", len(levelStarts), len(rows))
+	}
+	levelStarts = append(levelStarts, len(t.RepetitionLevels))
+	valueStarts = append(valueStarts, numValues)
+
+	repetitionLevels := make([]int32, 0, len(t.RepetitionLevels))
+	definitionLevels := make([]int32, 0, len(t.DefinitionLevels))
+	var values []interface{}
+	var indexes []int64
+	for _, row := range rows {
+		begin, end := levelStarts[row], levelStarts[row+1]
+		repetitionLevels = append(repetitionLevels, t.RepetitionLevels[begin:end]...)
+		definitionLevels = append(definitionLevels, t.DefinitionLevels[begin:end]...)
+		if t.Vector == nil {
+			values = append(values, t.Values[begin:end]...)
+			continue
+		}
+		for i := valueStarts[row]; i < valueStarts[row+1]; i++ {
+			indexes = append(indexes, int64(i))
+		}
+	}
+
+	t.RepetitionLevels, t.DefinitionLevels = repetitionLevels, definitionLevels
+	if t.Vector != nil {
+		t.Vector = t.Vector.Take(indexes)
+	} else {
+		t.Values = values
+	}
+	return nil
+}
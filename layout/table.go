@@ -39,6 +39,9 @@ type Table struct {
 
 	//Parquet values
 	Values []interface{}
+	//Non-null values stored with their physical type. Values is nil when it's set,
+	//use ToValues to convert it for the code working on Values.
+	Vector ColumnVector
 	//Definition Levels slice
 	DefinitionLevels []int32
 	//Repetition Levels slice
@@ -48,17 +51,52 @@ type Table struct {
 	Info *common.Tag
 }
 
-//Merge several tables to one table(the first table)
+//Convert Vector to Values
+func (t *Table) ToValues() {
+	if t.Vector == nil {
+		return
+	}
+	t.Values = vectorToValues(t.Vector, t.DefinitionLevels, t.MaxDefinitionLevel)
+	t.Vector = nil
+}
+
+//Values of the table, converted from Vector if it's set
+func (t *Table) values() []interface{} {
+	if t.Vector == nil {
+		return t.Values
+	}
+	return vectorToValues(t.Vector, t.DefinitionLevels, t.MaxDefinitionLevel)
+}
+
+//Merge several tables to one table(the first table).
+//Vector is kept if all the non-empty tables have one, otherwise they are merged to Values.
 func (t *Table) Merge(tables ...*Table) {
 	ln := len(tables)
 	if ln <= 0 {
 		return
 	}
+	vector := t.Vector != nil || len(t.DefinitionLevels) == 0
+	for i := 0; i < ln; i++ {
+		if tables[i] != nil && tables[i].Vector == nil && len(tables[i].DefinitionLevels) > 0 {
+			vector = false
+		}
+	}
+	if !vector {
+		t.ToValues()
+	}
+
 	for i := 0; i < ln; i++ {
 		if tables[i] == nil {
 			continue
 		}
-		t.Values = append(t.Values, tables[i].Values...)
+		if !vector {
+			t.Values = append(t.Values, tables[i].values()...)
+		} else if tables[i].Vector != nil {
+			if t.Vector == nil {
+				t.Vector = NewColumnVector(tables[i].Vector.Type(), tables[i].Vector.Len())
+			}
+			t.Vector.Append(tables[i].Vector)
+		}
 		t.RepetitionLevels = append(t.RepetitionLevels, tables[i].RepetitionLevels...)
 		t.DefinitionLevels = append(t.DefinitionLevels, tables[i].DefinitionLevels...)
 		if tables[i].MaxDefinitionLevel > t.MaxDefinitionLevel {
@@ -74,6 +112,9 @@ func (t *Table) Pop(numRows int64) *Table {
 	res := NewTableFromTable(t)
 	endIndex := int64(0)
 	ln := int64(len(t.Values))
+	if t.Vector != nil {
+		ln = int64(len(t.DefinitionLevels))
+	}
 	i, num := int64(0), int64(-1)
 	for i = 0; i < ln; i++ {
 		if t.RepetitionLevels[i] == 0 {
@@ -93,11 +134,24 @@ func (t *Table) Pop(numRows int64) *Table {
 
 	res.RepetitionLevels = t.RepetitionLevels[:endIndex]
 	res.DefinitionLevels = t.DefinitionLevels[:endIndex]
-	res.Values = t.Values[:endIndex]
+	if t.Vector != nil {
+		//the values of the vector are the levels equal to the maximum of the column
+		res.MaxDefinitionLevel = t.MaxDefinitionLevel
+		numValues := 0
+		for _, dl := range res.DefinitionLevels {
+			if dl == t.MaxDefinitionLevel {
+				numValues++
+			}
+		}
+		res.Vector = t.Vector.Slice(0, numValues)
+		t.Vector = t.Vector.Slice(numValues, t.Vector.Len())
+	} else {
+		res.Values = t.Values[:endIndex]
+		t.Values = t.Values[endIndex:]
+	}
 
 	t.RepetitionLevels = t.RepetitionLevels[endIndex:]
 	t.DefinitionLevels = t.DefinitionLevels[endIndex:]
-	t.Values = t.Values[endIndex:]
 
 	return res
 }
@@ -3,6 +3,8 @@ package layout
 import (
 	"fmt"
 	"testing"
+
+	"github.com/xitongsys/parquet-go/parquet"
 )
 
 func TestMergeTable(t *testing.T) {
@@ -22,3 +24,76 @@ func TestMergeTable(t *testing.T) {
 		t.Errorf("MergeTable err")
 	}
 }
+
+func TestVectorTable(t *testing.T) {
+	newVectorTable := func(values []int32, rls, dls []int32) *Table {
+		table := NewEmptyTable()
+		table.MaxDefinitionLevel, table.MaxRepetitionLevel = 1, 1
+		table.Vector = &Int32Vector{Values: values}
+		table.RepetitionLevels, table.DefinitionLevels = rls, dls
+		return table
+	}
+
+	table := newVectorTable([]int32{1, 2}, []int32{0, 1, 0}, []int32{1, 1, 0})
+	table.Merge(newVectorTable([]int32{3}, []int32{0, 0}, []int32{0, 1}))
+	if table.Vector == nil || fmt.Sprintf("%v", table.Vector.(*Int32Vector).Values) != "[1 2 3]" {
+		t.Errorf("Merge vector err, get %v", table.Vector)
+	}
+
+	res := table.Pop(2)
+	if fmt.Sprintf("%v", res.Vector.(*Int32Vector).Values) != "[1 2]" ||
+		fmt.Sprintf("%v", res.DefinitionLevels) != "[1 1 0]" ||
+		fmt.Sprintf("%v", table.Vector.(*Int32Vector).Values) != "[3]" ||
+		fmt.Sprintf("%v", table.DefinitionLevels) != "[0 1]" {
+		t.Errorf("Pop vector err, get %v %v", res.Vector, table.Vector)
+	}
+
+	res.ToValues()
+	if res.Vector != nil || fmt.Sprintf("%v", res.Values) != "[1 2 <nil>]" {
+		t.Errorf("ToValues err, get %v", res.Values)
+	}
+
+	// tables with Values turn the result into Values
+	table.Merge(res)
+	if table.Vector != nil || fmt.Sprintf("%v", table.Values) != "[<nil> 3 1 2 <nil>]" {
+		t.Errorf("Merge values err, get %v", table.Values)
+	}
+}
+
+func TestSortTables(t *testing.T) {
+	int32Type, stringType := parquet.Type_INT32, parquet.Type_BYTE_ARRAY
+	//rows of the key column: 2, nil, 1, 2
+	key := NewEmptyTable()
+	key.Schema = &parquet.SchemaElement{Type: &int32Type}
+	key.MaxDefinitionLevel = 1
+	key.Vector = &Int32Vector{Values: []int32{2, 1, 2}}
+	key.RepetitionLevels, key.DefinitionLevels = []int32{0, 0, 0, 0}, []int32{1, 0, 1, 1}
+	//rows of the repeated column: [a b], [], [c], [d]
+	list := NewEmptyTable()
+	list.Schema = &parquet.SchemaElement{Type: &stringType}
+	list.MaxDefinitionLevel, list.MaxRepetitionLevel = 1, 1
+	list.Values = []interface{}{"a", "b", nil, "c", "d"}
+	list.RepetitionLevels, list.DefinitionLevels = []int32{0, 1, 0, 0, 0}, []int32{1, 1, 0, 1, 1}
+
+	tables := map[string]*Table{"key": key, "list": list}
+	if err := SortTables(tables, []SortKey{{Path: "key", Descending: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprintf("%v", key.Vector.(*Int32Vector).Values) != "[2 2 1]" ||
+		fmt.Sprintf("%v", key.DefinitionLevels) != "[1 1 1 0]" ||
+		fmt.Sprintf("%v", list.Values) != "[a b d c <nil>]" ||
+		fmt.Sprintf("%v", list.RepetitionLevels) != "[0 1 0 0 0]" {
+		t.Errorf("SortTables err, get %v %v", key.Vector, list.Values)
+	}
+
+	if err := SortTables(tables, []SortKey{{Path: "key", NullsFirst: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprintf("%v", list.Values) != "[<nil> c a b d]" {
+		t.Errorf("SortTables nulls first err, get %v", list.Values)
+	}
+
+	if err := SortTables(tables, []SortKey{{Path: "list"}}); err == nil {
+		t.Errorf("SortTables should fail with a repeated key")
+	}
+}
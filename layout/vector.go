@@ -0,0 +1,357 @@
+package layout
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/encoding"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+//ColumnVector stores the non-null values of a column with their physical type,
+//so that they aren't boxed in interface{}. The value i is the value of the
+//i-th definition level equal to the maximum definition level of the table.
+type ColumnVector interface {
+	//Physical type of the values
+	Type() parquet.Type
+	//Number of values
+	Len() int
+	//Value i boxed in an interface{}
+	Value(i int) interface{}
+	//Size of the value i, used to split the pages
+	Size(i int) int32
+	//Append a value of the physical type boxed in an interface{}
+	AppendValue(val interface{})
+	//Append the values of a vector of the same type
+	Append(src ColumnVector)
+	//Values from i to j, sharing the storage of the vector
+	Slice(i, j int) ColumnVector
+	//Values at the indexes, used to decode the dictionary encoded pages
+	Take(indexes []int64) ColumnVector
+}
+
+//Create an empty vector for the values of a physical type
+func NewColumnVector(pT parquet.Type, capacity int) ColumnVector {
+	switch pT {
+	case parquet.Type_BOOLEAN:
+		return &BooleanVector{Values: make([]bool, 0, capacity)}
+	case parquet.Type_INT32:
+		return &Int32Vector{Values: make([]int32, 0, capacity)}
+	case parquet.Type_INT64:
+		return &Int64Vector{Values: make([]int64, 0, capacity)}
+	case parquet.Type_FLOAT:
+		return &FloatVector{Values: make([]float32, 0, capacity)}
+	case parquet.Type_DOUBLE:
+		return &DoubleVector{Values: make([]float64, 0, capacity)}
+	default:
+		return &ByteArrayVector{PhysicalType: pT, Values: make([]string, 0, capacity)}
+	}
+}
+
+//BooleanVector stores BOOLEAN values
+type BooleanVector struct {
+	Values []bool
+}
+
+func (v *BooleanVector) Type() parquet.Type          { return parquet.Type_BOOLEAN }
+func (v *BooleanVector) Len() int                    { return len(v.Values) }
+func (v *BooleanVector) Value(i int) interface{}     { return v.Values[i] }
+func (v *BooleanVector) Size(i int) int32            { return 1 }
+func (v *BooleanVector) AppendValue(val interface{}) { v.Values = append(v.Values, val.(bool)) }
+func (v *BooleanVector) Append(src ColumnVector) {
+	v.Values = append(v.Values, src.(*BooleanVector).Values...)
+}
+func (v *BooleanVector) Slice(i, j int) ColumnVector {
+	return &BooleanVector{Values: v.Values[i:j:j]}
+}
+func (v *BooleanVector) Take(indexes []int64) ColumnVector {
+	res := make([]bool, len(indexes))
+	for i, index := range indexes {
+		res[i] = v.Values[index]
+	}
+	return &BooleanVector{Values: res}
+}
+
+//Int32Vector stores INT32 values
+type Int32Vector struct {
+	Values []int32
+}
+
+func (v *Int32Vector) Type() parquet.Type          { return parquet.Type_INT32 }
+func (v *Int32Vector) Len() int                    { return len(v.Values) }
+func (v *Int32Vector) Value(i int) interface{}     { return v.Values[i] }
+func (v *Int32Vector) Size(i int) int32            { return 4 }
+func (v *Int32Vector) AppendValue(val interface{}) { v.Values = append(v.Values, val.(int32)) }
+func (v *Int32Vector) Append(src ColumnVector) {
+	v.Values = append(v.Values, src.(*Int32Vector).Values...)
+}
+func (v *Int32Vector) Slice(i, j int) ColumnVector {
+	return &Int32Vector{Values: v.Values[i:j:j]}
+}
+func (v *Int32Vector) Take(indexes []int64) ColumnVector {
+	res := make([]int32, len(indexes))
+	for i, index := range indexes {
+		res[i] = v.Values[index]
+	}
+	return &Int32Vector{Values: res}
+}
+
+//Int64Vector stores INT64 values
+type Int64Vector struct {
+	Values []int64
+}
+
+func (v *Int64Vector) Type() parquet.Type          { return parquet.Type_INT64 }
+func (v *Int64Vector) Len() int                    { return len(v.Values) }
+func (v *Int64Vector) Value(i int) interface{}     { return v.Values[i] }
+func (v *Int64Vector) Size(i int) int32            { return 8 }
+func (v *Int64Vector) AppendValue(val interface{}) { v.Values = append(v.Values, val.(int64)) }
+func (v *Int64Vector) Append(src ColumnVector) {
+	v.Values = append(v.Values, src.(*Int64Vector).Values...)
+}
+func (v *Int64Vector) Slice(i, j int) ColumnVector {
+	return &Int64Vector{Values: v.Values[i:j:j]}
+}
+func (v *Int64Vector) Take(indexes []int64) ColumnVector {
+	res := make([]int64, len(indexes))
+	for i, index := range indexes {
+		res[i] = v.Values[index]
+	}
+	return &Int64Vector{Values: res}
+}
+
+//FloatVector stores FLOAT values
+type FloatVector struct {
+	Values []float32
+}
+
+func (v *FloatVector) Type() parquet.Type          { return parquet.Type_FLOAT }
+func (v *FloatVector) Len() int                    { return len(v.Values) }
+func (v *FloatVector) Value(i int) interface{}     { return v.Values[i] }
+func (v *FloatVector) Size(i int) int32            { return 4 }
+func (v *FloatVector) AppendValue(val interface{}) { v.Values = append(v.Values, val.(float32)) }
+func (v *FloatVector) Append(src ColumnVector) {
+	v.Values = append(v.Values, src.(*FloatVector).Values...)
+}
+func (v *FloatVector) Slice(i, j int) ColumnVector {
+	return &FloatVector{Values: v.Values[i:j:j]}
+}
+func (v *FloatVector) Take(indexes []int64) ColumnVector {
+	res := make([]float32, len(indexes))
+	for i, index := range indexes {
+		res[i] = v.Values[index]
+	}
+	return &FloatVector{Values: res}
+}
+
+//DoubleVector stores DOUBLE values
+type DoubleVector struct {
+	Values []float64
+}
+
+func (v *DoubleVector) Type() parquet.Type          { return parquet.Type_DOUBLE }
+func (v *DoubleVector) Len() int                    { return len(v.Values) }
+func (v *DoubleVector) Value(i int) interface{}     { return v.Values[i] }
+func (v *DoubleVector) Size(i int) int32            { return 8 }
+func (v *DoubleVector) AppendValue(val interface{}) { v.Values = append(v.Values, val.(float64)) }
+func (v *DoubleVector) Append(src ColumnVector) {
+	v.Values = append(v.Values, src.(*DoubleVector).Values...)
+}
+func (v *DoubleVector) Slice(i, j int) ColumnVector {
+	return &DoubleVector{Values: v.Values[i:j:j]}
+}
+func (v *DoubleVector) Take(indexes []int64) ColumnVector {
+	res := make([]float64, len(indexes))
+	for i, index := range indexes {
+		res[i] = v.Values[index]
+	}
+	return &DoubleVector{Values: res}
+}
+
+//ByteArrayVector stores BYTE_ARRAY, FIXED_LEN_BYTE_ARRAY and INT96 values
+type ByteArrayVector struct {
+	PhysicalType parquet.Type
+	Values       []string
+}
+
+func (v *ByteArrayVector) Type() parquet.Type          { return v.PhysicalType }
+func (v *ByteArrayVector) Len() int                    { return len(v.Values) }
+func (v *ByteArrayVector) Value(i int) interface{}     { return v.Values[i] }
+func (v *ByteArrayVector) Size(i int) int32            { return int32(len(v.Values[i])) }
+func (v *ByteArrayVector) AppendValue(val interface{}) { v.Values = append(v.Values, val.(string)) }
+func (v *ByteArrayVector) Append(src ColumnVector) {
+	v.Values = append(v.Values, src.(*ByteArrayVector).Values...)
+}
+func (v *ByteArrayVector) Slice(i, j int) ColumnVector {
+	return &ByteArrayVector{PhysicalType: v.PhysicalType, Values: v.Values[i:j:j]}
+}
+func (v *ByteArrayVector) Take(indexes []int64) ColumnVector {
+	res := make([]string, len(indexes))
+	for i, index := range indexes {
+		res[i] = v.Values[index]
+	}
+	return &ByteArrayVector{PhysicalType: v.PhysicalType, Values: res}
+}
+
+//Box the values of a vector in interface{}
+func vectorValues(vector ColumnVector) []interface{} {
+	res := make([]interface{}, vector.Len())
+	for i := range res {
+		res[i] = vector.Value(i)
+	}
+	return res
+}
+
+//Convert the values of a vector to the Values of a table with its definition levels
+func vectorToValues(vector ColumnVector, definitionLevels []int32, maxDefinitionLevel int32) []interface{} {
+	res := make([]interface{}, len(definitionLevels))
+	j := 0
+	for i, dl := range definitionLevels {
+		if dl == maxDefinitionLevel {
+			res[i] = vector.Value(j)
+			j++
+		}
+	}
+	return res
+}
+
+//Get the minimum and maximum values of a vector, compared with the funcTable.
+//The natural orders of the types are compared without boxing the values.
+func vectorMinMax(vector ColumnVector, funcTable common.FuncTable) (interface{}, interface{}) {
+	if vector.Len() == 0 {
+		return nil, nil
+	}
+	pT := vector.Type()
+	natural := pT != parquet.Type_INT96 && funcTable == common.FindFuncTable(&pT, nil, nil)
+	switch v := vector.(type) {
+	case *Int32Vector:
+		if natural {
+			minVal, maxVal := v.Values[0], v.Values[0]
+			for _, val := range v.Values {
+				if !(minVal < val) {
+					minVal = val
+				}
+				if maxVal < val {
+					maxVal = val
+				}
+			}
+			return minVal, maxVal
+		}
+	case *Int64Vector:
+		if natural {
+			minVal, maxVal := v.Values[0], v.Values[0]
+			for _, val := range v.Values {
+				if !(minVal < val) {
+					minVal = val
+				}
+				if maxVal < val {
+					maxVal = val
+				}
+			}
+			return minVal, maxVal
+		}
+	case *FloatVector:
+		if natural {
+			minVal, maxVal := v.Values[0], v.Values[0]
+			for _, val := range v.Values {
+				if !(minVal < val) {
+					minVal = val
+				}
+				if maxVal < val {
+					maxVal = val
+				}
+			}
+			return minVal, maxVal
+		}
+	case *DoubleVector:
+		if natural {
+			minVal, maxVal := v.Values[0], v.Values[0]
+			for _, val := range v.Values {
+				if !(minVal < val) {
+					minVal = val
+				}
+				if maxVal < val {
+					maxVal = val
+				}
+			}
+			return minVal, maxVal
+		}
+	case *ByteArrayVector:
+		if natural {
+			minVal, maxVal := v.Values[0], v.Values[0]
+			for _, val := range v.Values {
+				if !(minVal < val) {
+					minVal = val
+				}
+				if maxVal < val {
+					maxVal = val
+				}
+			}
+			return minVal, maxVal
+		}
+	}
+
+	var minVal, maxVal interface{}
+	for i := 0; i < vector.Len(); i++ {
+		minVal, maxVal, _ = funcTable.MinMaxSize(minVal, maxVal, vector.Value(i))
+	}
+	return minVal, maxVal
+}
+
+//Read cnt values encoded with PLAIN to a vector
+func ReadPlainVector(bytesReader *bytes.Reader, dataType parquet.Type, cnt uint64, bitWidth uint64) (ColumnVector, error) {
+	switch dataType {
+	case parquet.Type_BOOLEAN:
+		values, err := encoding.ReadPlainBools(bytesReader, cnt)
+		return &BooleanVector{Values: values}, err
+	case parquet.Type_INT32:
+		values, err := encoding.ReadPlainInt32s(bytesReader, cnt)
+		return &Int32Vector{Values: values}, err
+	case parquet.Type_INT64:
+		values, err := encoding.ReadPlainInt64s(bytesReader, cnt)
+		return &Int64Vector{Values: values}, err
+	case parquet.Type_INT96:
+		values, err := encoding.ReadPlainFixedLenByteArrays(bytesReader, cnt, 12)
+		return &ByteArrayVector{PhysicalType: dataType, Values: values}, err
+	case parquet.Type_FLOAT:
+		values, err := encoding.ReadPlainFloat32s(bytesReader, cnt)
+		return &FloatVector{Values: values}, err
+	case parquet.Type_DOUBLE:
+		values, err := encoding.ReadPlainFloat64s(bytesReader, cnt)
+		return &DoubleVector{Values: values}, err
+	case parquet.Type_BYTE_ARRAY:
+		values, err := encoding.ReadPlainByteArrays(bytesReader, cnt)
+		return &ByteArrayVector{PhysicalType: dataType, Values: values}, err
+	case parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		values, err := encoding.ReadPlainFixedLenByteArrays(bytesReader, cnt, bitWidth)
+		return &ByteArrayVector{PhysicalType: dataType, Values: values}, err
+	default:
+		return nil, fmt.Errorf("Unknown parquet type")
+	}
+}
+
+//Read data page values to a vector. The indexes of the dictionary encodings are
+//read to an Int64Vector, which is replaced by the values in Decode.
+//It returns a nil vector if the encoding isn't read to vectors, so that
+//ReadDataPageValues is used instead.
+func ReadDataPageVector(bytesReader *bytes.Reader, encodingMethod parquet.Encoding, dataType parquet.Type, cnt uint64, bitWidth uint64) (ColumnVector, error) {
+	if encodingMethod == parquet.Encoding_PLAIN {
+		return ReadPlainVector(bytesReader, dataType, cnt, bitWidth)
+
+	} else if encodingMethod == parquet.Encoding_PLAIN_DICTIONARY || encodingMethod == parquet.Encoding_RLE_DICTIONARY {
+		indexes := &Int64Vector{Values: make([]int64, cnt)}
+		if cnt <= 0 {
+			return indexes, nil
+		}
+		values, err := ReadDataPageValues(bytesReader, encodingMethod, dataType, -1, cnt, bitWidth)
+		if err != nil {
+			return nil, err
+		}
+		for i := range indexes.Values {
+			indexes.Values[i] = values[i].(int64)
+		}
+		return indexes, nil
+	}
+	return nil, nil
+}
@@ -29,7 +29,7 @@ func MarshalJSON(ss []interface{}, schemaHandler *schema.SchemaHandler) (tb *map
 		}
 	}()
 
-	res := setupTableMap(schemaHandler, len(ss))
+	res := setupTableMap(schemaHandler, len(ss), false)
 	pathMap := schemaHandler.PathMap
 	nodeBuf := NewNodeBuf(1)
 
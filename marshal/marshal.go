@@ -239,7 +239,7 @@ func Marshal(srcInterface []interface{}, schemaHandler *schema.SchemaHandler) (t
 	}()
 
 	src := reflect.ValueOf(srcInterface)
-	res := setupTableMap(schemaHandler, len(srcInterface))
+	res := setupTableMap(schemaHandler, len(srcInterface), false)
 	pathMap := schemaHandler.PathMap
 	nodeBuf := NewNodeBuf(1)
 
@@ -342,7 +342,8 @@ func Marshal(srcInterface []interface{}, schemaHandler *schema.SchemaHandler) (t
 	return &res, nil
 }
 
-func setupTableMap(schemaHandler *schema.SchemaHandler, numElements int) map[string]*layout.Table {
+//Create the tables of the leaves, their values are stored in vectors if vector is true
+func setupTableMap(schemaHandler *schema.SchemaHandler, numElements int, vector bool) map[string]*layout.Table {
 	tableMap := make(map[string]*layout.Table)
 	for i := 0; i < len(schemaHandler.SchemaElements); i++ {
 		schema := schemaHandler.SchemaElements[i]
@@ -357,7 +358,11 @@ func setupTableMap(schemaHandler *schema.SchemaHandler, numElements int) map[str
 			table.Schema = schemaHandler.SchemaElements[schemaHandler.MapIndex[pathStr]]
 			table.Info = schemaHandler.Infos[i]
 			// Pre-size tables under the assumption that they'll be filled.
-			table.Values = make([]interface{}, 0, numElements)
+			if vector {
+				table.Vector = layout.NewColumnVector(schema.GetType(), numElements)
+			} else {
+				table.Values = make([]interface{}, 0, numElements)
+			}
 			table.DefinitionLevels = make([]int32, 0, numElements)
 			table.RepetitionLevels = make([]int32, 0, numElements)
 			tableMap[pathStr] = table
@@ -9,6 +9,7 @@ import (
 	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xitongsys/parquet-go/schema"
 	"github.com/xitongsys/parquet-go/types"
+	"sync"
 	"unsafe"
 )
 
@@ -26,35 +27,29 @@ import (
 //
 // It does not support map-type fields. It should support every other use-case of Marshal.
 func MarshalFast(srcInterface []interface{}, schemaHandler *schema.SchemaHandler) (tb *map[string]*layout.Table, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			switch x := r.(type) {
-			case string:
-				err = errors.New(x)
-			case error:
-				err = x
-			default:
-				err = errors.New("unkown error")
-			}
-		}
-	}()
+	return newCompiler(schemaHandler).marshal(srcInterface)
+}
 
-	tableMap := setupTableMap(schemaHandler, len(srcInterface))
-	pathMap := schemaHandler.PathMap
+// FastMarshaler implements the MarshalFast function and reuses the compiled encoders
+// across calls instead of compiling them for every batch. It is safe for concurrent use,
+// which allows using its Marshal method as the MarshalFunc of a ParquetWriter.
+//
+// Like MarshalFast, it does not support map-type fields.
+type FastMarshaler struct {
+	compilers sync.Pool
+}
 
-	c := compiler{
-		encoderMap:    make(map[encoderMapKey]encoder),
-		tableMap:      tableMap,
-		schemaHandler: schemaHandler,
+// Marshal implements the MarshalFast function with the encoders cached by the marshaler.
+func (m *FastMarshaler) Marshal(srcInterface []interface{}, schemaHandler *schema.SchemaHandler) (tb *map[string]*layout.Table, err error) {
+	c, _ := m.compilers.Get().(*compiler)
+	if c == nil || c.schemaHandler != schemaHandler {
+		c = newCompiler(schemaHandler)
 	}
-
-	for _, v := range srcInterface {
-		typ, ptr := reflect.TypeAndPtrOf(v)
-		enc := c.getEncoder(typ, pathMap)
-		enc.encode(ptr, 0, 0)
+	if tb, err = c.marshal(srcInterface); err == nil {
+		// a compiler which panicked may be left in an inconsistent state
+		m.compilers.Put(c)
 	}
-
-	return &tableMap, nil
+	return tb, err
 }
 
 type encoder interface {
@@ -79,16 +74,59 @@ func (k encoderMapKey) String() string {
 // compiler handles compiling encoders and caching them so that they can be reused across
 // multiple objects.
 //
-// Currently, the caching is local to the compiler, which requires re-compiling on every
-// call to MarshalFast. We could cache across calls to MarshalFast, but this is
-// complicated by the need to synchronize across multiple goroutines, and re-compiling
-// doesn't comprise a significant portion of execution time when writing large files.
+// The caching is local to the compiler, which requires re-compiling on every call to
+// MarshalFast. FastMarshaler caches across calls by reusing compilers, one per goroutine,
+// since a compiler isn't safe for concurrent use.
 type compiler struct {
 	encoderMap    map[encoderMapKey]encoder
 	tableMap      map[string]*layout.Table
 	schemaHandler *schema.SchemaHandler
 }
 
+func newCompiler(schemaHandler *schema.SchemaHandler) *compiler {
+	return &compiler{
+		encoderMap:    make(map[encoderMapKey]encoder),
+		tableMap:      setupTableMap(schemaHandler, 0, true),
+		schemaHandler: schemaHandler,
+	}
+}
+
+// marshal encodes the objects into new tables. The compiled encoders point to the tables
+// of the compiler, so these are reset in place rather than replaced, and the results are
+// copies of them.
+func (c *compiler) marshal(srcInterface []interface{}) (tb *map[string]*layout.Table, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch x := r.(type) {
+			case string:
+				err = errors.New(x)
+			case error:
+				err = x
+			default:
+				err = errors.New("unkown error")
+			}
+		}
+	}()
+
+	for path, table := range setupTableMap(c.schemaHandler, len(srcInterface), true) {
+		*c.tableMap[path] = *table
+	}
+	pathMap := c.schemaHandler.PathMap
+
+	for _, v := range srcInterface {
+		typ, ptr := reflect.TypeAndPtrOf(v)
+		enc := c.getEncoder(typ, pathMap)
+		enc.encode(ptr, 0, 0)
+	}
+
+	tableMap := make(map[string]*layout.Table, len(c.tableMap))
+	for path, table := range c.tableMap {
+		res := *table
+		tableMap[path] = &res
+	}
+	return &tableMap, nil
+}
+
 // getEncoder returns an encoder for the type and path provided. It looks up a cached
 // encoder if one exists, otherwise compiles a new one and caches it.
 func (c *compiler) getEncoder(typ reflect.Type, pathMap *schema.PathMapType) encoder {
@@ -130,10 +168,12 @@ func (c *compiler) getSchema(path string) *parquet.SchemaElement {
 func (c *compiler) terminalEncoder(typ reflect.Type, pathMap *schema.PathMapType) terminalEncoder {
 	typeIface := reflect.Zero(typ).Interface()
 	path := pathMap.Path
+	pT := c.getSchema(path).Type
 	return terminalEncoder{
-		typeIface: typeIface,
-		table:     c.tableMap[path],
-		pT:        c.getSchema(path).Type,
+		typeIface:   typeIface,
+		table:       c.tableMap[path],
+		pT:          pT,
+		appendValue: vectorAppender(typ.Kind(), pT),
 	}
 }
 
@@ -207,14 +247,23 @@ func toIface(typeIface interface{}, ptr unsafe.Pointer) interface{} {
 
 // terminalEncoder handles encoding of terminal (leaf) values that don't have children.
 type terminalEncoder struct {
-	typeIface interface{}
-	table     *layout.Table
-	pT        *parquet.Type
+	typeIface   interface{}
+	table       *layout.Table
+	pT          *parquet.Type
+	appendValue func(vector layout.ColumnVector, ptr unsafe.Pointer)
 }
 
-// encode converts a pointer back to an interface of the correct type and appends it
-// to the table with definition-level and repetition-level.
+// encode appends the value to the vector of the table with definition-level and
+// repetition-level. If the value can't be appended to the vector, it converts the
+// pointer back to an interface of the correct type and writes it.
 func (e *terminalEncoder) encode(ptr unsafe.Pointer, dl, rl int32) {
+	if ptr != nil && e.appendValue != nil && e.table.Vector != nil && dl == e.table.MaxDefinitionLevel {
+		e.appendValue(e.table.Vector, ptr)
+		e.table.DefinitionLevels = append(e.table.DefinitionLevels, dl)
+		e.table.RepetitionLevels = append(e.table.RepetitionLevels, rl)
+		return
+	}
+
 	var v interface{}
 	if ptr != nil {
 		v = toIface(e.typeIface, ptr)
@@ -222,12 +271,102 @@ func (e *terminalEncoder) encode(ptr unsafe.Pointer, dl, rl int32) {
 	e.write(v, dl, rl)
 }
 
+// write appends a value to the table. Nulls only need the levels in the vector of
+// the table, other values make the table fall back to Values.
 func (e *terminalEncoder) write(v interface{}, dl, rl int32) {
-	e.table.Values = append(e.table.Values, types.InterfaceToParquetType(v, e.pT))
+	if e.table.Vector != nil && (v != nil || dl == e.table.MaxDefinitionLevel) {
+		e.table.ToValues()
+	}
+	if e.table.Vector == nil {
+		e.table.Values = append(e.table.Values, types.InterfaceToParquetType(v, e.pT))
+	}
 	e.table.DefinitionLevels = append(e.table.DefinitionLevels, dl)
 	e.table.RepetitionLevels = append(e.table.RepetitionLevels, rl)
 }
 
+// vectorAppender returns a function appending the value of kind at a pointer to a
+// vector of the physical type pT, converted as types.InterfaceToParquetType does.
+// It's nil if the kind isn't converted to pT without reflection.
+func vectorAppender(kind reflect.Kind, pT *parquet.Type) func(layout.ColumnVector, unsafe.Pointer) {
+	if pT == nil {
+		return nil
+	}
+	loadInt := intLoader(kind)
+	switch *pT {
+	case parquet.Type_BOOLEAN:
+		if kind == reflect.Bool {
+			return func(vector layout.ColumnVector, ptr unsafe.Pointer) {
+				v := vector.(*layout.BooleanVector)
+				v.Values = append(v.Values, *(*bool)(ptr))
+			}
+		}
+	case parquet.Type_INT32:
+		if loadInt != nil {
+			return func(vector layout.ColumnVector, ptr unsafe.Pointer) {
+				v := vector.(*layout.Int32Vector)
+				v.Values = append(v.Values, int32(loadInt(ptr)))
+			}
+		}
+	case parquet.Type_INT64:
+		if loadInt != nil {
+			return func(vector layout.ColumnVector, ptr unsafe.Pointer) {
+				v := vector.(*layout.Int64Vector)
+				v.Values = append(v.Values, loadInt(ptr))
+			}
+		}
+	case parquet.Type_FLOAT:
+		if kind == reflect.Float32 {
+			return func(vector layout.ColumnVector, ptr unsafe.Pointer) {
+				v := vector.(*layout.FloatVector)
+				v.Values = append(v.Values, *(*float32)(ptr))
+			}
+		} else if kind == reflect.Float64 {
+			return func(vector layout.ColumnVector, ptr unsafe.Pointer) {
+				v := vector.(*layout.FloatVector)
+				v.Values = append(v.Values, float32(*(*float64)(ptr)))
+			}
+		}
+	case parquet.Type_DOUBLE:
+		if kind == reflect.Float32 {
+			return func(vector layout.ColumnVector, ptr unsafe.Pointer) {
+				v := vector.(*layout.DoubleVector)
+				v.Values = append(v.Values, float64(*(*float32)(ptr)))
+			}
+		} else if kind == reflect.Float64 {
+			return func(vector layout.ColumnVector, ptr unsafe.Pointer) {
+				v := vector.(*layout.DoubleVector)
+				v.Values = append(v.Values, *(*float64)(ptr))
+			}
+		}
+	case parquet.Type_INT96, parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY:
+		if kind == reflect.String {
+			return func(vector layout.ColumnVector, ptr unsafe.Pointer) {
+				v := vector.(*layout.ByteArrayVector)
+				v.Values = append(v.Values, *(*string)(ptr))
+			}
+		}
+	}
+	return nil
+}
+
+// intLoader returns a function loading a signed integer of kind from a pointer,
+// or nil if kind isn't a signed integer.
+func intLoader(kind reflect.Kind) func(unsafe.Pointer) int64 {
+	switch kind {
+	case reflect.Int:
+		return func(ptr unsafe.Pointer) int64 { return int64(*(*int)(ptr)) }
+	case reflect.Int8:
+		return func(ptr unsafe.Pointer) int64 { return int64(*(*int8)(ptr)) }
+	case reflect.Int16:
+		return func(ptr unsafe.Pointer) int64 { return int64(*(*int16)(ptr)) }
+	case reflect.Int32:
+		return func(ptr unsafe.Pointer) int64 { return int64(*(*int32)(ptr)) }
+	case reflect.Int64:
+		return func(ptr unsafe.Pointer) int64 { return *(*int64)(ptr) }
+	}
+	return nil
+}
+
 // nilEncoder handles encoding of values known to be nil.
 type nilEncoder struct {
 	terminalEncoder
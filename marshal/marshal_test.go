@@ -190,6 +190,9 @@ func TestMarshalFast(t *testing.T) {
 			if err != nil {
 				t.Fatalf("%v", err)
 			}
+			for _, table := range *actual {
+				table.ToValues()
+			}
 			if !reflect.DeepEqual(expected, actual) {
 				// require.Equal(t, expected, actual)
 				t.Errorf("not equal")
@@ -197,3 +200,29 @@ func TestMarshalFast(t *testing.T) {
 		})
 	}
 }
+
+func TestFastMarshaler(t *testing.T) {
+	type testElem struct {
+		Int32  int32   `parquet:"name=int32, type=INT32"`
+		String *string `parquet:"name=string, type=BYTE_ARRAY"`
+	}
+	sch, err := schema.NewSchemaHandlerFromStruct(new(testElem))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	m, str := new(FastMarshaler), "a"
+	for i := 0; i < 3; i++ {
+		input := []interface{}{testElem{Int32: int32(i)}, testElem{Int32: int32(-i), String: &str}}
+		expected, err := MarshalFast(input, sch)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		actual, err := m.Marshal(input, sch)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("not equal")
+		}
+	}
+}
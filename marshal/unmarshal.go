@@ -4,6 +4,7 @@ import (
 	"errors"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/xitongsys/parquet-go/common"
 	"github.com/xitongsys/parquet-go/layout"
@@ -42,37 +43,9 @@ func Unmarshal(tableMap *map[string]*layout.Table, bgn int, end int, dstInterfac
 		}
 	}()
 
-	tableNeeds := make(map[string]*layout.Table)
-	tableBgn, tableEnd := make(map[string]int), make(map[string]int)
-	for name, table := range *tableMap {
-		if !strings.HasPrefix(name, prefixPath) {
-			continue
-		}
-
-		tableNeeds[name] = table
-
-		ln := len(table.Values)
-		num := -1
-		tableBgn[name], tableEnd[name] = -1, -1
-		for i := 0; i < ln; i++ {
-			if table.RepetitionLevels[i] == 0 {
-				num++
-				if num == bgn {
-					tableBgn[name] = i
-				}
-				if num == end {
-					tableEnd[name] = i
-					break
-				}
-			}
-		}
-
-		if tableEnd[name] < 0 {
-			tableEnd[name] = ln
-		}
-		if tableBgn[name] < 0 {
-			return
-		}
+	tableNeeds, tableBgn, tableEnd, ok := tableRanges(tableMap, bgn, end, prefixPath)
+	if !ok {
+		return
 	}
 
 	mapRecords := make(map[reflect.Value]*MapRecord)
@@ -112,8 +85,25 @@ func Unmarshal(tableMap *map[string]*layout.Table, bgn int, end int, dstInterfac
 		var prevSlicePo reflect.Value
 		var prevSliceRecord *SliceRecord
 
+		//index of the value of the row bgn in the vector of the table
+		vectorIndex := 0
+		if table.Vector != nil {
+			for _, dl := range table.DefinitionLevels[:bgn] {
+				if dl == table.MaxDefinitionLevel {
+					vectorIndex++
+				}
+			}
+		}
+
 		for i := bgn; i < end; i++ {
-			rl, dl, val := table.RepetitionLevels[i], table.DefinitionLevels[i], table.Values[i]
+			var val interface{}
+			rl, dl, valueIndex := table.RepetitionLevels[i], table.DefinitionLevels[i], -1
+			if table.Vector == nil {
+				val = table.Values[i]
+			} else if dl == table.MaxDefinitionLevel {
+				valueIndex = vectorIndex
+				vectorIndex++
+			}
 			po, index := root, prefixIndex
 		OuterLoop:
 			for index < len(path) {
@@ -229,12 +219,15 @@ func Unmarshal(tableMap *map[string]*layout.Table, bgn int, end int, dstInterfac
 					if prevType != poType || name != prevFieldName {
 						prevType = poType
 						prevFieldName = name
-						f, _ := prevType.FieldByName(name)
-						prevFieldIndex = f.Index
+						prevFieldIndex = fieldIndex(prevType, name)
 					}
 					po = po.FieldByIndex(prevFieldIndex)
 
 				default:
+					if valueIndex >= 0 {
+						setVectorValue(po, table.Vector, valueIndex)
+						break OuterLoop
+					}
 					value := reflect.ValueOf(val)
 					if po.Type() != value.Type() {
 						value = value.Convert(poType)
@@ -262,3 +255,113 @@ func Unmarshal(tableMap *map[string]*layout.Table, bgn int, end int, dstInterfac
 
 	return nil
 }
+
+//Get the tables of the paths with prefixPath and the ranges of their values of the rows [bgn, end).
+//It returns false if a table doesn't have the row bgn.
+func tableRanges(tableMap *map[string]*layout.Table, bgn int, end int, prefixPath string) (map[string]*layout.Table, map[string]int, map[string]int, bool) {
+	tableNeeds := make(map[string]*layout.Table)
+	tableBgn, tableEnd := make(map[string]int), make(map[string]int)
+	for name, table := range *tableMap {
+		if !strings.HasPrefix(name, prefixPath) {
+			continue
+		}
+
+		tableNeeds[name] = table
+
+		ln := len(table.Values)
+		if table.Vector != nil {
+			ln = len(table.DefinitionLevels)
+		}
+		num := -1
+		tableBgn[name], tableEnd[name] = -1, -1
+		for i := 0; i < ln; i++ {
+			if table.RepetitionLevels[i] == 0 {
+				num++
+				if num == bgn {
+					tableBgn[name] = i
+				}
+				if num == end {
+					tableEnd[name] = i
+					break
+				}
+			}
+		}
+
+		if tableEnd[name] < 0 {
+			tableEnd[name] = ln
+		}
+		if tableBgn[name] < 0 {
+			return nil, nil, nil, false
+		}
+	}
+	return tableNeeds, tableBgn, tableEnd, true
+}
+
+type fieldIndexKey struct {
+	typ  reflect.Type
+	name string
+}
+
+//Index of the struct fields by type and name, FieldByName is slow for the structs with many fields
+var fieldIndexes sync.Map
+
+//Index of the field name of the struct typ for FieldByIndex
+func fieldIndex(typ reflect.Type, name string) []int {
+	key := fieldIndexKey{typ, name}
+	if index, ok := fieldIndexes.Load(key); ok {
+		return index.([]int)
+	}
+	f, _ := typ.FieldByName(name)
+	fieldIndexes.Store(key, f.Index)
+	return f.Index
+}
+
+//Set the value i of a vector to po, without boxing it if the kind of po allows it
+func setVectorValue(po reflect.Value, vector layout.ColumnVector, i int) {
+	switch v := vector.(type) {
+	case *layout.BooleanVector:
+		if po.Kind() == reflect.Bool {
+			po.SetBool(v.Values[i])
+			return
+		}
+	case *layout.Int32Vector:
+		switch po.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			po.SetInt(int64(v.Values[i]))
+			return
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			po.SetUint(uint64(v.Values[i]))
+			return
+		}
+	case *layout.Int64Vector:
+		switch po.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			po.SetInt(v.Values[i])
+			return
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			po.SetUint(uint64(v.Values[i]))
+			return
+		}
+	case *layout.FloatVector:
+		if po.Kind() == reflect.Float32 || po.Kind() == reflect.Float64 {
+			po.SetFloat(float64(v.Values[i]))
+			return
+		}
+	case *layout.DoubleVector:
+		if po.Kind() == reflect.Float32 || po.Kind() == reflect.Float64 {
+			po.SetFloat(v.Values[i])
+			return
+		}
+	case *layout.ByteArrayVector:
+		if po.Kind() == reflect.String {
+			po.SetString(v.Values[i])
+			return
+		}
+	}
+
+	value := reflect.ValueOf(vector.Value(i))
+	if po.Type() != value.Type() {
+		value = value.Convert(po.Type())
+	}
+	po.Set(value)
+}
@@ -0,0 +1,465 @@
+package marshal
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/layout"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+)
+
+// UnmarshalFast implements the Unmarshal function while maximizing performance and
+// minimizing allocations.
+//
+// For each column, it uses reflection to compile a decoder from the type of the objects
+// and the path of the column. The decoder walks the objects through the offsets of the
+// struct fields and the headers of the slices with unsafe pointers, and writes the typed
+// values of the vectors of the tables without boxing them. It supports pointers, slices,
+// maps and nested structs, like Unmarshal.
+//
+// The decoders are compiled on every call, FastUnmarshaler caches them across calls.
+func UnmarshalFast(tableMap *map[string]*layout.Table, bgn int, end int, dstInterface interface{}, schemaHandler *schema.SchemaHandler, prefixPath string) (err error) {
+	return unmarshalFast(nil, tableMap, bgn, end, dstInterface, schemaHandler, prefixPath)
+}
+
+// FastUnmarshaler implements the UnmarshalFast function and caches the compiled decoders
+// by type of the objects and schema. It is safe for concurrent use, which allows using its
+// Unmarshal method as the UnmarshalFunc of a ParquetReader.
+type FastUnmarshaler struct {
+	decoders sync.Map
+}
+
+// Unmarshal implements the UnmarshalFast function with the decoders cached by the unmarshaler.
+func (u *FastUnmarshaler) Unmarshal(tableMap *map[string]*layout.Table, bgn int, end int, dstInterface interface{}, schemaHandler *schema.SchemaHandler, prefixPath string) (err error) {
+	return unmarshalFast(&u.decoders, tableMap, bgn, end, dstInterface, schemaHandler, prefixPath)
+}
+
+// decoderMapKey is the key used to lookup the compiled decoder of a column.
+type decoderMapKey struct {
+	typ           reflect.Type
+	schemaHandler *schema.SchemaHandler
+	prefixIndex   int
+	path          string
+}
+
+func unmarshalFast(decoders *sync.Map, tableMap *map[string]*layout.Table, bgn int, end int, dstInterface interface{}, schemaHandler *schema.SchemaHandler, prefixPath string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch x := r.(type) {
+			case string:
+				err = errors.New(x)
+			case error:
+				err = x
+			default:
+				err = errors.New("unknown error")
+			}
+		}
+	}()
+
+	tableNeeds, tableBgn, tableEnd, ok := tableRanges(tableMap, bgn, end, prefixPath)
+	if !ok {
+		return nil
+	}
+
+	root := reflect.ValueOf(dstInterface).Elem()
+	typ := root.Type()
+	if typ.Kind() != reflect.Slice {
+		return fmt.Errorf("unmarshal to %v, which isn't a pointer of slice", reflect.TypeOf(dstInterface))
+	}
+	// reserve the rows, the decoders append them in place
+	if root.Cap()-root.Len() < end-bgn {
+		rows := reflect.MakeSlice(typ, root.Len(), root.Len()+end-bgn)
+		reflect.Copy(rows, root)
+		root.Set(rows)
+	}
+
+	state := &decodeState{
+		rootLen:    root.Len(),
+		mapRecords: make(map[unsafe.Pointer]*mapRecord),
+	}
+	prefixIndex := common.PathStrIndex(prefixPath) - 1
+
+	for name, table := range tableNeeds {
+		var dec *columnDecoder
+		key := decoderMapKey{typ, schemaHandler, prefixIndex, name}
+		if decoders != nil {
+			if cached, ok := decoders.Load(key); ok {
+				dec = cached.(*columnDecoder)
+			}
+		}
+		if dec == nil {
+			if dec, err = compileColumnDecoder(typ, table.Path, prefixIndex, schemaHandler); err != nil {
+				return err
+			}
+			if decoders != nil {
+				decoders.Store(key, dec)
+			}
+		}
+		dec.decode(state, unsafe.Pointer(root.UnsafeAddr()), table, tableBgn[name], tableEnd[name])
+	}
+
+	for i := len(state.mapRecordsStack) - 1; i >= 0; i-- {
+		rec := state.mapRecordsStack[i]
+		for j := range rec.keys {
+			rec.m.SetMapIndex(rec.keys[j].Elem(), rec.values[j].Elem())
+		}
+	}
+
+	return nil
+}
+
+type decodeStepKind int
+
+const (
+	decodeSlice decodeStepKind = iota
+	decodeMap
+	decodePointer
+	decodeField
+)
+
+// decodeStep is one step of the walk from the root slice to the value of a column.
+// The walk stops at the step when the definition level of the value is less than
+// preDefinitionLevel, or less than postDefinitionLevel after the element is selected.
+type decodeStep struct {
+	kind                decodeStepKind
+	typ                 reflect.Type
+	zero                reflect.Value
+	elemSize            uintptr
+	offset              uintptr
+	key                 bool
+	repetitionLevel     int32
+	preDefinitionLevel  int32
+	postDefinitionLevel int32
+}
+
+// columnDecoder decodes the values of a column to the objects. It's immutable once
+// compiled, the state of the walk is kept by decode.
+type columnDecoder struct {
+	steps []decodeStep
+	//type of the values, nil if the column isn't a field of the objects
+	leaf reflect.Type
+}
+
+// compileColumnDecoder compiles the decoder of the column with path to objects of the slice
+// type typ, walking the schema as Unmarshal does.
+func compileColumnDecoder(typ reflect.Type, path []string, prefixIndex int, schemaHandler *schema.SchemaHandler) (*columnDecoder, error) {
+	repetitionLevels, definitionLevels := make([]int32, len(path)), make([]int32, len(path))
+	for i := 0; i < len(path); i++ {
+		repetitionLevels[i], _ = schemaHandler.MaxRepetitionLevel(path[:i+1])
+		definitionLevels[i], _ = schemaHandler.MaxDefinitionLevel(path[:i+1])
+	}
+	errMismatch := fmt.Errorf("type %v doesn't match the column %v", typ, common.PathToStr(path))
+
+	dec := new(columnDecoder)
+	index := prefixIndex
+	for index < len(path) {
+		schemaIndex := schemaHandler.MapIndex[common.PathToStr(path[:index+1])]
+		cT := schemaHandler.SchemaElements[schemaIndex].ConvertedType
+
+		step := decodeStep{typ: typ}
+		switch typ.Kind() {
+		case reflect.Slice:
+			step.kind = decodeSlice
+			step.zero = reflect.Zero(typ.Elem())
+			step.elemSize = typ.Elem().Size()
+			cTIsList := cT != nil && *cT == parquet.ConvertedType_LIST
+			if cTIsList {
+				if index++; index >= len(path) {
+					return nil, errMismatch
+				}
+				step.preDefinitionLevel = definitionLevels[index]
+			}
+			step.repetitionLevel = repetitionLevels[index]
+			if cTIsList {
+				if index++; index >= len(path) {
+					return nil, errMismatch
+				}
+				step.postDefinitionLevel = definitionLevels[index]
+			}
+			typ = typ.Elem()
+
+		case reflect.Map:
+			step.kind = decodeMap
+			if index += 2; index >= len(path) {
+				return nil, errMismatch
+			}
+			step.preDefinitionLevel = definitionLevels[index-1]
+			step.repetitionLevel = repetitionLevels[index-1]
+			step.postDefinitionLevel = definitionLevels[index]
+			if step.key = strings.ToLower(path[index]) == "key"; step.key {
+				typ = typ.Key()
+			} else {
+				typ = typ.Elem()
+			}
+
+		case reflect.Ptr:
+			step.kind = decodePointer
+			typ = typ.Elem()
+
+		case reflect.Struct:
+			if index++; index >= len(path) {
+				return nil, errMismatch
+			}
+			f, ok := typ.FieldByName(path[index])
+			if !ok {
+				//the column isn't read to the objects
+				return &columnDecoder{}, nil
+			}
+			step.kind = decodeField
+			step.preDefinitionLevel = definitionLevels[index]
+			//promoted fields of embedded structs
+			for i, fi := range f.Index {
+				sf := typ.Field(fi)
+				step.offset += sf.Offset
+				typ = sf.Type
+				if i < len(f.Index)-1 && typ.Kind() == reflect.Ptr {
+					dec.steps = append(dec.steps, step, decodeStep{kind: decodePointer, typ: typ})
+					step = decodeStep{kind: decodeField}
+					typ = typ.Elem()
+				}
+			}
+
+		default:
+			dec.leaf = typ
+			return dec, nil
+		}
+		dec.steps = append(dec.steps, step)
+	}
+	return dec, nil
+}
+
+// decodeState is the state of an unmarshal call, shared by the decoders of the columns.
+type decodeState struct {
+	//length of the root slice before the call, the rows are appended after it
+	rootLen         int
+	mapRecords      map[unsafe.Pointer]*mapRecord
+	mapRecordsStack []*mapRecord
+}
+
+// mapRecord keeps the entries of a map until all the columns are decoded, since the
+// entries of a map aren't addressable.
+type mapRecord struct {
+	m      reflect.Value
+	keys   []reflect.Value
+	values []reflect.Value
+}
+
+// stepState is the element selected by a step for the current slice or map.
+type stepState struct {
+	ptr    unsafe.Pointer
+	index  int
+	record *mapRecord
+}
+
+type sliceHeader struct {
+	data unsafe.Pointer
+	len  int
+	cap  int
+}
+
+func (d *columnDecoder) decode(state *decodeState, root unsafe.Pointer, table *layout.Table, bgn int, end int) {
+	if d.leaf == nil {
+		return
+	}
+	setValue := valueSetter(table, d.leaf)
+
+	//index of the value of the row bgn in the vector of the table
+	vectorIndex := 0
+	if table.Vector != nil {
+		for _, dl := range table.DefinitionLevels[:bgn] {
+			if dl == table.MaxDefinitionLevel {
+				vectorIndex++
+			}
+		}
+	}
+
+	steps := make([]stepState, len(d.steps))
+	for i := bgn; i < end; i++ {
+		rl, dl, valueIndex := table.RepetitionLevels[i], table.DefinitionLevels[i], i
+		if table.Vector != nil {
+			valueIndex = -1
+			if dl == table.MaxDefinitionLevel {
+				valueIndex = vectorIndex
+				vectorIndex++
+			}
+		}
+
+		if ptr, ok := d.walk(state, steps, root, rl, dl); ok && valueIndex >= 0 {
+			setValue(ptr, valueIndex)
+		}
+	}
+}
+
+// walk follows the steps from the root to the value with the levels rl and dl, creating the
+// slices, maps, pointers and elements on the way. It returns false if the walk stops before
+// the value because it's null.
+func (d *columnDecoder) walk(state *decodeState, steps []stepState, ptr unsafe.Pointer, rl, dl int32) (unsafe.Pointer, bool) {
+	for s := range d.steps {
+		step, st := &d.steps[s], &steps[s]
+		switch step.kind {
+		case decodeSlice:
+			header := (*sliceHeader)(ptr)
+			if header.data == nil {
+				reflect.NewAt(step.typ, ptr).Elem().Set(reflect.MakeSlice(step.typ, 0, 0))
+			}
+			if st.ptr != ptr {
+				st.ptr, st.index = ptr, -1
+			}
+			if dl < step.preDefinitionLevel {
+				return nil, false
+			}
+			if rl == step.repetitionLevel || st.index < 0 {
+				st.index++
+			}
+			index := st.index
+			if s == 0 {
+				index += state.rootLen
+			}
+			for index >= header.len {
+				slice := reflect.NewAt(step.typ, ptr).Elem()
+				slice.Set(reflect.Append(slice, step.zero))
+			}
+			ptr = unsafe.Pointer(uintptr(header.data) + uintptr(index)*step.elemSize)
+			if dl < step.postDefinitionLevel {
+				return nil, false
+			}
+
+		case decodeMap:
+			if *(*unsafe.Pointer)(ptr) == nil {
+				reflect.NewAt(step.typ, ptr).Elem().Set(reflect.MakeMap(step.typ))
+			}
+			//the maps are identified by their pointer, which doesn't change when the
+			//slices containing them grow
+			m := *(*unsafe.Pointer)(ptr)
+			if st.ptr != m {
+				st.ptr, st.index = m, -1
+				if st.record = state.mapRecords[m]; st.record == nil {
+					st.record = &mapRecord{m: reflect.ValueOf(reflect.NewAt(step.typ, ptr).Elem().Interface())}
+					state.mapRecords[m] = st.record
+					state.mapRecordsStack = append(state.mapRecordsStack, st.record)
+				}
+			}
+			if dl < step.preDefinitionLevel {
+				return nil, false
+			}
+			if rl == step.repetitionLevel || st.index < 0 {
+				st.index++
+			}
+			rec := st.record
+			for st.index >= len(rec.keys) {
+				rec.keys = append(rec.keys, reflect.New(step.typ.Key()))
+				rec.values = append(rec.values, reflect.New(step.typ.Elem()))
+			}
+			if step.key {
+				ptr = unsafe.Pointer(rec.keys[st.index].Pointer())
+			} else {
+				ptr = unsafe.Pointer(rec.values[st.index].Pointer())
+			}
+			if dl < step.postDefinitionLevel {
+				return nil, false
+			}
+
+		case decodePointer:
+			if *(*unsafe.Pointer)(ptr) == nil {
+				*(*unsafe.Pointer)(ptr) = unsafe.Pointer(reflect.New(step.typ.Elem()).Pointer())
+			}
+			ptr = *(*unsafe.Pointer)(ptr)
+
+		case decodeField:
+			if dl < step.preDefinitionLevel {
+				return nil, false
+			}
+			ptr = unsafe.Pointer(uintptr(ptr) + step.offset)
+		}
+	}
+	return ptr, true
+}
+
+// valueSetter returns a function setting the value i of the table to a pointer of typ.
+// The values of the vector of the table are set without reflection if the kind of typ allows it.
+func valueSetter(table *layout.Table, typ reflect.Type) func(ptr unsafe.Pointer, i int) {
+	if table.Vector == nil {
+		return func(ptr unsafe.Pointer, i int) {
+			po := reflect.NewAt(typ, ptr).Elem()
+			value := reflect.ValueOf(table.Values[i])
+			if po.Type() != value.Type() {
+				value = value.Convert(typ)
+			}
+			po.Set(value)
+		}
+	}
+
+	kind := typ.Kind()
+	storeInt := intStorer(kind)
+	switch v := table.Vector.(type) {
+	case *layout.BooleanVector:
+		if kind == reflect.Bool {
+			return func(ptr unsafe.Pointer, i int) { *(*bool)(ptr) = v.Values[i] }
+		}
+	case *layout.Int32Vector:
+		if kind == reflect.Int32 {
+			return func(ptr unsafe.Pointer, i int) { *(*int32)(ptr) = v.Values[i] }
+		} else if storeInt != nil {
+			return func(ptr unsafe.Pointer, i int) { storeInt(ptr, int64(v.Values[i])) }
+		}
+	case *layout.Int64Vector:
+		if kind == reflect.Int64 {
+			return func(ptr unsafe.Pointer, i int) { *(*int64)(ptr) = v.Values[i] }
+		} else if storeInt != nil {
+			return func(ptr unsafe.Pointer, i int) { storeInt(ptr, v.Values[i]) }
+		}
+	case *layout.FloatVector:
+		if kind == reflect.Float32 {
+			return func(ptr unsafe.Pointer, i int) { *(*float32)(ptr) = v.Values[i] }
+		} else if kind == reflect.Float64 {
+			return func(ptr unsafe.Pointer, i int) { *(*float64)(ptr) = float64(v.Values[i]) }
+		}
+	case *layout.DoubleVector:
+		if kind == reflect.Float32 {
+			return func(ptr unsafe.Pointer, i int) { *(*float32)(ptr) = float32(v.Values[i]) }
+		} else if kind == reflect.Float64 {
+			return func(ptr unsafe.Pointer, i int) { *(*float64)(ptr) = v.Values[i] }
+		}
+	case *layout.ByteArrayVector:
+		if kind == reflect.String {
+			return func(ptr unsafe.Pointer, i int) { *(*string)(ptr) = v.Values[i] }
+		}
+	}
+	return func(ptr unsafe.Pointer, i int) {
+		setVectorValue(reflect.NewAt(typ, ptr).Elem(), table.Vector, i)
+	}
+}
+
+// intStorer returns a function storing an integer to a pointer of kind, truncated as
+// reflect.Value.SetInt and SetUint do, or nil if kind isn't an integer.
+func intStorer(kind reflect.Kind) func(unsafe.Pointer, int64) {
+	switch kind {
+	case reflect.Int:
+		return func(ptr unsafe.Pointer, v int64) { *(*int)(ptr) = int(v) }
+	case reflect.Int8:
+		return func(ptr unsafe.Pointer, v int64) { *(*int8)(ptr) = int8(v) }
+	case reflect.Int16:
+		return func(ptr unsafe.Pointer, v int64) { *(*int16)(ptr) = int16(v) }
+	case reflect.Int32:
+		return func(ptr unsafe.Pointer, v int64) { *(*int32)(ptr) = int32(v) }
+	case reflect.Int64:
+		return func(ptr unsafe.Pointer, v int64) { *(*int64)(ptr) = v }
+	case reflect.Uint:
+		return func(ptr unsafe.Pointer, v int64) { *(*uint)(ptr) = uint(v) }
+	case reflect.Uint8:
+		return func(ptr unsafe.Pointer, v int64) { *(*uint8)(ptr) = uint8(v) }
+	case reflect.Uint16:
+		return func(ptr unsafe.Pointer, v int64) { *(*uint16)(ptr) = uint16(v) }
+	case reflect.Uint32:
+		return func(ptr unsafe.Pointer, v int64) { *(*uint32)(ptr) = uint32(v) }
+	case reflect.Uint64:
+		return func(ptr unsafe.Pointer, v int64) { *(*uint64)(ptr) = uint64(v) }
+	}
+	return nil
+}
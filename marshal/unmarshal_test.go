@@ -2,8 +2,11 @@ package marshal
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/layout"
 	. "github.com/xitongsys/parquet-go/schema"
 )
 
@@ -119,3 +122,102 @@ func TestMarshalUnmarshal(t *testing.T) {
 	}
 
 }
+
+type fastEmbedded struct {
+	Note *string `parquet:"name=note, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+type fastSub struct {
+	Val  int16   `parquet:"name=val, type=INT32, convertedtype=INT_16"`
+	Vals []int64 `parquet:"name=vals, type=INT64, repetitiontype=REPEATED"`
+}
+
+type fastElem struct {
+	fastEmbedded
+	Bool    bool        `parquet:"name=bool, type=BOOLEAN"`
+	Int     int         `parquet:"name=int, type=INT64"`
+	Int8    int8        `parquet:"name=int8, type=INT32, convertedtype=INT_8"`
+	Float   *float32    `parquet:"name=float, type=FLOAT"`
+	Double  float64     `parquet:"name=double, type=DOUBLE"`
+	Str     string      `parquet:"name=str, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	List    []string    `parquet:"name=list, type=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	Nested  *fastSub    `parquet:"name=nested"`
+	SubList []fastSub   `parquet:"name=sublist, type=LIST"`
+	Iface   interface{} `parquet:"name=iface, type=INT32"`
+}
+
+type fastMapElem struct {
+	fastElem
+	Map map[string]*fastSub `parquet:"name=map, type=MAP, keytype=BYTE_ARRAY, keyconvertedtype=UTF8"`
+}
+
+func newFastElem(i int) fastElem {
+	note, float := fmt.Sprint("note", i), float32(i)
+	elem := fastElem{Bool: i%2 == 0, Int: -i, Int8: int8(i), Double: float64(i) / 3, Str: fmt.Sprint(i % 3), Iface: int32(i)}
+	if i%3 != 0 {
+		elem.Note, elem.Float = &note, &float
+		elem.List = []string{"a", fmt.Sprint(i)}
+		elem.Nested = &fastSub{Val: int16(i), Vals: []int64{int64(i), int64(-i)}}
+		elem.SubList = []fastSub{{Val: 1}, {Val: 2, Vals: []int64{3}}}
+	}
+	return elem
+}
+
+// Compare UnmarshalFast with Unmarshal for the tables marshaled from src
+func testUnmarshalFast[T any](t *testing.T, src []interface{}, marshalFunc func([]interface{}, *SchemaHandler) (*map[string]*layout.Table, error)) {
+	schemaHandler, err := NewSchemaHandlerFromStruct(new(T))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tableMap, err := marshalFunc(src, schemaHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fastUnmarshaler := new(FastUnmarshaler)
+	for _, rows := range [][2]int{{0, 10}, {3, 7}, {9, 20}} {
+		//the rows are appended to the slices
+		expected, actual, cached := make([]T, 1), make([]T, 1), make([]T, 1)
+		if err := Unmarshal(tableMap, rows[0], rows[1], &expected, schemaHandler, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := UnmarshalFast(tableMap, rows[0], rows[1], &actual, schemaHandler, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := fastUnmarshaler.Unmarshal(tableMap, rows[0], rows[1], &cached, schemaHandler, ""); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, actual) || !reflect.DeepEqual(expected, cached) {
+			t.Errorf("UnmarshalFast err, expect %v, get %v, %v", expected, actual, cached)
+		}
+	}
+
+	expected, actual := []*fastSub{}, []*fastSub{}
+	prefixPath := common.ReformPathStr("Parquet_go_root.Nested")
+	if err := Unmarshal(tableMap, 0, 10, &expected, schemaHandler, prefixPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := UnmarshalFast(tableMap, 0, 10, &actual, schemaHandler, prefixPath); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("UnmarshalFast with prefix err, expect %v, get %v", expected, actual)
+	}
+}
+
+func TestUnmarshalFast(t *testing.T) {
+	src, mapSrc := make([]interface{}, 10), make([]interface{}, 10)
+	for i := range src {
+		src[i] = newFastElem(i)
+		elem := fastMapElem{fastElem: newFastElem(i)}
+		if i%3 != 0 {
+			elem.Map = map[string]*fastSub{"a": {Val: int16(i)}, "b": nil}
+		}
+		mapSrc[i] = elem
+	}
+
+	testUnmarshalFast[fastElem](t, src, Marshal)
+	//the tables of MarshalFast have vectors
+	testUnmarshalFast[fastElem](t, src, MarshalFast)
+	testUnmarshalFast[fastMapElem](t, mapSrc, Marshal)
+}
@@ -0,0 +1,92 @@
+package reader
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xitongsys/parquet-go/bloomfilter"
+	"github.com/xitongsys/parquet-go/encryption"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// ReadBloomFilter reads the bloom filter of the column pathStr in a row group.
+// It returns nil if the column chunk has no bloom filter.
+func (pr *ParquetReader) ReadBloomFilter(rowGroupIndex int64, pathStr string) (*bloomfilter.SplitBlockFilter, error) {
+	rowGroup, chunk, err := pr.columnChunk(rowGroupIndex, pathStr)
+	if err != nil {
+		return nil, err
+	}
+	if chunk.GetMetaData().BloomFilterOffset == nil {
+		return nil, nil
+	}
+	return pr.readBloomFilter(rowGroup, chunk)
+}
+
+// BloomFilterMayContain reports whether value may be in the column pathStr of a
+// row group. It's false only if the bloom filter of the column chunk rules the
+// value out, so it's true for the columns without bloom filter.
+func (pr *ParquetReader) BloomFilterMayContain(rowGroupIndex int64, pathStr string, value interface{}) (bool, error) {
+	column := &filterColumn{path: pathStr}
+	if err := column.bind(pr.SchemaHandler); err != nil {
+		return false, err
+	}
+	value, err := column.convert(value)
+	if err != nil {
+		return false, err
+	}
+
+	filter, err := pr.ReadBloomFilter(rowGroupIndex, pathStr)
+	if err != nil || filter == nil {
+		return true, err
+	}
+	return filter.Check(bloomfilter.Hash(value)), nil
+}
+
+func (pr *ParquetReader) readBloomFilter(rowGroup *parquet.RowGroup, chunk *parquet.ColumnChunk) (*bloomfilter.SplitBlockFilter, error) {
+	decryptor, err := pr.columnDecryptor(rowGroup, chunk)
+	if err != nil {
+		return nil, err
+	}
+	pFile := pr.PFile
+	if chunk.FilePath != nil {
+		if pFile, err = pFile.Open(chunk.GetFilePath()); err != nil {
+			return nil, err
+		}
+		defer pFile.Close()
+	}
+
+	offset := chunk.GetMetaData().GetBloomFilterOffset()
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid bloom filter offset %v", offset)
+	}
+	if _, err = pFile.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if decryptor == nil {
+		return bloomfilter.Read(pFile)
+	}
+
+	//the header and the bitset are separate modules in encrypted chunks
+	module, err := encryption.ReadModule(pFile)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := decryptor.DecryptModule(encryption.ModuleBloomFilterHeader, 0, module)
+	if err != nil {
+		return nil, err
+	}
+	numBytes, err := bloomfilter.UnmarshalHeader(buf)
+	if err != nil {
+		return nil, err
+	}
+	if module, err = encryption.ReadModule(pFile); err != nil {
+		return nil, err
+	}
+	if buf, err = decryptor.DecryptModule(encryption.ModuleBloomFilterBitset, 0, module); err != nil {
+		return nil, err
+	}
+	if len(buf) != int(numBytes) {
+		return nil, fmt.Errorf("bloom filter bitset size %v doesn't match its header %v", len(buf), numBytes)
+	}
+	return bloomfilter.FromBitset(buf)
+}
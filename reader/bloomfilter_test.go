@@ -0,0 +1,115 @@
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type bloomRecord struct {
+	Key   string `parquet:"name=key, type=BYTE_ARRAY, convertedtype=UTF8, bloomfilter=true, bloomfilterfpp=0.001"`
+	Value *int64 `parquet:"name=value, type=INT64, bloomfilter=true"`
+	Other int32  `parquet:"name=other, type=INT32"`
+}
+
+func writeBloomFile(t *testing.T, numRowGroups, rowGroupSize int) []byte {
+	var buf bytes.Buffer
+	fw := writerfile.NewWriterFile(&buf)
+	pw, err := writer.NewParquetWriter(fw, new(bloomRecord), 2)
+	assert.NoError(t, err)
+
+	for i := 0; i < numRowGroups; i++ {
+		for j := 0; j < rowGroupSize; j++ {
+			id := int64(i*rowGroupSize + j)
+			// the keys aren't sorted, so the statistics can't skip row groups
+			rec := bloomRecord{Key: fmt.Sprintf("key_%d_%d", j, i), Other: int32(id)}
+			if id%2 == 0 {
+				rec.Value = &id
+			}
+			assert.NoError(t, pw.Write(rec))
+		}
+		assert.NoError(t, pw.Flush(true))
+	}
+	assert.NoError(t, pw.WriteStop())
+	return buf.Bytes()
+}
+
+func TestBloomFilter(t *testing.T) {
+	data := writeBloomFile(t, 3, 200)
+	pf, err := buffer.NewBufferFile(data)
+	assert.NoError(t, err)
+	pr, err := NewParquetReader(pf, new(bloomRecord), 1)
+	assert.NoError(t, err)
+	defer pr.ReadStop()
+
+	key := common.ReformPathStr("parquet_go_root.key")
+	value := common.ReformPathStr("parquet_go_root.value")
+	other := common.ReformPathStr("parquet_go_root.other")
+
+	for rg := int64(0); rg < 3; rg++ {
+		assert.NotNil(t, pr.Footer.RowGroups[rg].Columns[0].MetaData.BloomFilterOffset)
+		assert.NotNil(t, pr.Footer.RowGroups[rg].Columns[1].MetaData.BloomFilterOffset)
+		assert.Nil(t, pr.Footer.RowGroups[rg].Columns[2].MetaData.BloomFilterOffset)
+
+		falsePositives := 0
+		for j := 0; j < 200; j++ {
+			ok, err := pr.BloomFilterMayContain(rg, key, fmt.Sprintf("key_%d_%d", j, rg))
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = pr.BloomFilterMayContain(rg, key, fmt.Sprintf("key_%d_%d", j, rg+3))
+			assert.NoError(t, err)
+			if ok {
+				falsePositives++
+			}
+		}
+		assert.True(t, falsePositives < 5, "too many false positives: %v", falsePositives)
+
+		ok, err := pr.BloomFilterMayContain(rg, value, rg*200+2)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		// columns without bloom filter may contain any value
+		filter, err := pr.ReadBloomFilter(rg, other)
+		assert.NoError(t, err)
+		assert.Nil(t, filter)
+		ok, err = pr.BloomFilterMayContain(rg, other, -1)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	_, err = pr.BloomFilterMayContain(0, value, "a")
+	assert.Error(t, err)
+	_, err = pr.ReadBloomFilter(3, key)
+	assert.Error(t, err)
+}
+
+func TestFilterBloomFilter(t *testing.T) {
+	data := writeBloomFile(t, 3, 200)
+	pf, err := buffer.NewBufferFile(data)
+	assert.NoError(t, err)
+	pr, err := NewParquetReader(pf, new(bloomRecord), 1)
+	assert.NoError(t, err)
+	defer pr.ReadStop()
+
+	key := common.ReformPathStr("parquet_go_root.key")
+	assert.NoError(t, pr.SetFilter(Eq(key, "key_10_1")))
+	assert.Equal(t, 1, len(pr.RowRanges))
+	rows := make([]bloomRecord, 10)
+	assert.NoError(t, pr.Read(&rows))
+	assert.Equal(t, 1, len(rows))
+	assert.Equal(t, int32(210), rows[0].Other)
+
+	assert.NoError(t, pr.SetFilter(In(key, "key_1_0", "key_2_2", "key_3_5")))
+	assert.Equal(t, 2, len(pr.RowRanges))
+
+	// NotIn can't use the bloom filter
+	assert.NoError(t, pr.SetFilter(NotIn(key, "key_1_0")))
+	assert.Equal(t, 3, len(pr.RowRanges))
+}
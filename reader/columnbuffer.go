@@ -1,11 +1,15 @@
 package reader
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/encryption"
 	"github.com/xitongsys/parquet-go/layout"
 	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xitongsys/parquet-go/schema"
@@ -29,9 +33,96 @@ type ColumnBufferType struct {
 
 	DataTable        *layout.Table
 	DataTableNumRows int64
+
+	//Rows to read of each row group, set by a filter. Row groups without
+	//ranges are skipped. nil means all the rows are read.
+	RowRanges map[int64][]RowRange
+	//OffsetIndex of the current chunk, used to skip the pages out of RowRanges
+	//and the pages skipped by SkipRows
+	OffsetIndex *parquet.OffsetIndex
+	//Index of the next data page of the current chunk
+	DataPageIndex int
+
+	fileDecryptor *encryption.FileDecryptor
+	//Decryptor of the current chunk, nil if it isn't encrypted
+	columnDecryptor *encryption.ColumnDecryptor
+
+	pageLimits layout.PageLimits
+
+	//Prefetcher of the chunks of the reader, nil if the file isn't a source.RangeReader
+	prefetcher *prefetcher
+	//RowGroupIndex of the last prefetched chunk
+	prefetchedRowGroup int64
+
+	//Pool of the pages read ahead, nil if they aren't read ahead
+	readAheadPool *readAheadPool
+	//Reader of the pages of the current chunk read ahead, it owns ThriftReader
+	readAhead *pageReadAhead
+	//The values read from a source.MappedFile may be views of the mapping
+	mappedViews bool
+}
+
+// ColumnError is an error reading a column chunk, with the position of the error in the file
+type ColumnError struct {
+	//Path of the column in the schema
+	Path          string
+	RowGroupIndex int64
+	//Offset of the page in the file, -1 if the error isn't in a page
+	PageOffset int64
+	Err        error
+}
+
+func (e *ColumnError) Error() string {
+	path := strings.ReplaceAll(e.Path, common.PAR_GO_PATH_DELIMITER, ".")
+	if e.PageOffset < 0 {
+		return fmt.Sprintf("column %v, row group %v: %v", path, e.RowGroupIndex, e.Err)
+	}
+	return fmt.Sprintf("column %v, row group %v, page offset %v: %v", path, e.RowGroupIndex, e.PageOffset, e.Err)
+}
+
+func (e *ColumnError) Unwrap() error {
+	return e.Err
+}
+
+// Wrap err in a ColumnError of the current row group, io.EOF is returned as it's the end of the column
+func (cbt *ColumnBufferType) columnError(err error, pageOffset int64) error {
+	if _, ok := err.(*ColumnError); ok || err == nil || err == io.EOF {
+		return err
+	}
+	return &ColumnError{Path: cbt.PathStr, RowGroupIndex: cbt.RowGroupIndex - 1, PageOffset: pageOffset, Err: err}
+}
+
+// Offset in the file of the next page read by ThriftReader, -1 if it's unknown
+func (cbt *ColumnBufferType) pageOffset() int64 {
+	if cbt.readAhead != nil {
+		return cbt.readAhead.nextOffset()
+	}
+	if cbt.ThriftReader == nil {
+		return -1
+	}
+	pos, err := cbt.PFile.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	return pos - int64(cbt.ThriftReader.Reader.Buffered())
+}
+
+// Convert a panic decoding a corrupted page to an error. The panics aren't recovered
+// by the fuzz targets, so that they are found.
+func (cbt *ColumnBufferType) recoverError(err *error, pageOffset int64) {
+	if fuzzing {
+		return
+	}
+	if r := recover(); r != nil {
+		*err = cbt.columnError(fmt.Errorf("corrupted page: %v", r), pageOffset)
+	}
 }
 
 func NewColumnBuffer(pFile source.ParquetFile, footer *parquet.FileMetaData, schemaHandler *schema.SchemaHandler, pathStr string) (*ColumnBufferType, error) {
+	return newColumnBuffer(pFile, footer, schemaHandler, pathStr, nil, nil, layout.PageLimits{})
+}
+
+func newColumnBuffer(pFile source.ParquetFile, footer *parquet.FileMetaData, schemaHandler *schema.SchemaHandler, pathStr string, rowRanges map[int64][]RowRange, decryptor *encryption.FileDecryptor, pageLimits layout.PageLimits) (*ColumnBufferType, error) {
 	newPFile, err := pFile.Open("")
 	if err != nil {
 		return nil, err
@@ -42,24 +133,33 @@ func NewColumnBuffer(pFile source.ParquetFile, footer *parquet.FileMetaData, sch
 		SchemaHandler:    schemaHandler,
 		PathStr:          pathStr,
 		DataTableNumRows: -1,
+		RowRanges:        rowRanges,
+		fileDecryptor:    decryptor,
+		pageLimits:       pageLimits,
 	}
 
 	if err = res.NextRowGroup(); err == io.EOF {
 		err = nil
 	}
-	return res, err
+	return res, res.columnError(err, -1)
 }
 
 func (cbt *ColumnBufferType) NextRowGroup() error {
 	var err error
+	cbt.stopReadAhead()
 	rowGroups := cbt.Footer.GetRowGroups()
 	ln := int64(len(rowGroups))
-	if cbt.RowGroupIndex >= ln {
-		cbt.DataTableNumRows++ //very important, because DataTableNumRows is one smaller than real rows number
-		return io.EOF
-	}
+	for {
+		if cbt.RowGroupIndex >= ln {
+			cbt.DataTableNumRows++ //very important, because DataTableNumRows is one smaller than real rows number
+			return io.EOF
+		}
 
-	cbt.RowGroupIndex++
+		cbt.RowGroupIndex++
+		if cbt.RowRanges == nil || len(cbt.RowRanges[cbt.RowGroupIndex-1]) > 0 {
+			break
+		}
+	}
 
 	columnChunks := rowGroups[cbt.RowGroupIndex-1].GetColumns()
 	i := int64(0)
@@ -87,9 +187,23 @@ func (cbt *ColumnBufferType) NextRowGroup() error {
 	}
 
 	//offset := columnChunks[i].FileOffset
-	offset := columnChunks[i].MetaData.DataPageOffset
-	if columnChunks[i].MetaData.DictionaryPageOffset != nil {
-		offset = *columnChunks[i].MetaData.DictionaryPageOffset
+	offset := layout.ChunkOffset(columnChunks[i].MetaData)
+
+	cbt.columnDecryptor = nil
+	if cbt.fileDecryptor != nil {
+		rowGroupIndex := int(cbt.RowGroupIndex - 1)
+		if cbt.columnDecryptor, err = cbt.fileDecryptor.ColumnDecryptor(columnChunks[i].CryptoMetadata, rowGroupOrdinal(rowGroups[rowGroupIndex], rowGroupIndex), int(i)); err != nil {
+			return err
+		}
+	}
+
+	cbt.OffsetIndex, cbt.DataPageIndex = nil, 0
+	if cbt.RowRanges != nil && columnChunks[i].OffsetIndexOffset != nil {
+		maxRL, _ := cbt.SchemaHandler.MaxRepetitionLevel(common.StrToPath(cbt.PathStr))
+		if maxRL == 0 {
+			//pages can't be skipped without offset index, so just ignore the error
+			cbt.OffsetIndex, _ = readOffsetIndex(cbt.PFile, columnChunks[i], cbt.columnDecryptor)
+		}
 	}
 
 	if cbt.ThriftReader != nil {
@@ -102,39 +216,173 @@ func (cbt *ColumnBufferType) NextRowGroup() error {
 	return nil
 }
 
-func (cbt *ColumnBufferType) ReadPage() error {
+// Bytes of the file if it's a source.MappedFile, nil otherwise. The pages of
+// encrypted chunks are read and decrypted as in the other files.
+func (cbt *ColumnBufferType) mappedBytes() []byte {
+	if file, ok := cbt.PFile.(source.MappedFile); ok && cbt.columnDecryptor == nil {
+		return file.Bytes()
+	}
+	return nil
+}
+
+// Read the page at offset from the bytes of a mapped file without copying it, its
+// values are copied unless mappedViews is set. ThriftReader is moved after the page.
+func (cbt *ColumnBufferType) readMappedPage(data []byte, offset int64) (*layout.Page, int64, int64, error) {
+	if offset < 0 || offset >= int64(len(data)) {
+		return nil, 0, 0, io.EOF
+	}
+	compressedPage, size, err := cbt.pageLimits.ReadMappedPage(data[offset:])
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	compressedPage.Views = cbt.mappedViews
+	cbt.ThriftReader.Close()
+	cbt.ThriftReader = source.ConvertToThriftReader(cbt.PFile, offset+size)
+	return cbt.pageLimits.DecodePageVector(compressedPage, cbt.SchemaHandler, cbt.ChunkHeader.MetaData)
+}
+
+// Prefetch the current chunk if the file is a source.RangeReader
+func (cbt *ColumnBufferType) prefetch() {
+	if cbt.prefetcher != nil && cbt.prefetchedRowGroup != cbt.RowGroupIndex && cbt.ChunkHeader.FilePath == nil {
+		cbt.prefetcher.fetch(cbt.RowGroupIndex-1, cbt.PathStr)
+		cbt.prefetchedRowGroup = cbt.RowGroupIndex
+	}
+}
+
+// Reader of the pages of the current chunk read ahead, which is started on the first
+// call. It's nil if the pages aren't read ahead: the pages of encrypted chunks are
+// read and decrypted one by one.
+func (cbt *ColumnBufferType) pageReadAhead() *pageReadAhead {
+	if cbt.readAhead == nil && cbt.readAheadPool != nil && cbt.columnDecryptor == nil {
+		cbt.prefetch()
+		metaData := cbt.ChunkHeader.MetaData
+		start := layout.ChunkOffset(metaData)
+		cbt.readAhead = newPageReadAhead(cbt.readAheadPool, cbt.PFile, cbt.ThriftReader, cbt.SchemaHandler, metaData, cbt.pageLimits, cbt.pageOffset(), start+metaData.TotalCompressedSize)
+	}
+	return cbt.readAhead
+}
+
+// Stop reading the pages ahead, ThriftReader is moved to the first page which isn't read yet
+func (cbt *ColumnBufferType) stopReadAhead() {
+	if cbt.readAhead == nil {
+		return
+	}
+	offset := cbt.readAhead.stop()
+	cbt.readAhead = nil
+	cbt.ThriftReader.Close()
+	cbt.ThriftReader = source.ConvertToThriftReader(cbt.PFile, offset)
+}
+
+// Close the file of the column buffer, the pages aren't read ahead anymore
+func (cbt *ColumnBufferType) close() error {
+	if cbt.readAhead != nil {
+		cbt.readAhead.stop()
+		cbt.readAhead = nil
+	}
+	return cbt.PFile.Close()
+}
+
+// Skip the next data pages whose rows are all out of RowRanges. Nulls are added
+// to DataTable in place of the rows of the skipped pages.
+func (cbt *ColumnBufferType) skipPages() bool {
+	if cbt.RowRanges == nil || cbt.OffsetIndex == nil || (cbt.ChunkHeader.MetaData.DictionaryPageOffset != nil && cbt.DictPage == nil) {
+		return false
+	}
+
+	locations := cbt.OffsetIndex.PageLocations
+	ranges := cbt.RowRanges[cbt.RowGroupIndex-1]
+	numRows := cbt.Footer.RowGroups[cbt.RowGroupIndex-1].GetNumRows()
+	skipRows := int64(0)
+	i := cbt.DataPageIndex
+	for ; i < len(locations); i++ {
+		from, to := locations[i].FirstRowIndex, numRows
+		if i+1 < len(locations) {
+			to = locations[i+1].FirstRowIndex
+		}
+		if overlapRowRanges(ranges, from, to) {
+			break
+		}
+		skipRows += to - from
+	}
+	if skipRows <= 0 {
+		return false
+	}
+
+	cbt.appendNulls(skipRows)
+	cbt.ChunkReadValues += skipRows
+	cbt.DataTableNumRows += skipRows
+	cbt.DataPageIndex = i
+	if i < len(locations) {
+		cbt.stopReadAhead()
+		cbt.ThriftReader.Close()
+		cbt.ThriftReader = source.ConvertToThriftReader(cbt.PFile, locations[i].Offset)
+	}
+	return true
+}
+
+// Append num null values to DataTable
+func (cbt *ColumnBufferType) appendNulls(num int64) {
+	if cbt.DataTable == nil {
+		index := cbt.SchemaHandler.MapIndex[cbt.PathStr]
+		cbt.DataTable = layout.NewEmptyTable()
+		cbt.DataTable.Schema = cbt.SchemaHandler.SchemaElements[index]
+		cbt.DataTable.Path = common.StrToPath(cbt.PathStr)
+	}
+
+	//nulls of a required column aren't stored in Vector
+	if cbt.DataTable.Vector != nil && cbt.DataTable.MaxDefinitionLevel == 0 {
+		cbt.DataTable.ToValues()
+	}
+	for i := int64(0); i < num; i++ {
+		if cbt.DataTable.Vector == nil {
+			cbt.DataTable.Values = append(cbt.DataTable.Values, nil)
+		}
+		cbt.DataTable.RepetitionLevels = append(cbt.DataTable.RepetitionLevels, int32(0))
+		cbt.DataTable.DefinitionLevels = append(cbt.DataTable.DefinitionLevels, int32(0))
+	}
+}
+
+func (cbt *ColumnBufferType) ReadPage() (err error) {
 	if cbt.ChunkHeader != nil && cbt.ChunkHeader.MetaData != nil && cbt.ChunkReadValues < cbt.ChunkHeader.MetaData.NumValues {
-		page, numValues, numRows, err := layout.ReadPage(cbt.ThriftReader, cbt.SchemaHandler, cbt.ChunkHeader.MetaData)
+		if cbt.skipPages() {
+			return nil
+		}
+
+		var page *layout.Page
+		var numValues, numRows int64
+		var thriftReader *thrift.TBufferedTransport
+		pageOffset := cbt.pageOffset()
+		if data := cbt.mappedBytes(); data != nil {
+			page, numValues, numRows, err = cbt.readMappedPage(data, pageOffset)
+		} else if readAhead := cbt.pageReadAhead(); readAhead != nil {
+			page, numValues, numRows, err = readAhead.next()
+		} else if thriftReader, err = cbt.pageReader(); err == nil {
+			page, numValues, numRows, err = cbt.pageLimits.ReadPageVector(thriftReader, cbt.SchemaHandler, cbt.ChunkHeader.MetaData)
+		}
 		if err != nil {
 			//data is nil and rl/dl=0, no pages in file
-			if err == io.EOF {
-				if cbt.DataTable == nil {
-					index := cbt.SchemaHandler.MapIndex[cbt.PathStr]
-					cbt.DataTable = layout.NewEmptyTable()
-					cbt.DataTable.Schema = cbt.SchemaHandler.SchemaElements[index]
-					cbt.DataTable.Path = common.StrToPath(cbt.PathStr)
-
-				}
-
+			if err == io.EOF && cbt.ChunkHeader.MetaData.TotalCompressedSize == 0 {
 				cbt.DataTableNumRows = cbt.ChunkHeader.MetaData.NumValues
-
-				for cbt.ChunkReadValues < cbt.ChunkHeader.MetaData.NumValues {
-					cbt.DataTable.Values = append(cbt.DataTable.Values, nil)
-					cbt.DataTable.RepetitionLevels = append(cbt.DataTable.RepetitionLevels, int32(0))
-					cbt.DataTable.DefinitionLevels = append(cbt.DataTable.DefinitionLevels, int32(0))
-					cbt.ChunkReadValues++
-				}
+				cbt.appendNulls(cbt.ChunkHeader.MetaData.NumValues - cbt.ChunkReadValues)
+				cbt.ChunkReadValues = cbt.ChunkHeader.MetaData.NumValues
+				return err
 			}
-
-			return err
+			//the file is truncated
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return cbt.columnError(err, pageOffset)
 		}
+		defer cbt.recoverError(&err, pageOffset)
 
 		if page.Header.GetType() == parquet.PageType_DICTIONARY_PAGE {
 			cbt.DictPage = page
 			return nil
 		}
 
-		page.Decode(cbt.DictPage)
+		if err = page.Decode(cbt.DictPage); err != nil {
+			return cbt.columnError(err, pageOffset)
+		}
 
 		if cbt.DataTable == nil {
 			cbt.DataTable = layout.NewTableFromTable(page.DataTable)
@@ -142,11 +390,12 @@ func (cbt *ColumnBufferType) ReadPage() error {
 
 		cbt.DataTable.Merge(page.DataTable)
 		cbt.ChunkReadValues += numValues
+		cbt.DataPageIndex++
 
 		cbt.DataTableNumRows += numRows
 	} else {
 		if err := cbt.NextRowGroup(); err != nil {
-			return err
+			return cbt.columnError(err, -1)
 		}
 
 		return cbt.ReadPage()
@@ -155,20 +404,85 @@ func (cbt *ColumnBufferType) ReadPage() error {
 	return nil
 }
 
-func (cbt *ColumnBufferType) ReadPageForSkip() (*layout.Page, error) {
+// Reader of the next page. The pages of encrypted chunks are decrypted, so that
+// their header and data are read as in plaintext chunks.
+func (cbt *ColumnBufferType) pageReader() (*thrift.TBufferedTransport, error) {
+	cbt.prefetch()
+	if cbt.columnDecryptor == nil {
+		return cbt.ThriftReader, nil
+	}
+
+	headerModule, pageModule := encryption.ModuleDataPageHeader, encryption.ModuleDataPage
+	if cbt.ChunkHeader.MetaData.DictionaryPageOffset != nil && cbt.DictPage == nil {
+		headerModule, pageModule = encryption.ModuleDictionaryPageHeader, encryption.ModuleDictionaryPage
+	}
+
+	module, err := encryption.ReadModule(cbt.ThriftReader)
+	if err != nil {
+		return nil, err
+	}
+	headerBuf, err := cbt.columnDecryptor.DecryptModule(headerModule, cbt.DataPageIndex, module)
+	if err != nil {
+		return nil, err
+	}
+	td := thrift.NewTDeserializer()
+	td.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(td.Transport)
+	header := parquet.NewPageHeader()
+	if err = td.Read(context.TODO(), header, headerBuf); err != nil {
+		return nil, err
+	}
+
+	if header.CompressedPageSize < 0 {
+		return nil, fmt.Errorf("invalid page size %v", header.CompressedPageSize)
+	}
+	if maxSize := cbt.pageLimits.MaxPageSize; maxSize > 0 && int64(header.CompressedPageSize) > maxSize {
+		return nil, fmt.Errorf("page size %v is larger than the limit %v", header.CompressedPageSize, maxSize)
+	}
+	module = make([]byte, header.CompressedPageSize)
+	if _, err = io.ReadFull(cbt.ThriftReader, module); err != nil {
+		return nil, err
+	}
+	data, err := cbt.columnDecryptor.DecryptModule(pageModule, cbt.DataPageIndex, module)
+	if err != nil {
+		return nil, err
+	}
+
+	header.CompressedPageSize = int32(len(data))
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	if headerBuf, err = ts.Write(context.TODO(), header); err != nil {
+		return nil, err
+	}
+	buf := append(headerBuf, data...)
+	return thrift.NewTBufferedTransport(thrift.NewStreamTransportR(bytes.NewReader(buf)), len(buf)), nil
+}
+
+func (cbt *ColumnBufferType) ReadPageForSkip() (page *layout.Page, err error) {
+	cbt.stopReadAhead()
 	if cbt.ChunkHeader != nil && cbt.ChunkHeader.MetaData != nil && cbt.ChunkReadValues < cbt.ChunkHeader.MetaData.NumValues {
-		page, err := layout.ReadPageRawData(cbt.ThriftReader, cbt.SchemaHandler, cbt.ChunkHeader.MetaData)
+		pageOffset := cbt.pageOffset()
+		defer cbt.recoverError(&err, pageOffset)
+		thriftReader, err := cbt.pageReader()
 		if err != nil {
-			return nil, err
+			return nil, cbt.columnError(err, pageOffset)
+		}
+		if page, err = cbt.pageLimits.ReadPageRawData(thriftReader, cbt.SchemaHandler, cbt.ChunkHeader.MetaData); err != nil {
+			//the file is truncated, unless there are no pages in it
+			if err == io.EOF && cbt.ChunkHeader.MetaData.TotalCompressedSize > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, cbt.columnError(err, pageOffset)
 		}
 
 		numValues, numRows, err := page.GetRLDLFromRawData(cbt.SchemaHandler)
 		if err != nil {
-			return nil, err
+			return nil, cbt.columnError(err, pageOffset)
 		}
 
 		if page.Header.GetType() == parquet.PageType_DICTIONARY_PAGE {
-			page.GetValueFromRawData(cbt.SchemaHandler)
+			if err = page.GetValueFromRawData(cbt.SchemaHandler); err != nil {
+				return nil, cbt.columnError(err, pageOffset)
+			}
 			cbt.DictPage = page
 			return page, nil
 		}
@@ -180,37 +494,141 @@ func (cbt *ColumnBufferType) ReadPageForSkip() (*layout.Page, error) {
 		cbt.DataTable.Merge(page.DataTable)
 		cbt.ChunkReadValues += numValues
 		cbt.DataTableNumRows += numRows
+		cbt.DataPageIndex++
 		return page, nil
 
 	} else {
 		if err := cbt.NextRowGroup(); err != nil {
-			return nil, err
+			return nil, cbt.columnError(err, -1)
 		}
 
 		return cbt.ReadPageForSkip()
 	}
 }
 
-func (cbt *ColumnBufferType) SkipRows(num int64) int64 {
-	var (
-		err  error
-		page *layout.Page
-	)
+// Skip whole pages using the OffsetIndex of the chunks, without reading them.
+// Only the pages before the one containing the last skipped row are skipped,
+// the remaining rows are left to SkipRows. It returns the number of skipped rows.
+func (cbt *ColumnBufferType) seekRows(num int64) int64 {
+	if maxRL, _ := cbt.SchemaHandler.MaxRepetitionLevel(common.StrToPath(cbt.PathStr)); maxRL > 0 {
+		return 0
+	}
+
+	skipped := int64(0)
+	//DataTable holds one more row than DataTableNumRows until the end of file
+	if cbt.DataTable != nil && len(cbt.DataTable.DefinitionLevels) > 0 {
+		buffered := int64(len(cbt.DataTable.DefinitionLevels))
+		if num < buffered || cbt.DataTableNumRows != buffered-1 {
+			return 0
+		}
+		cbt.DataTable = layout.NewTableFromTable(cbt.DataTable)
+		cbt.DataTableNumRows = -1
+		skipped = buffered
+	}
+
+	for skipped < num {
+		metaData := cbt.ChunkHeader.GetMetaData()
+		if metaData == nil {
+			return skipped
+		}
+		if cbt.ChunkReadValues >= metaData.NumValues {
+			if cbt.RowGroupIndex >= int64(len(cbt.Footer.GetRowGroups())) || cbt.NextRowGroup() != nil {
+				return skipped
+			}
+			continue
+		}
+
+		target := cbt.ChunkReadValues + num - skipped
+		if target >= metaData.NumValues {
+			skipped += metaData.NumValues - cbt.ChunkReadValues
+			cbt.ChunkReadValues = metaData.NumValues
+			continue
+		}
+
+		if cbt.OffsetIndex == nil && !cbt.loadOffsetIndex() {
+			return skipped
+		}
+		if metaData.DictionaryPageOffset != nil && cbt.DictPage == nil {
+			if cbt.ReadPage() != nil || cbt.DictPage == nil {
+				return skipped
+			}
+		}
+
+		locations := cbt.OffsetIndex.PageLocations
+		i := cbt.DataPageIndex
+		if i >= len(locations) || locations[i].FirstRowIndex != cbt.ChunkReadValues {
+			return skipped
+		}
+
+		for i+1 < len(locations) && locations[i+1].FirstRowIndex <= target {
+			i++
+		}
+		if i == cbt.DataPageIndex {
+			return skipped
+		}
+		skipped += locations[i].FirstRowIndex - cbt.ChunkReadValues
+		cbt.ChunkReadValues = locations[i].FirstRowIndex
+		cbt.DataPageIndex = i
+		cbt.stopReadAhead()
+		cbt.ThriftReader.Close()
+		cbt.ThriftReader = source.ConvertToThriftReader(cbt.PFile, locations[i].Offset)
+	}
+	return skipped
+}
+
+// Read the OffsetIndex of the current chunk. It's only done before reading
+// the chunk, as ThriftReader is moved back to the start of the chunk.
+func (cbt *ColumnBufferType) loadOffsetIndex() bool {
+	if cbt.ChunkHeader.OffsetIndexOffset == nil || cbt.ChunkReadValues > 0 || cbt.DictPage != nil {
+		return false
+	}
+
+	cbt.stopReadAhead()
+	offsetIndex, err := readOffsetIndex(cbt.PFile, cbt.ChunkHeader, cbt.columnDecryptor)
+	offset := layout.ChunkOffset(cbt.ChunkHeader.MetaData)
+	cbt.ThriftReader.Close()
+	cbt.ThriftReader = source.ConvertToThriftReader(cbt.PFile, offset)
+	if err != nil {
+		return false
+	}
+	cbt.OffsetIndex = offsetIndex
+	return true
+}
+
+// Skip num rows, it returns the number of skipped rows, which is less than num at the end of the column
+func (cbt *ColumnBufferType) SkipRows(num int64) (skipped int64, err error) {
+	var page *layout.Page
+
+	skipped = cbt.seekRows(num)
+	if num -= skipped; num <= 0 {
+		return skipped, nil
+	}
 
 	for cbt.DataTableNumRows < num && err == nil {
 		page, err = cbt.ReadPageForSkip()
 	}
+	if err != nil && err != io.EOF {
+		return skipped, err
+	}
 
 	if num > cbt.DataTableNumRows {
 		num = cbt.DataTableNumRows
 	}
 
+	if cbt.DataTable == nil {
+		return skipped, nil
+	}
+
 	if page != nil {
+		pageOffset := cbt.pageOffset()
+		defer cbt.recoverError(&err, pageOffset)
 		if err = page.GetValueFromRawData(cbt.SchemaHandler); err != nil {
-			return 0
+			return skipped, cbt.columnError(err, pageOffset)
 		}
 
-		page.Decode(cbt.DictPage)
+		if err = page.Decode(cbt.DictPage); err != nil {
+			return skipped, cbt.columnError(err, pageOffset)
+		}
 		i, j := len(cbt.DataTable.Values)-1, len(page.DataTable.Values)-1
 		for i >= 0 && j >= 0 {
 			cbt.DataTable.Values[i] = page.DataTable.Values[j]
@@ -226,12 +644,23 @@ func (cbt *ColumnBufferType) SkipRows(num int64) int64 {
 		cbt.DataTable.Merge(tmp)
 	}
 
-	return num
+	return skipped + num, nil
 }
 
-func (cbt *ColumnBufferType) ReadRows(num int64) (*layout.Table, int64) {
+// Read num rows, it returns the table of the rows and their number, which is less than num at the end of the column
+func (cbt *ColumnBufferType) ReadRows(num int64) (*layout.Table, int64, error) {
+	res, num, err := cbt.readRows(num)
+	if err != nil {
+		return nil, 0, err
+	}
+	res.ToValues()
+	return res, num, nil
+}
+
+// Read num rows, the values of the returned table may be in its Vector
+func (cbt *ColumnBufferType) readRows(num int64) (*layout.Table, int64, error) {
 	if cbt.Footer.NumRows == 0 {
-		return &layout.Table{}, 0
+		return &layout.Table{}, 0, nil
 	}
 
 	var err error
@@ -239,8 +668,11 @@ func (cbt *ColumnBufferType) ReadRows(num int64) (*layout.Table, int64) {
 	for cbt.DataTableNumRows < num && err == nil {
 		err = cbt.ReadPage()
 	}
+	if err != nil && err != io.EOF {
+		return nil, 0, err
+	}
 
-	if cbt.DataTableNumRows < 0 {
+	if cbt.DataTableNumRows < 0 || cbt.DataTable == nil {
 		cbt.DataTableNumRows = 0
 		cbt.DataTable = layout.NewEmptyTable()
 	}
@@ -257,6 +689,6 @@ func (cbt *ColumnBufferType) ReadRows(num int64) (*layout.Table, int64) {
 		cbt.DataTable = layout.NewTableFromTable(tmp)
 		cbt.DataTable.Merge(tmp)
 	}
-	return res, num
+	return res, num, nil
 
 }
@@ -0,0 +1,157 @@
+package reader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// truncatedFile is a file truncated after its footer was read
+type truncatedFile struct {
+	source.ParquetFile
+	data []byte
+}
+
+func (f truncatedFile) Open(name string) (source.ParquetFile, error) {
+	return buffer.NewBufferFile(f.data)
+}
+
+func writeCorruptionFixture(t *testing.T) ([]byte, *parquet.FileMetaData) {
+	records := make([]genericRecord, 100)
+	for i := range records {
+		records[i] = genericRecord{ID: int64(i), Name: "name"}
+	}
+
+	var buf bytes.Buffer
+	pw, err := writer.NewGenericWriterFromWriter[genericRecord](&buf, 1)
+	assert.NoError(t, err)
+	//the gzip checksum makes any corruption of the page data detected
+	pw.CompressionType = parquet.CompressionCodec_GZIP
+	_, err = pw.Write(records)
+	assert.NoError(t, err)
+	assert.NoError(t, pw.WriteStop())
+
+	pf, err := buffer.NewBufferFile(buf.Bytes())
+	assert.NoError(t, err)
+	pr, err := NewParquetReader(pf, new(genericRecord), 1)
+	assert.NoError(t, err)
+	return buf.Bytes(), pr.Footer
+}
+
+func TestColumnError(t *testing.T) {
+	data, footer := writeCorruptionFixture(t)
+	//the last column is corrupted, so that the chunks of the other columns are intact
+	columns := footer.RowGroups[0].Columns
+	column := len(columns) - 1
+	chunk := columns[column].MetaData
+	pageOffset := chunk.DataPageOffset
+	chunkEnd := pageOffset + chunk.TotalCompressedSize
+
+	corrupt := func(offset int64, b ...byte) []byte {
+		res := append([]byte{}, data...)
+		copy(res[offset:], b)
+		return res
+	}
+
+	testCases := []struct {
+		name       string
+		file       func() source.ParquetFile
+		pageOffset int64
+	}{
+		{"truncated", func() source.ParquetFile {
+			pf, _ := buffer.NewBufferFile(data)
+			return truncatedFile{pf, data[:pageOffset+4]}
+		}, pageOffset},
+		{"page header", func() source.ParquetFile {
+			pf, _ := buffer.NewBufferFile(corrupt(pageOffset, 0xff, 0xff, 0xff, 0xff))
+			return pf
+		}, pageOffset},
+		{"page data", func() source.ParquetFile {
+			pf, _ := buffer.NewBufferFile(corrupt(chunkEnd-8, 0, 0, 0, 0, 0, 0, 0, 0))
+			return pf
+		}, pageOffset},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var pr *ParquetReader
+			checkError := func(err error) {
+				var columnErr *ColumnError
+				if assert.True(t, errors.As(err, &columnErr), "%v", err) {
+					assert.Equal(t, pr.SchemaHandler.ValueColumns[column], columnErr.Path)
+					assert.Equal(t, int64(0), columnErr.RowGroupIndex)
+					assert.Equal(t, tc.pageOffset, columnErr.PageOffset)
+					assert.Contains(t, err.Error(), fmt.Sprintf("page offset %v", tc.pageOffset))
+				}
+			}
+
+			pr, err := NewParquetReader(tc.file(), new(genericRecord), 2)
+			assert.NoError(t, err)
+			res := make([]genericRecord, 100)
+			checkError(pr.Read(&res))
+
+			pr, err = NewParquetReader(tc.file(), new(genericRecord), 2)
+			assert.NoError(t, err)
+			checkError(pr.SkipRows(50))
+
+			pr, err = NewParquetColumnReader(tc.file(), 1)
+			assert.NoError(t, err)
+			_, _, _, err = pr.ReadColumnByPath(pr.SchemaHandler.ValueColumns[column], 100)
+			checkError(err)
+
+			pr, err = NewParquetColumnReader(tc.file(), 1)
+			assert.NoError(t, err)
+			checkError(pr.SkipRowsByIndex(int64(column), 50))
+		})
+	}
+}
+
+func TestReaderLimits(t *testing.T) {
+	data, _ := writeCorruptionFixture(t)
+	newFile := func() source.ParquetFile {
+		pf, _ := buffer.NewBufferFile(data)
+		return pf
+	}
+
+	_, err := NewParquetReader(newFile(), new(genericRecord), 1, ParquetReaderOptions{MaxFooterSize: 16})
+	assert.ErrorContains(t, err, "larger than the limit")
+
+	//the footer size can't be larger than the file
+	footerSize := append([]byte{}, data...)
+	copy(footerSize[len(data)-8:], []byte{0xff, 0xff, 0xff, 0x0f})
+	pf, _ := buffer.NewBufferFile(footerSize)
+	_, err = NewParquetReader(pf, new(genericRecord), 1)
+	assert.Error(t, err)
+
+	testCases := []struct {
+		name string
+		opts ParquetReaderOptions
+	}{
+		{"page size", ParquetReaderOptions{MaxPageSize: 16}},
+		{"page values", ParquetReaderOptions{MaxPageValues: 10}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr, err := NewParquetReader(newFile(), new(genericRecord), 1, tc.opts)
+			assert.NoError(t, err)
+			res := make([]genericRecord, 100)
+			err = pr.Read(&res)
+			var columnErr *ColumnError
+			assert.True(t, errors.As(err, &columnErr), "%v", err)
+			assert.ErrorContains(t, err, "limit")
+
+			//no limit
+			tc.opts.MaxPageSize, tc.opts.MaxPageValues = -1, -1
+			pr, err = NewParquetReader(newFile(), new(genericRecord), 1, tc.opts)
+			assert.NoError(t, err)
+			assert.NoError(t, pr.Read(&res))
+		})
+	}
+}
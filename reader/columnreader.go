@@ -3,15 +3,24 @@ package reader
 import (
 	"fmt"
 
+	"github.com/xitongsys/parquet-go/marshal"
 	"github.com/xitongsys/parquet-go/schema"
 	"github.com/xitongsys/parquet-go/source"
 )
 
 // NewParquetColumnReader creates a parquet column reader
-func NewParquetColumnReader(pFile source.ParquetFile, np int64) (*ParquetReader, error) {
+func NewParquetColumnReader(pFile source.ParquetFile, np int64, opts ...ParquetReaderOptions) (*ParquetReader, error) {
 	res := new(ParquetReader)
 	res.NP = np
 	res.PFile = pFile
+	res.UnmarshalFunc = marshal.Unmarshal
+	var options ParquetReaderOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	res.decryptionProperties = options.FileDecryptionProperties
+	res.maxFooterSize = options.maxFooterSize()
+	res.pageLimits = options.pageLimits()
 	if err := res.ReadFooter(); err != nil {
 		return nil, err
 	}
@@ -36,27 +45,25 @@ func (pr *ParquetReader) SkipRowsByPath(pathStr string, num int64) error {
 
 	if _, ok := pr.ColumnBuffers[pathStr]; !ok {
 		var err error
-		if pr.ColumnBuffers[pathStr], err = NewColumnBuffer(pr.PFile, pr.Footer, pr.SchemaHandler, pathStr); err != nil {
+		if pr.ColumnBuffers[pathStr], err = pr.newColumnBuffer(pathStr); err != nil {
 			return err
 		}
 	}
 
 	if cb, ok := pr.ColumnBuffers[pathStr]; ok {
-		cb.SkipRows(int64(num))
-
-	} else {
-		return errPathNotFound
+		_, err := cb.SkipRows(int64(num))
+		return err
 	}
-
-	return nil
+	return errPathNotFound
 }
 
-func (pr *ParquetReader) SkipRowsByIndex(index int64, num int64) {
+// SkipRowsByIndex skips rows of the column by index. The index of first column is 0.
+func (pr *ParquetReader) SkipRowsByIndex(index int64, num int64) error {
 	if index >= int64(len(pr.SchemaHandler.ValueColumns)) {
-		return
+		return fmt.Errorf("index %v out of range %v", index, len(pr.SchemaHandler.ValueColumns))
 	}
 	pathStr := pr.SchemaHandler.ValueColumns[index]
-	pr.SkipRowsByPath(pathStr, num)
+	return pr.SkipRowsByPath(pathStr, num)
 }
 
 // ReadColumnByPath reads column by path in schema.
@@ -74,13 +81,16 @@ func (pr *ParquetReader) ReadColumnByPath(pathStr string, num int64) (values []i
 
 	if _, ok := pr.ColumnBuffers[pathStr]; !ok {
 		var err error
-		if pr.ColumnBuffers[pathStr], err = NewColumnBuffer(pr.PFile, pr.Footer, pr.SchemaHandler, pathStr); err != nil {
+		if pr.ColumnBuffers[pathStr], err = pr.newColumnBuffer(pathStr); err != nil {
 			return []interface{}{}, []int32{}, []int32{}, err
 		}
 	}
 
 	if cb, ok := pr.ColumnBuffers[pathStr]; ok {
-		table, _ := cb.ReadRows(int64(num))
+		table, _, err := cb.ReadRows(int64(num))
+		if err != nil {
+			return []interface{}{}, []int32{}, []int32{}, err
+		}
 		return table.Values, table.RepetitionLevels, table.DefinitionLevels, nil
 	}
 	return []interface{}{}, []int32{}, []int32{}, errPathNotFound
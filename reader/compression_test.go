@@ -0,0 +1,86 @@
+package reader
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+)
+
+// testdata/brotli.parquet is written by testdata/brotli with the pages compressed by libbrotlienc
+func TestBrotliFixture(t *testing.T) {
+	type Record struct {
+		Id       int64     `parquet:"name=id, type=INT64"`
+		Name     string    `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Category *string   `parquet:"name=category, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		Scores   []float64 `parquet:"name=scores, type=DOUBLE, repetitiontype=REPEATED"`
+	}
+	records := make([]Record, 2000)
+	for i := range records {
+		records[i] = Record{Id: int64(i), Name: fmt.Sprintf("name_%d", i)}
+		if i%3 != 0 {
+			category := fmt.Sprintf("category_%d", i%7)
+			records[i].Category = &category
+		}
+		for j := 0; j < i%4; j++ {
+			records[i].Scores = append(records[i].Scores, float64(i)+float64(j)/4)
+		}
+	}
+
+	fr, err := local.NewLocalFileReader("testdata/brotli.parquet")
+	assert.NoError(t, err)
+	defer fr.Close()
+	pr, err := NewParquetReader(fr, new(Record), 2)
+	assert.NoError(t, err)
+	assert.Len(t, pr.Footer.RowGroups, 2)
+	for _, rowGroup := range pr.Footer.RowGroups {
+		for _, chunk := range rowGroup.Columns {
+			assert.Equal(t, parquet.CompressionCodec_BROTLI, chunk.MetaData.Codec)
+		}
+	}
+	res := make([]Record, len(records))
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, records, res)
+	pr.ReadStop()
+}
+
+// testdata/lzo.parquet is written by parquet-mr with the LzoCodec of hadoop-lzo and
+// testdata/lzo_uncompressed.parquet by the same writer with the same rows without compression,
+// see testdata/README.md. They aren't in the repository, the test is skipped until they are added.
+func TestLzoFixture(t *testing.T) {
+	for _, name := range []string{"testdata/lzo.parquet", "testdata/lzo_uncompressed.parquet"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Skipf("%v isn't in testdata", name)
+		}
+	}
+	open := func(name string) *ParquetReader {
+		fr, err := local.NewLocalFileReader(name)
+		assert.NoError(t, err)
+		t.Cleanup(func() { fr.Close() })
+		pr, err := NewParquetColumnReader(fr, 1)
+		assert.NoError(t, err)
+		return pr
+	}
+	pr, expected := open("testdata/lzo.parquet"), open("testdata/lzo_uncompressed.parquet")
+	for _, rowGroup := range pr.Footer.RowGroups {
+		for _, chunk := range rowGroup.Columns {
+			assert.Equal(t, parquet.CompressionCodec_LZO, chunk.MetaData.Codec)
+		}
+	}
+	numRows := pr.GetNumRows()
+	assert.Equal(t, expected.GetNumRows(), numRows)
+	for _, path := range pr.SchemaHandler.ValueColumns {
+		values, rls, dls, err := pr.ReadColumnByPath(path, numRows)
+		assert.NoError(t, err, path)
+		expectedValues, expectedRls, expectedDls, err := expected.ReadColumnByPath(path, numRows)
+		assert.NoError(t, err, path)
+		assert.Equal(t, expectedValues, values, path)
+		assert.Equal(t, expectedRls, rls, path)
+		assert.Equal(t, expectedDls, dls, path)
+	}
+	pr.ReadStop()
+	expected.ReadStop()
+}
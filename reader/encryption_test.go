@@ -0,0 +1,283 @@
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/encryption"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type encryptedRecord struct {
+	ID    int64   `parquet:"name=id, type=INT64, bloomfilter=true"`
+	Name  string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Score *int32  `parquet:"name=score, type=INT32"`
+	Tags  []int32 `parquet:"name=tags, type=INT32, repetitiontype=REPEATED"`
+}
+
+var (
+	footerKey = []byte("0123456789012345")
+	idKey     = []byte("1234567890123450")
+	nameKey   = []byte("2345678901234501")
+)
+
+func encryptedRecords(n int) []encryptedRecord {
+	res := make([]encryptedRecord, n)
+	for i := range res {
+		res[i] = encryptedRecord{ID: int64(i), Name: fmt.Sprintf("name_%d", i%7), Tags: []int32{int32(i), int32(i + 1)}}
+		if i%3 != 0 {
+			score := int32(i)
+			res[i].Score = &score
+		}
+	}
+	return res
+}
+
+func writeEncryptedFile(t *testing.T, props *encryption.FileEncryptionProperties, records []encryptedRecord) []byte {
+	var buf bytes.Buffer
+	pw, err := writer.NewParquetWriterFromWriter(&buf, new(encryptedRecord), 2, writer.ParquetWriterOptions{Encryption: props})
+	assert.NoError(t, err)
+	pw.PageSize = 256
+	for i, record := range records {
+		assert.NoError(t, pw.Write(record))
+		if i%200 == 199 {
+			assert.NoError(t, pw.Flush(true))
+		}
+	}
+	assert.NoError(t, pw.WriteStop())
+	return buf.Bytes()
+}
+
+func readEncryptedFile(data []byte, props *encryption.FileDecryptionProperties) ([]encryptedRecord, error) {
+	pf, err := buffer.NewBufferFile(data)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := NewParquetReader(pf, new(encryptedRecord), 2, ParquetReaderOptions{FileDecryptionProperties: props})
+	if err != nil {
+		return nil, err
+	}
+	defer pr.ReadStop()
+	res := make([]encryptedRecord, pr.GetNumRows())
+	if err = pr.Read(&res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func columnKeys() map[string]*encryption.ColumnEncryptionProperties {
+	return map[string]*encryption.ColumnEncryptionProperties{
+		"id":    {Key: idKey, KeyMetadata: []byte("id_key")},
+		"name":  {Key: nameKey, KeyMetadata: []byte("name_key")},
+		"score": {},
+	}
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	records := encryptedRecords(500)
+	keys := map[string][]byte{"footer_key": footerKey, "id_key": idKey, "name_key": nameKey}
+	retriever := encryption.KeyRetrieverFunc(func(keyMetadata []byte) ([]byte, error) {
+		return keys[string(keyMetadata)], nil
+	})
+
+	testCases := []struct {
+		name       string
+		encryption *encryption.FileEncryptionProperties
+		decryption *encryption.FileDecryptionProperties
+	}{
+		{
+			name:       "uniform encryption",
+			encryption: &encryption.FileEncryptionProperties{FooterKey: footerKey},
+			decryption: &encryption.FileDecryptionProperties{FooterKey: footerKey},
+		},
+		{
+			name:       "column keys",
+			encryption: &encryption.FileEncryptionProperties{FooterKey: footerKey, Columns: columnKeys()},
+			decryption: &encryption.FileDecryptionProperties{FooterKey: footerKey, ColumnKeys: map[string][]byte{"id": idKey, "name": nameKey}},
+		},
+		{
+			name:       "key retriever",
+			encryption: &encryption.FileEncryptionProperties{FooterKey: footerKey, FooterKeyMetadata: []byte("footer_key"), Columns: columnKeys()},
+			decryption: &encryption.FileDecryptionProperties{KeyRetriever: retriever},
+		},
+		{
+			name:       "plaintext footer",
+			encryption: &encryption.FileEncryptionProperties{FooterKey: footerKey, FooterKeyMetadata: []byte("footer_key"), PlaintextFooter: true, Columns: columnKeys()},
+			decryption: &encryption.FileDecryptionProperties{KeyRetriever: retriever},
+		},
+		{
+			name:       "AAD prefix",
+			encryption: &encryption.FileEncryptionProperties{FooterKey: footerKey, AADPrefix: []byte("file_1")},
+			decryption: &encryption.FileDecryptionProperties{FooterKey: footerKey},
+		},
+		{
+			name:       "supplied AAD prefix",
+			encryption: &encryption.FileEncryptionProperties{FooterKey: footerKey, AADPrefix: []byte("file_1"), DisableAADPrefixStorage: true, Columns: columnKeys()},
+			decryption: &encryption.FileDecryptionProperties{FooterKey: footerKey, ColumnKeys: map[string][]byte{"id": idKey, "name": nameKey}, AADPrefix: []byte("file_1")},
+		},
+		{
+			name:       "AES GCM CTR",
+			encryption: &encryption.FileEncryptionProperties{Algorithm: encryption.AesGcmCtrV1, FooterKey: footerKey, PlaintextFooter: true, Columns: columnKeys()},
+			decryption: &encryption.FileDecryptionProperties{FooterKey: footerKey, ColumnKeys: map[string][]byte{"id": idKey, "name": nameKey}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := writeEncryptedFile(t, tc.encryption, records)
+			assert.False(t, bytes.Contains(data, []byte("name_3")))
+			if tc.encryption.PlaintextFooter {
+				assert.Equal(t, []byte("PAR1"), data[len(data)-4:])
+			} else {
+				assert.Equal(t, []byte("PARE"), data[:4])
+				assert.Equal(t, []byte("PARE"), data[len(data)-4:])
+			}
+
+			res, err := readEncryptedFile(data, tc.decryption)
+			assert.NoError(t, err)
+			assert.Equal(t, records, res)
+
+			_, err = readEncryptedFile(data, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEncryptionIndexes(t *testing.T) {
+	records := encryptedRecords(600)
+	data := writeEncryptedFile(t, &encryption.FileEncryptionProperties{FooterKey: footerKey, Columns: columnKeys()}, records)
+	pf, err := buffer.NewBufferFile(data)
+	assert.NoError(t, err)
+	pr, err := NewParquetReader(pf, new(encryptedRecord), 1, ParquetReaderOptions{
+		FileDecryptionProperties: &encryption.FileDecryptionProperties{FooterKey: footerKey, ColumnKeys: map[string][]byte{"id": idKey, "name": nameKey}},
+	})
+	assert.NoError(t, err)
+	defer pr.ReadStop()
+	assert.Equal(t, 3, len(pr.Footer.RowGroups))
+
+	id := common.ReformPathStr("parquet_go_root.id")
+	pageIndex, err := pr.ReadPageIndex(1, id)
+	assert.NoError(t, err)
+	assert.True(t, len(pageIndex.Pages) > 1)
+	assert.Equal(t, int64(200), pageIndex.Pages[0].MinValue)
+
+	ok, err := pr.BloomFilterMayContain(1, id, int64(250))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.NoError(t, pr.SkipRows(290))
+	res := make([]encryptedRecord, 20)
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, records[290:310], res)
+
+	assert.NoError(t, pr.SetFilter(Eq(id, int64(450))))
+	res = make([]encryptedRecord, 10)
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, records[450:451], res)
+}
+
+func TestEncryptionErrors(t *testing.T) {
+	records := encryptedRecords(100)
+	plaintextFooter := writeEncryptedFile(t, &encryption.FileEncryptionProperties{FooterKey: footerKey, PlaintextFooter: true, Columns: columnKeys()}, records)
+	encryptedFooter := writeEncryptedFile(t, &encryption.FileEncryptionProperties{FooterKey: footerKey, Columns: columnKeys()}, records)
+
+	// wrong keys
+	otherKey := []byte("5432109876543210")
+	_, err := readEncryptedFile(encryptedFooter, &encryption.FileDecryptionProperties{FooterKey: otherKey})
+	assert.Error(t, err)
+	_, err = readEncryptedFile(plaintextFooter, &encryption.FileDecryptionProperties{FooterKey: otherKey})
+	assert.Error(t, err)
+	_, err = readEncryptedFile(encryptedFooter, &encryption.FileDecryptionProperties{FooterKey: footerKey, ColumnKeys: map[string][]byte{"id": idKey, "name": otherKey}})
+	assert.Error(t, err)
+
+	// missing column key
+	_, err = readEncryptedFile(encryptedFooter, &encryption.FileDecryptionProperties{FooterKey: footerKey, ColumnKeys: map[string][]byte{"id": idKey}})
+	assert.Error(t, err)
+
+	// plaintext columns can be read without the keys when the footer is in plaintext
+	pf, err := buffer.NewBufferFile(plaintextFooter)
+	assert.NoError(t, err)
+	pr, err := NewParquetColumnReader(pf, 1)
+	assert.NoError(t, err)
+	tags, _, _, err := pr.ReadColumnByPath(common.ReformPathStr("parquet_go_root.tags"), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int32(0), int32(1), int32(1), int32(2), int32(2), int32(3), int32(3), int32(4)}, tags)
+	_, _, _, err = pr.ReadColumnByPath(common.ReformPathStr("parquet_go_root.score"), 4)
+	assert.Error(t, err)
+	pr.ReadStop()
+
+	// tampered footer
+	tampered := append([]byte{}, plaintextFooter...)
+	tampered[len(tampered)-8-encryption.SignatureLength-5]++
+	_, err = readEncryptedFile(tampered, &encryption.FileDecryptionProperties{FooterKey: footerKey, ColumnKeys: map[string][]byte{"id": idKey, "name": nameKey}})
+	assert.Error(t, err)
+
+	// plaintext files
+	var buf bytes.Buffer
+	pw, err := writer.NewParquetWriterFromWriter(&buf, new(encryptedRecord), 1)
+	assert.NoError(t, err)
+	assert.NoError(t, pw.Write(records[0]))
+	assert.NoError(t, pw.WriteStop())
+	_, err = readEncryptedFile(buf.Bytes(), &encryption.FileDecryptionProperties{FooterKey: footerKey})
+	assert.Error(t, err)
+	res, err := readEncryptedFile(buf.Bytes(), &encryption.FileDecryptionProperties{FooterKey: footerKey, PlaintextFilesAllowed: true})
+	assert.NoError(t, err)
+	assert.Equal(t, records[:1], res)
+
+	// unknown encrypted column
+	props := &encryption.FileEncryptionProperties{FooterKey: footerKey, Columns: map[string]*encryption.ColumnEncryptionProperties{"unknown": {}}}
+	pw, err = writer.NewParquetWriterFromWriter(&buf, new(encryptedRecord), 1, writer.ParquetWriterOptions{Encryption: props})
+	assert.NoError(t, err)
+	assert.NoError(t, pw.Write(records[0]))
+	assert.Error(t, pw.WriteStop())
+}
+
+// Files of apache/parquet-testing written by parquet-mr with its published keys. They aren't
+// in the repository, the test reads the ones copied to testdata.
+func TestEncryptionFixtures(t *testing.T) {
+	props := func(footerKey []byte) *encryption.FileDecryptionProperties {
+		return &encryption.FileDecryptionProperties{
+			FooterKey: footerKey,
+			ColumnKeys: map[string][]byte{
+				"double_field": []byte("1234567890123450"),
+				"float_field":  []byte("1234567890123451"),
+			},
+		}
+	}
+	for _, name := range []string{"encrypt_columns_and_footer.parquet.encrypted", "encrypt_columns_plaintext_footer.parquet.encrypted"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join("testdata", name)
+			if _, err := os.Stat(path); err != nil {
+				t.Skipf("%v isn't in testdata", name)
+			}
+			data, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			pf, err := buffer.NewBufferFile(data)
+			assert.NoError(t, err)
+			pr, err := NewParquetColumnReader(pf, 1, ParquetReaderOptions{FileDecryptionProperties: props([]byte("0123456789012345"))})
+			if !assert.NoError(t, err) {
+				return
+			}
+			numRows := pr.GetNumRows()
+			assert.Greater(t, numRows, int64(0))
+			//the pages of all the columns are decrypted and their AES-GCM tags verified
+			for _, path := range pr.SchemaHandler.ValueColumns {
+				_, rls, _, err := pr.ReadColumnByPath(path, numRows)
+				assert.NoError(t, err, path)
+				if maxRL, _ := pr.SchemaHandler.MaxRepetitionLevel(common.StrToPath(path)); maxRL == 0 {
+					assert.Len(t, rls, int(numRows), path)
+				}
+			}
+			pr.ReadStop()
+
+			_, err = NewParquetColumnReader(pf, 1, ParquetReaderOptions{FileDecryptionProperties: props([]byte("0000000000000000"))})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,671 @@
+package reader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xitongsys/parquet-go/bloomfilter"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/encoding"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+	"github.com/xitongsys/parquet-go/types"
+)
+
+// FilterExpr is a predicate over leaf columns of a parquet file.
+//
+// A filter set by ParquetReader.SetFilter is used to skip row groups using the
+// column chunk statistics and bloom filters, to skip pages using the ColumnIndex/OffsetIndex and
+// finally to drop the rows which don't match before they are unmarshalled.
+//
+// Column paths are the same as in ReadColumnByPath, e.g.
+// common.ReformPathStr("parquet_go_root.name"). Values must be convertible to the
+// go type of the column's physical type (int32, int64, float32, float64, bool or
+// string). A comparison never matches a null value and, for repeated columns, a
+// row matches if any of its values matches.
+type FilterExpr interface {
+	// bind resolves the column paths and converts the values to the column types
+	bind(sh *schema.SchemaHandler) error
+	// columns appends the in-paths of the columns used by the expression
+	columns(paths []string) []string
+	// not returns the negation of the expression
+	not() FilterExpr
+	// rowRanges returns the rows of a row group that may match the expression
+	rowRanges(env *filterEnv) []RowRange
+	// match reports whether a row matches the expression
+	match(row func(pathStr string) []interface{}) bool
+}
+
+// RowRange is a half-open range [From, To) of row indexes in a row group
+type RowRange struct {
+	From int64
+	To   int64
+}
+
+type compareOp int
+
+const (
+	opEq compareOp = iota
+	opNotEq
+	opLt
+	opLtEq
+	opGt
+	opGtEq
+)
+
+// Eq matches rows where the column is equal to value
+func Eq(path string, value interface{}) FilterExpr {
+	return &compareExpr{filterColumn: filterColumn{path: path}, op: opEq, value: value}
+}
+
+// NotEq matches rows where the column is not null and not equal to value
+func NotEq(path string, value interface{}) FilterExpr {
+	return &compareExpr{filterColumn: filterColumn{path: path}, op: opNotEq, value: value}
+}
+
+// Lt matches rows where the column is less than value
+func Lt(path string, value interface{}) FilterExpr {
+	return &compareExpr{filterColumn: filterColumn{path: path}, op: opLt, value: value}
+}
+
+// LtEq matches rows where the column is less than or equal to value
+func LtEq(path string, value interface{}) FilterExpr {
+	return &compareExpr{filterColumn: filterColumn{path: path}, op: opLtEq, value: value}
+}
+
+// Gt matches rows where the column is greater than value
+func Gt(path string, value interface{}) FilterExpr {
+	return &compareExpr{filterColumn: filterColumn{path: path}, op: opGt, value: value}
+}
+
+// GtEq matches rows where the column is greater than or equal to value
+func GtEq(path string, value interface{}) FilterExpr {
+	return &compareExpr{filterColumn: filterColumn{path: path}, op: opGtEq, value: value}
+}
+
+// In matches rows where the column is equal to one of values
+func In(path string, values ...interface{}) FilterExpr {
+	return &inExpr{filterColumn: filterColumn{path: path}, values: values}
+}
+
+// NotIn matches rows where the column is not null and not equal to any of values
+func NotIn(path string, values ...interface{}) FilterExpr {
+	return &inExpr{filterColumn: filterColumn{path: path}, values: values, negated: true}
+}
+
+// IsNull matches rows where the column is null
+func IsNull(path string) FilterExpr {
+	return &nullExpr{filterColumn: filterColumn{path: path}}
+}
+
+// IsNotNull matches rows where the column is not null
+func IsNotNull(path string) FilterExpr {
+	return &nullExpr{filterColumn: filterColumn{path: path}, negated: true}
+}
+
+// And matches rows which match all of exprs
+func And(exprs ...FilterExpr) FilterExpr {
+	return &andExpr{exprs: exprs}
+}
+
+// Or matches rows which match any of exprs
+func Or(exprs ...FilterExpr) FilterExpr {
+	return &orExpr{exprs: exprs}
+}
+
+// Not matches rows which don't match expr. The negation is pushed down to the
+// comparisons, so Not(Lt(path, v)) is the same as GtEq(path, v) and doesn't
+// match null values.
+func Not(expr FilterExpr) FilterExpr {
+	return expr.not()
+}
+
+// filterColumn is the column used by a leaf expression
+type filterColumn struct {
+	path      string
+	inPath    string
+	schema    *parquet.SchemaElement
+	funcTable common.FuncTable
+}
+
+func (c *filterColumn) bind(sh *schema.SchemaHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("filter on column %v: %v", c.path, r)
+		}
+	}()
+
+	if c.inPath, err = sh.ConvertToInPathStr(c.path); err != nil {
+		return err
+	}
+	index, ok := sh.MapIndex[c.inPath]
+	if !ok {
+		return fmt.Errorf("path %v not found", c.path)
+	}
+	c.schema = sh.SchemaElements[index]
+	if c.schema.GetNumChildren() > 0 || c.schema.Type == nil {
+		return fmt.Errorf("path %v is not a leaf column", c.path)
+	}
+	c.funcTable = common.FindFuncTable(c.schema.Type, c.schema.ConvertedType, c.schema.LogicalType)
+	return nil
+}
+
+// Convert a filter value to the go type of the column
+func (c *filterColumn) convert(value interface{}) (res interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("filter on column %v: can't convert %v (%T) to %v", c.path, value, value, c.schema.GetType())
+		}
+	}()
+
+	if value == nil {
+		return nil, fmt.Errorf("filter on column %v: nil value, use IsNull instead", c.path)
+	}
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	return types.InterfaceToParquetType(value, c.schema.Type), nil
+}
+
+func (c *filterColumn) equal(a, b interface{}) bool {
+	return !c.funcTable.LessThan(a, b) && !c.funcTable.LessThan(b, a)
+}
+
+// rowRanges returns the rows of the row group whose statistics satisfy mayMatch.
+// The page index is used when the column chunk statistics can't rule the row group out.
+func (c *filterColumn) rowRanges(env *filterEnv, mayMatch func(st *columnStats) bool) []RowRange {
+	all := []RowRange{{From: 0, To: env.rowGroup.GetNumRows()}}
+	chunk, ok := env.chunks[c.inPath]
+	if !ok || chunk.MetaData == nil {
+		return all
+	}
+
+	st := chunkStats(chunk.MetaData, c.schema)
+	if !mayMatch(st) {
+		return nil
+	}
+
+	// pages of repeated columns don't start on row boundaries and
+	// columns written without statistics have no usable page index
+	if maxRL, _ := env.sh.MaxRepetitionLevel(common.StrToPath(c.inPath)); maxRL > 0 || st.min == nil || st.max == nil {
+		return all
+	}
+
+	pageIndex, err := env.pr.readPageIndex(env.rowGroup, chunk)
+	if err != nil || pageIndex.ColumnIndex == nil {
+		return all
+	}
+
+	res := make([]RowRange, 0)
+	for _, page := range pageIndex.Pages {
+		st := &columnStats{min: page.MinValue, max: page.MaxValue, nullCount: page.NullCount, allNull: page.NullPage}
+		if mayMatch(st) {
+			res = unionRowRanges(res, []RowRange{{From: page.FirstRowIndex, To: page.FirstRowIndex + page.NumRows}})
+		}
+	}
+	return res
+}
+
+// columnStats are the statistics of a column chunk or a page
+type columnStats struct {
+	min, max interface{}
+	//-1 if unknown
+	nullCount int64
+	allNull   bool
+}
+
+// Decode a plain encoded statistics value
+func decodeStatValue(buf []byte, se *parquet.SchemaElement) interface{} {
+	if buf == nil {
+		return nil
+	}
+	switch se.GetType() {
+	case parquet.Type_BYTE_ARRAY, parquet.Type_FIXED_LEN_BYTE_ARRAY, parquet.Type_INT96:
+		return string(buf)
+	}
+	values, err := encoding.ReadPlain(bytes.NewReader(buf), se.GetType(), 1, 0)
+	if err != nil || len(values) != 1 {
+		return nil
+	}
+	return values[0]
+}
+
+// The deprecated min/max fields are only sorted correctly for signed types
+func signedSortOrder(se *parquet.SchemaElement) bool {
+	switch se.GetType() {
+	case parquet.Type_BOOLEAN, parquet.Type_FLOAT, parquet.Type_DOUBLE:
+		return true
+	case parquet.Type_INT32, parquet.Type_INT64:
+		if se.IsSetConvertedType() && strings.HasPrefix(se.GetConvertedType().String(), "UINT") {
+			return false
+		}
+		if se.LogicalType != nil && se.LogicalType.INTEGER != nil && !se.LogicalType.INTEGER.IsSigned {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+func chunkStats(metaData *parquet.ColumnMetaData, se *parquet.SchemaElement) *columnStats {
+	res := &columnStats{nullCount: -1}
+	statistics := metaData.GetStatistics()
+	if statistics == nil {
+		return res
+	}
+	if statistics.IsSetMinValue() && statistics.IsSetMaxValue() {
+		res.min = decodeStatValue(statistics.MinValue, se)
+		res.max = decodeStatValue(statistics.MaxValue, se)
+	} else if statistics.IsSetMin() && statistics.IsSetMax() && signedSortOrder(se) {
+		res.min = decodeStatValue(statistics.Min, se)
+		res.max = decodeStatValue(statistics.Max, se)
+	}
+	if statistics.IsSetNullCount() {
+		res.nullCount = statistics.GetNullCount()
+		res.allNull = res.nullCount == metaData.GetNumValues()
+	}
+	return res
+}
+
+// compareExpr compares a column with a value
+type compareExpr struct {
+	filterColumn
+	op    compareOp
+	value interface{}
+}
+
+func (e *compareExpr) bind(sh *schema.SchemaHandler) (err error) {
+	if err = e.filterColumn.bind(sh); err != nil {
+		return err
+	}
+	e.value, err = e.convert(e.value)
+	return err
+}
+
+func (e *compareExpr) columns(paths []string) []string {
+	return append(paths, e.inPath)
+}
+
+func (e *compareExpr) not() FilterExpr {
+	negated := map[compareOp]compareOp{
+		opEq:    opNotEq,
+		opNotEq: opEq,
+		opLt:    opGtEq,
+		opLtEq:  opGt,
+		opGt:    opLtEq,
+		opGtEq:  opLt,
+	}
+	return &compareExpr{filterColumn: e.filterColumn, op: negated[e.op], value: e.value}
+}
+
+func (e *compareExpr) mayMatch(st *columnStats) bool {
+	if st.allNull {
+		return false
+	}
+	if st.min == nil || st.max == nil {
+		return true
+	}
+	lt := e.funcTable.LessThan
+	switch e.op {
+	case opEq:
+		return !lt(e.value, st.min) && !lt(st.max, e.value)
+	case opNotEq:
+		return !(e.equal(st.min, e.value) && e.equal(st.max, e.value))
+	case opLt:
+		return lt(st.min, e.value)
+	case opLtEq:
+		return !lt(e.value, st.min)
+	case opGt:
+		return lt(e.value, st.max)
+	case opGtEq:
+		return !lt(st.max, e.value)
+	}
+	return true
+}
+
+func (e *compareExpr) rowRanges(env *filterEnv) []RowRange {
+	res := e.filterColumn.rowRanges(env, e.mayMatch)
+	if len(res) > 0 && e.op == opEq && !env.bloomFilterMayContain(e.inPath, e.value) {
+		return nil
+	}
+	return res
+}
+
+func (e *compareExpr) match(row func(pathStr string) []interface{}) bool {
+	lt := e.funcTable.LessThan
+	for _, v := range row(e.inPath) {
+		if v == nil {
+			continue
+		}
+		var ok bool
+		switch e.op {
+		case opEq:
+			ok = e.equal(v, e.value)
+		case opNotEq:
+			ok = !e.equal(v, e.value)
+		case opLt:
+			ok = lt(v, e.value)
+		case opLtEq:
+			ok = !lt(e.value, v)
+		case opGt:
+			ok = lt(e.value, v)
+		case opGtEq:
+			ok = !lt(v, e.value)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inExpr checks whether a column is in a set of values
+type inExpr struct {
+	filterColumn
+	values  []interface{}
+	negated bool
+}
+
+func (e *inExpr) bind(sh *schema.SchemaHandler) error {
+	if err := e.filterColumn.bind(sh); err != nil {
+		return err
+	}
+	values := make([]interface{}, len(e.values))
+	for i, value := range e.values {
+		var err error
+		if values[i], err = e.convert(value); err != nil {
+			return err
+		}
+	}
+	e.values = values
+	return nil
+}
+
+func (e *inExpr) columns(paths []string) []string {
+	return append(paths, e.inPath)
+}
+
+func (e *inExpr) not() FilterExpr {
+	return &inExpr{filterColumn: e.filterColumn, values: e.values, negated: !e.negated}
+}
+
+func (e *inExpr) contains(v interface{}) bool {
+	for _, value := range e.values {
+		if e.equal(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *inExpr) mayMatch(st *columnStats) bool {
+	if st.allNull {
+		return false
+	}
+	if st.min == nil || st.max == nil {
+		return true
+	}
+	if e.negated {
+		return !(e.equal(st.min, st.max) && e.contains(st.min))
+	}
+	for _, value := range e.values {
+		if !e.funcTable.LessThan(value, st.min) && !e.funcTable.LessThan(st.max, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *inExpr) rowRanges(env *filterEnv) []RowRange {
+	res := e.filterColumn.rowRanges(env, e.mayMatch)
+	if len(res) > 0 && !e.negated && !env.bloomFilterMayContain(e.inPath, e.values...) {
+		return nil
+	}
+	return res
+}
+
+func (e *inExpr) match(row func(pathStr string) []interface{}) bool {
+	for _, v := range row(e.inPath) {
+		if v != nil && e.contains(v) != e.negated {
+			return true
+		}
+	}
+	return false
+}
+
+// nullExpr checks whether a column is null
+type nullExpr struct {
+	filterColumn
+	negated bool
+}
+
+func (e *nullExpr) bind(sh *schema.SchemaHandler) error {
+	return e.filterColumn.bind(sh)
+}
+
+func (e *nullExpr) columns(paths []string) []string {
+	return append(paths, e.inPath)
+}
+
+func (e *nullExpr) not() FilterExpr {
+	return &nullExpr{filterColumn: e.filterColumn, negated: !e.negated}
+}
+
+func (e *nullExpr) mayMatch(st *columnStats) bool {
+	if e.negated {
+		return !st.allNull
+	}
+	return st.nullCount != 0
+}
+
+func (e *nullExpr) rowRanges(env *filterEnv) []RowRange {
+	return e.filterColumn.rowRanges(env, e.mayMatch)
+}
+
+func (e *nullExpr) match(row func(pathStr string) []interface{}) bool {
+	for _, v := range row(e.inPath) {
+		if (v == nil) != e.negated {
+			return true
+		}
+	}
+	return false
+}
+
+type andExpr struct {
+	exprs []FilterExpr
+}
+
+func (e *andExpr) bind(sh *schema.SchemaHandler) error {
+	if len(e.exprs) == 0 {
+		return errors.New("empty And filter")
+	}
+	for _, expr := range e.exprs {
+		if err := expr.bind(sh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *andExpr) columns(paths []string) []string {
+	for _, expr := range e.exprs {
+		paths = expr.columns(paths)
+	}
+	return paths
+}
+
+func (e *andExpr) not() FilterExpr {
+	exprs := make([]FilterExpr, len(e.exprs))
+	for i, expr := range e.exprs {
+		exprs[i] = expr.not()
+	}
+	return &orExpr{exprs: exprs}
+}
+
+func (e *andExpr) rowRanges(env *filterEnv) []RowRange {
+	res := []RowRange{{From: 0, To: env.rowGroup.GetNumRows()}}
+	for _, expr := range e.exprs {
+		if res = intersectRowRanges(res, expr.rowRanges(env)); len(res) == 0 {
+			break
+		}
+	}
+	return res
+}
+
+func (e *andExpr) match(row func(pathStr string) []interface{}) bool {
+	for _, expr := range e.exprs {
+		if !expr.match(row) {
+			return false
+		}
+	}
+	return true
+}
+
+type orExpr struct {
+	exprs []FilterExpr
+}
+
+func (e *orExpr) bind(sh *schema.SchemaHandler) error {
+	if len(e.exprs) == 0 {
+		return errors.New("empty Or filter")
+	}
+	for _, expr := range e.exprs {
+		if err := expr.bind(sh); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *orExpr) columns(paths []string) []string {
+	for _, expr := range e.exprs {
+		paths = expr.columns(paths)
+	}
+	return paths
+}
+
+func (e *orExpr) not() FilterExpr {
+	exprs := make([]FilterExpr, len(e.exprs))
+	for i, expr := range e.exprs {
+		exprs[i] = expr.not()
+	}
+	return &andExpr{exprs: exprs}
+}
+
+func (e *orExpr) rowRanges(env *filterEnv) []RowRange {
+	res := make([]RowRange, 0)
+	for _, expr := range e.exprs {
+		res = unionRowRanges(res, expr.rowRanges(env))
+	}
+	return res
+}
+
+func (e *orExpr) match(row func(pathStr string) []interface{}) bool {
+	for _, expr := range e.exprs {
+		if expr.match(row) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterEnv holds the row group a filter is evaluated against
+type filterEnv struct {
+	pr       *ParquetReader
+	sh       *schema.SchemaHandler
+	rowGroup *parquet.RowGroup
+	chunks   map[string]*parquet.ColumnChunk
+}
+
+func newFilterEnv(pr *ParquetReader, rowGroup *parquet.RowGroup) *filterEnv {
+	env := &filterEnv{
+		pr:       pr,
+		sh:       pr.SchemaHandler,
+		rowGroup: rowGroup,
+		chunks:   make(map[string]*parquet.ColumnChunk),
+	}
+	for _, chunk := range rowGroup.GetColumns() {
+		if chunk.MetaData == nil {
+			continue
+		}
+		path := append([]string{pr.SchemaHandler.GetRootInName()}, chunk.MetaData.GetPathInSchema()...)
+		env.chunks[common.PathToStr(path)] = chunk
+	}
+	return env
+}
+
+// Check the bloom filter of a column chunk, it's false only if none of values is in the chunk
+func (env *filterEnv) bloomFilterMayContain(pathStr string, values ...interface{}) bool {
+	chunk, ok := env.chunks[pathStr]
+	if !ok || chunk.MetaData.BloomFilterOffset == nil {
+		return true
+	}
+	filter, err := env.pr.readBloomFilter(env.rowGroup, chunk)
+	if err != nil {
+		return true
+	}
+	for _, value := range values {
+		if filter.Check(bloomfilter.Hash(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func intersectRowRanges(a, b []RowRange) []RowRange {
+	res := make([]RowRange, 0)
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		from, to := a[i].From, a[i].To
+		if b[j].From > from {
+			from = b[j].From
+		}
+		if b[j].To < to {
+			to = b[j].To
+		}
+		if from < to {
+			res = append(res, RowRange{From: from, To: to})
+		}
+		if a[i].To < b[j].To {
+			i++
+		} else {
+			j++
+		}
+	}
+	return res
+}
+
+func unionRowRanges(a, b []RowRange) []RowRange {
+	res := make([]RowRange, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		var cur RowRange
+		if j >= len(b) || (i < len(a) && a[i].From <= b[j].From) {
+			cur = a[i]
+			i++
+		} else {
+			cur = b[j]
+			j++
+		}
+		if ln := len(res); ln > 0 && cur.From <= res[ln-1].To {
+			if cur.To > res[ln-1].To {
+				res[ln-1].To = cur.To
+			}
+		} else {
+			res = append(res, cur)
+		}
+	}
+	return res
+}
+
+// Check whether [from, to) overlaps any of the ranges
+func overlapRowRanges(ranges []RowRange, from, to int64) bool {
+	for _, r := range ranges {
+		if r.From < to && from < r.To {
+			return true
+		}
+	}
+	return false
+}
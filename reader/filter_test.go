@@ -0,0 +1,177 @@
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type filterRecord struct {
+	Id     int64    `parquet:"name=id, type=INT64"`
+	Tenant string   `parquet:"name=tenant, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Score  *float64 `parquet:"name=score, type=DOUBLE"`
+	Tags   []string `parquet:"name=tags, type=SLICE, convertedtype=LIST, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+}
+
+// Write numRowGroups row groups of rowGroupSize rows with small pages
+func writeFilterFile(t *testing.T, numRowGroups, rowGroupSize int) []byte {
+	var buf bytes.Buffer
+	fw := writerfile.NewWriterFile(&buf)
+	pw, err := writer.NewParquetWriter(fw, new(filterRecord), 1)
+	assert.NoError(t, err)
+	pw.PageSize = 128
+
+	id := int64(0)
+	for i := 0; i < numRowGroups; i++ {
+		for j := 0; j < rowGroupSize; j++ {
+			rec := filterRecord{
+				Id:     id,
+				Tenant: fmt.Sprintf("tenant_%d", id%3),
+				Tags:   []string{fmt.Sprintf("tag_%d", id%5)},
+			}
+			if id%4 != 0 {
+				score := float64(id) / 2
+				rec.Score = &score
+			}
+			assert.NoError(t, pw.Write(rec))
+			id++
+		}
+		assert.NoError(t, pw.Flush(true))
+	}
+	assert.NoError(t, pw.WriteStop())
+	return buf.Bytes()
+}
+
+func readFiltered(t *testing.T, data []byte, expr FilterExpr) (*ParquetReader, []filterRecord) {
+	pf, err := buffer.NewBufferFile(data)
+	assert.NoError(t, err)
+	pr, err := NewParquetReader(pf, new(filterRecord), 2)
+	assert.NoError(t, err)
+	assert.NoError(t, pr.SetFilter(expr))
+
+	res := make([]filterRecord, 0)
+	for {
+		rows := make([]filterRecord, 7)
+		assert.NoError(t, pr.Read(&rows))
+		if len(rows) == 0 {
+			break
+		}
+		res = append(res, rows...)
+	}
+	pr.ReadStop()
+	return pr, res
+}
+
+func ids(rows []filterRecord) []int64 {
+	res := make([]int64, len(rows))
+	for i, row := range rows {
+		res[i] = row.Id
+	}
+	return res
+}
+
+func TestFilterRowGroups(t *testing.T) {
+	data := writeFilterFile(t, 4, 100)
+	id := common.ReformPathStr("parquet_go_root.id")
+
+	pr, rows := readFiltered(t, data, Eq(id, 250))
+	assert.Equal(t, []int64{250}, ids(rows))
+	assert.Equal(t, 1, len(pr.RowRanges))
+	ranges, ok := pr.RowRanges[2]
+	assert.True(t, ok)
+	// pages without row 250 are skipped
+	assert.Equal(t, 1, len(ranges))
+	assert.True(t, ranges[0].From <= 50 && ranges[0].To > 50 && ranges[0].To-ranges[0].From < 100)
+
+	pr, rows = readFiltered(t, data, And(GtEq(id, int32(95)), Lt(id, 105)))
+	assert.Equal(t, []int64{95, 96, 97, 98, 99, 100, 101, 102, 103, 104}, ids(rows))
+	assert.Equal(t, 2, len(pr.RowRanges))
+
+	pr, rows = readFiltered(t, data, Gt(id, 1000))
+	assert.Equal(t, 0, len(rows))
+	assert.Equal(t, 0, len(pr.RowRanges))
+}
+
+func TestFilterRows(t *testing.T) {
+	data := writeFilterFile(t, 2, 60)
+	id := common.ReformPathStr("parquet_go_root.id")
+	tenant := common.ReformPathStr("parquet_go_root.tenant")
+	score := common.ReformPathStr("parquet_go_root.score")
+	tags := common.ReformPathStr("parquet_go_root.tags.list.element")
+
+	expected := func(f func(id int64) bool) []int64 {
+		res := make([]int64, 0)
+		for i := int64(0); i < 120; i++ {
+			if f(i) {
+				res = append(res, i)
+			}
+		}
+		return res
+	}
+
+	testCases := []struct {
+		name     string
+		expr     FilterExpr
+		expected []int64
+	}{
+		{"eq-dict", Eq(tenant, "tenant_1"), expected(func(id int64) bool { return id%3 == 1 })},
+		{"and", And(Gt(id, 100), Eq(tenant, []byte("tenant_2"))), expected(func(id int64) bool { return id > 100 && id%3 == 2 })},
+		{"or", Or(Lt(id, 3), GtEq(id, 118)), []int64{0, 1, 2, 118, 119}},
+		{"in", In(id, 7, 70, 700), []int64{7, 70}},
+		{"not-in", And(NotIn(tenant, "tenant_0", "tenant_1"), Lt(id, 10)), []int64{2, 5, 8}},
+		{"is-null", And(IsNull(score), Lt(id, 20)), []int64{0, 4, 8, 12, 16}},
+		{"not-null", And(IsNotNull(score), LtEq(score, 3.0)), []int64{1, 2, 3, 5, 6}},
+		{"not", And(Not(Or(Gt(id, 5), Eq(id, 2))), NotEq(id, 0)), []int64{1, 3, 4, 5}},
+		{"repeated", And(Eq(tags, "tag_3"), Lt(id, 30)), []int64{3, 8, 13, 18, 23, 28}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, rows := readFiltered(t, data, tc.expr)
+			assert.Equal(t, tc.expected, ids(rows))
+			for _, row := range rows {
+				assert.Equal(t, fmt.Sprintf("tenant_%d", row.Id%3), row.Tenant)
+				assert.Equal(t, []string{fmt.Sprintf("tag_%d", row.Id%5)}, row.Tags)
+			}
+		})
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	data := writeFilterFile(t, 1, 10)
+	pf, err := buffer.NewBufferFile(data)
+	assert.NoError(t, err)
+	pr, err := NewParquetReader(pf, new(filterRecord), 1)
+	assert.NoError(t, err)
+
+	assert.Error(t, pr.SetFilter(Eq(common.ReformPathStr("parquet_go_root.unknown"), 1)))
+	assert.Error(t, pr.SetFilter(Eq(common.ReformPathStr("parquet_go_root.tags"), "a")))
+	assert.Error(t, pr.SetFilter(Eq(common.ReformPathStr("parquet_go_root.id"), "a")))
+	assert.Error(t, pr.SetFilter(Eq(common.ReformPathStr("parquet_go_root.id"), nil)))
+	assert.Error(t, pr.SetFilter(And()))
+
+	// removing the filter reads all the rows again
+	assert.NoError(t, pr.SetFilter(Eq(common.ReformPathStr("parquet_go_root.id"), 3)))
+	assert.NoError(t, pr.SetFilter(nil))
+	rows := make([]filterRecord, 20)
+	assert.NoError(t, pr.Read(&rows))
+	assert.Equal(t, 10, len(rows))
+	pr.ReadStop()
+}
+
+func TestRowRanges(t *testing.T) {
+	a := []RowRange{{0, 10}, {20, 30}, {40, 50}}
+	b := []RowRange{{5, 25}, {45, 60}}
+	assert.Equal(t, []RowRange{{5, 10}, {20, 25}, {45, 50}}, intersectRowRanges(a, b))
+	assert.Equal(t, []RowRange{{0, 30}, {40, 60}}, unionRowRanges(a, b))
+	assert.Equal(t, []RowRange{}, intersectRowRanges(a, nil))
+	assert.Equal(t, a, unionRowRanges(a, nil))
+	assert.True(t, overlapRowRanges(a, 9, 12))
+	assert.False(t, overlapRowRanges(a, 10, 20))
+}
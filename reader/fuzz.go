@@ -0,0 +1,38 @@
+//go:build gofuzz
+// +build gofuzz
+
+package reader
+
+import (
+	"github.com/xitongsys/parquet-go-source/buffer"
+)
+
+const fuzzing = true
+
+// FuzzFooter is a go-fuzz target of the reading of a file, from its footer to the values of its columns:
+//
+//	go-fuzz-build -func FuzzFooter github.com/xitongsys/parquet-go/reader
+//	go-fuzz -bin reader-fuzz.zip -workdir fuzz/reader
+//
+// The corpus is made of small parquet files.
+func FuzzFooter(data []byte) int {
+	pf, err := buffer.NewBufferFile(data)
+	if err != nil {
+		return -1
+	}
+	opts := ParquetReaderOptions{MaxFooterSize: 1 << 20, MaxPageSize: 1 << 20, MaxPageValues: 1 << 16}
+	pr, err := NewParquetColumnReader(pf, 1, opts)
+	if err != nil {
+		return 0
+	}
+	for _, pathStr := range pr.SchemaHandler.ValueColumns {
+		pr.ReadColumnByPath(pathStr, 1024)
+	}
+	if pr, err = NewParquetColumnReader(pf, 1, opts); err != nil {
+		return 1
+	}
+	for i := range pr.SchemaHandler.ValueColumns {
+		pr.SkipRowsByIndex(int64(i), 10)
+	}
+	return 1
+}
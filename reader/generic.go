@@ -0,0 +1,41 @@
+package reader
+
+import (
+	"io"
+
+	"github.com/xitongsys/parquet-go/marshal"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// GenericReader is a ParquetReader of the rows of type T, the schema is built from the tags of T.
+// The decoders of T are compiled once and reused for all the rows, see marshal.FastUnmarshaler.
+type GenericReader[T any] struct {
+	*ParquetReader
+}
+
+// Create a parquet reader of the rows of type T
+func NewGenericReader[T any](pFile source.ParquetFile, np int64, opts ...ParquetReaderOptions) (*GenericReader[T], error) {
+	pr, err := NewParquetReader(pFile, new(T), np, opts...)
+	if err != nil {
+		return nil, err
+	}
+	pr.UnmarshalFunc = new(marshal.FastUnmarshaler).Unmarshal
+	return &GenericReader[T]{pr}, nil
+}
+
+// Read rows of parquet file to buf, it returns the number of rows read.
+// The number is less than len(buf) at the end of the file, and it's 0 with io.EOF when no row is left.
+func (r *GenericReader[T]) Read(buf []T) (int, error) {
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	//the rows are unmarshaled to the array of buf
+	rows := buf
+	if err := r.ParquetReader.Read(&rows); err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, io.EOF
+	}
+	return len(rows), nil
+}
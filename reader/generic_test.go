@@ -0,0 +1,72 @@
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type genericRecord struct {
+	ID    int64    `parquet:"name=id, type=INT64"`
+	Name  string   `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Score *float64 `parquet:"name=score, type=DOUBLE"`
+	Tags  []string `parquet:"name=tags, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REPEATED"`
+}
+
+type genericMapRecord struct {
+	ID    int64            `parquet:"name=id, type=INT64"`
+	Attrs map[string]int32 `parquet:"name=attrs, type=MAP, convertedtype=MAP, keytype=BYTE_ARRAY, keyconvertedtype=UTF8, valuetype=INT32"`
+}
+
+func testGenericReaderWriter[T any](t *testing.T, records []T) {
+	var buf bytes.Buffer
+	pw, err := writer.NewGenericWriterFromWriter[T](&buf, 4)
+	assert.NoError(t, err)
+	pw.PageSize = 1024
+	n, err := pw.Write(records[:400])
+	assert.NoError(t, err)
+	assert.Equal(t, 400, n)
+	n, err = pw.Write(records[400:])
+	assert.NoError(t, err)
+	assert.Equal(t, len(records)-400, n)
+	assert.NoError(t, pw.WriteStop())
+
+	pf, err := buffer.NewBufferFile(buf.Bytes())
+	assert.NoError(t, err)
+	pr, err := NewGenericReader[T](pf, 4)
+	assert.NoError(t, err)
+	defer pr.ReadStop()
+
+	res := make([]T, 0, len(records))
+	rows := make([]T, 300)
+	for {
+		n, err := pr.Read(rows)
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		res = append(res, rows[:n]...)
+	}
+	assert.Equal(t, records, res)
+}
+
+func TestGenericReaderWriter(t *testing.T) {
+	records := make([]genericRecord, 1000)
+	mapRecords := make([]genericMapRecord, 1000)
+	for i := range records {
+		records[i] = genericRecord{ID: int64(i), Name: fmt.Sprintf("name_%d", i), Tags: []string{"a", fmt.Sprint(i)}}
+		if i%2 == 0 {
+			score := float64(i) / 2
+			records[i].Score = &score
+		}
+		mapRecords[i] = genericMapRecord{ID: int64(i), Attrs: map[string]int32{"i": int32(i)}}
+	}
+
+	testGenericReaderWriter(t, records)
+	testGenericReaderWriter(t, mapRecords)
+}
@@ -0,0 +1,93 @@
+package reader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+func TestMmapFile(t *testing.T) {
+	type Record struct {
+		Id   int64   `parquet:"name=id, type=INT64"`
+		Name string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Tag  *string `parquet:"name=tag, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	}
+	records := make([]Record, 1000)
+	for i := range records {
+		records[i] = Record{Id: int64(i), Name: fmt.Sprintf("name_%d", i)}
+		if i%3 != 0 {
+			tag := fmt.Sprintf("tag_%d", i%7)
+			records[i].Tag = &tag
+		}
+	}
+
+	dir := t.TempDir()
+	for _, codec := range []parquet.CompressionCodec{parquet.CompressionCodec_UNCOMPRESSED, parquet.CompressionCodec_SNAPPY} {
+		var buf bytes.Buffer
+		pw, err := writer.NewParquetWriter(writerfile.NewWriterFile(&buf), new(Record), 1)
+		assert.NoError(t, err)
+		pw.CompressionType = codec
+		pw.PageSize = 1024
+		for i, record := range records {
+			assert.NoError(t, pw.Write(record))
+			if i == 600 {
+				assert.NoError(t, pw.Flush(true))
+			}
+		}
+		assert.NoError(t, pw.WriteStop())
+		path := filepath.Join(dir, codec.String()+".parquet")
+		assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+
+		for _, views := range []bool{false, true} {
+			file, err := source.NewMmapFile(path)
+			assert.NoError(t, err)
+			pr, err := NewParquetReader(file, new(Record), 2, ParquetReaderOptions{MappedViews: views})
+			assert.NoError(t, err)
+			res := make([]Record, len(records))
+			assert.NoError(t, pr.Read(&res))
+			assert.Equal(t, records, res)
+
+			//the PLAIN values of the uncompressed pages are views of the mapping with MappedViews
+			mapping := file.Bytes()
+			start := uintptr(unsafe.Pointer(&mapping[0]))
+			name := (*reflect.StringHeader)(unsafe.Pointer(&res[0].Name)).Data
+			inMapping := start <= name && name < start+uintptr(len(mapping))
+			assert.Equal(t, views && codec == parquet.CompressionCodec_UNCOMPRESSED, inMapping)
+
+			//the file is only unmapped by ReadStop without views, the values can still be used
+			pr.ReadStop()
+			assert.Equal(t, views, file.Bytes() != nil)
+			assert.Equal(t, records, res)
+			assert.NoError(t, file.Unmap())
+			_, err = file.Read(make([]byte, 1))
+			assert.Error(t, err)
+		}
+
+		//Rows calls ReadStop at the end of the rows
+		file, err := source.NewMmapFile(path)
+		assert.NoError(t, err)
+		pr, err := NewParquetReader(file, new(Record), 2)
+		assert.NoError(t, err)
+		rows := pr.Rows(context.Background())
+		var res []Record
+		for rows.Next() {
+			var record Record
+			assert.NoError(t, rows.Scan(&record))
+			res = append(res, record)
+		}
+		assert.NoError(t, rows.Err())
+		assert.Nil(t, file.Bytes())
+		assert.Equal(t, records, res)
+	}
+}
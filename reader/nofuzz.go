@@ -0,0 +1,6 @@
+//go:build !gofuzz
+// +build !gofuzz
+
+package reader
+
+const fuzzing = false
@@ -0,0 +1,218 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/encryption"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// PageIndex is the page index of a column chunk
+type PageIndex struct {
+	//nil if the column chunk has no ColumnIndex
+	ColumnIndex *parquet.ColumnIndex
+	OffsetIndex *parquet.OffsetIndex
+	//One entry for each data page
+	Pages []PageInfo
+}
+
+// PageInfo describes a data page of a column chunk
+type PageInfo struct {
+	//Location of the page in the file
+	Offset             int64
+	CompressedPageSize int32
+	//Index of the first row of the page in the row group and number of rows of the page
+	FirstRowIndex int64
+	NumRows       int64
+
+	//Min/Max values of the page. They are nil if unknown or if the page only has nulls
+	MinValue interface{}
+	MaxValue interface{}
+	//Whether the page only has null values
+	NullPage bool
+	//Number of null values of the page, -1 if unknown
+	NullCount int64
+}
+
+// Max buffer size of the thrift structs read from the file
+const maxThriftBufferSize = 64 << 10
+
+// Read a thrift struct of length bytes at offset of the file
+func readThriftStruct(pFile source.ParquetFile, offset int64, length int32, obj thrift.TStruct) error {
+	if length <= 0 {
+		return fmt.Errorf("invalid thrift struct length %v", length)
+	}
+	if _, err := pFile.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	thriftReader := thrift.NewStreamTransportR(io.LimitReader(pFile, int64(length)))
+	//the length is from the footer, so the buffer isn't allocated with it
+	bufferSize := int(length)
+	if bufferSize > maxThriftBufferSize {
+		bufferSize = maxThriftBufferSize
+	}
+	bufferReader := thrift.NewTBufferedTransport(thriftReader, bufferSize)
+	protocol := thrift.NewTCompactProtocolFactory().GetProtocol(bufferReader)
+	return obj.Read(context.TODO(), protocol)
+}
+
+// Read an encrypted thrift struct of length bytes at offset of the file
+func readEncryptedThriftStruct(pFile source.ParquetFile, offset int64, length int32, obj thrift.TStruct, decryptor *encryption.ColumnDecryptor, moduleType int8) error {
+	if length <= 0 {
+		return fmt.Errorf("invalid thrift struct length %v", length)
+	}
+	if _, err := pFile.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	module, err := encryption.ReadModule(io.LimitReader(pFile, int64(length)))
+	if err != nil {
+		return err
+	}
+	buf, err := decryptor.DecryptModule(moduleType, 0, module)
+	if err != nil {
+		return err
+	}
+	td := thrift.NewTDeserializer()
+	td.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(td.Transport)
+	return td.Read(context.TODO(), obj, buf)
+}
+
+// ReadColumnIndex reads the ColumnIndex of a column chunk from pFile
+func ReadColumnIndex(pFile source.ParquetFile, chunk *parquet.ColumnChunk) (*parquet.ColumnIndex, error) {
+	return readColumnIndex(pFile, chunk, nil)
+}
+
+func readColumnIndex(pFile source.ParquetFile, chunk *parquet.ColumnChunk, decryptor *encryption.ColumnDecryptor) (*parquet.ColumnIndex, error) {
+	if chunk.ColumnIndexOffset == nil || chunk.ColumnIndexLength == nil {
+		return nil, fmt.Errorf("column chunk has no column index")
+	}
+	columnIndex := parquet.NewColumnIndex()
+	var err error
+	if decryptor != nil {
+		err = readEncryptedThriftStruct(pFile, chunk.GetColumnIndexOffset(), chunk.GetColumnIndexLength(), columnIndex, decryptor, encryption.ModuleColumnIndex)
+	} else {
+		err = readThriftStruct(pFile, chunk.GetColumnIndexOffset(), chunk.GetColumnIndexLength(), columnIndex)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return columnIndex, nil
+}
+
+// ReadOffsetIndex reads the OffsetIndex of a column chunk from pFile
+func ReadOffsetIndex(pFile source.ParquetFile, chunk *parquet.ColumnChunk) (*parquet.OffsetIndex, error) {
+	return readOffsetIndex(pFile, chunk, nil)
+}
+
+func readOffsetIndex(pFile source.ParquetFile, chunk *parquet.ColumnChunk, decryptor *encryption.ColumnDecryptor) (*parquet.OffsetIndex, error) {
+	if chunk.OffsetIndexOffset == nil || chunk.OffsetIndexLength == nil {
+		return nil, fmt.Errorf("column chunk has no offset index")
+	}
+	offsetIndex := parquet.NewOffsetIndex()
+	var err error
+	if decryptor != nil {
+		err = readEncryptedThriftStruct(pFile, chunk.GetOffsetIndexOffset(), chunk.GetOffsetIndexLength(), offsetIndex, decryptor, encryption.ModuleOffsetIndex)
+	} else {
+		err = readThriftStruct(pFile, chunk.GetOffsetIndexOffset(), chunk.GetOffsetIndexLength(), offsetIndex)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return offsetIndex, nil
+}
+
+// ReadPageIndex reads the page index of the column pathStr in a row group
+func (pr *ParquetReader) ReadPageIndex(rowGroupIndex int64, pathStr string) (*PageIndex, error) {
+	rowGroup, chunk, err := pr.columnChunk(rowGroupIndex, pathStr)
+	if err != nil {
+		return nil, err
+	}
+	return pr.readPageIndex(rowGroup, chunk)
+}
+
+func (pr *ParquetReader) readPageIndex(rowGroup *parquet.RowGroup, chunk *parquet.ColumnChunk) (*PageIndex, error) {
+	var err error
+	pFile := pr.PFile
+	if chunk.FilePath != nil {
+		if pFile, err = pFile.Open(chunk.GetFilePath()); err != nil {
+			return nil, err
+		}
+		defer pFile.Close()
+	}
+
+	decryptor, err := pr.columnDecryptor(rowGroup, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(PageIndex)
+	if res.OffsetIndex, err = readOffsetIndex(pFile, chunk, decryptor); err != nil {
+		return nil, err
+	}
+	if chunk.ColumnIndexOffset != nil {
+		if res.ColumnIndex, err = readColumnIndex(pFile, chunk, decryptor); err != nil {
+			return nil, err
+		}
+		if err = alignColumnIndex(res.ColumnIndex, res.OffsetIndex); err != nil {
+			return nil, err
+		}
+	}
+
+	path := append([]string{pr.SchemaHandler.GetRootInName()}, chunk.GetMetaData().GetPathInSchema()...)
+	se := pr.SchemaHandler.SchemaElements[pr.SchemaHandler.MapIndex[common.PathToStr(path)]]
+	locations := res.OffsetIndex.PageLocations
+	res.Pages = make([]PageInfo, len(locations))
+	for i, location := range locations {
+		page := &res.Pages[i]
+		page.Offset = location.Offset
+		page.CompressedPageSize = location.CompressedPageSize
+		page.FirstRowIndex = location.FirstRowIndex
+		page.NumRows = rowGroup.GetNumRows() - location.FirstRowIndex
+		if i+1 < len(locations) {
+			page.NumRows = locations[i+1].FirstRowIndex - location.FirstRowIndex
+		}
+		page.NullCount = -1
+
+		if columnIndex := res.ColumnIndex; columnIndex != nil {
+			page.NullPage = columnIndex.NullPages[i]
+			if !page.NullPage {
+				page.MinValue = decodeStatValue(columnIndex.MinValues[i], se)
+				page.MaxValue = decodeStatValue(columnIndex.MaxValues[i], se)
+			}
+			if columnIndex.NullCounts != nil {
+				page.NullCount = columnIndex.NullCounts[i]
+			}
+		}
+	}
+	return res, nil
+}
+
+// Older versions of the writer added an entry for the dictionary page to the
+// ColumnIndex. Drop it so that the entries line up with the OffsetIndex.
+func alignColumnIndex(columnIndex *parquet.ColumnIndex, offsetIndex *parquet.OffsetIndex) error {
+	numPages := len(offsetIndex.PageLocations)
+	if len(columnIndex.NullPages) == numPages+1 {
+		columnIndex.NullPages = columnIndex.NullPages[1:]
+		if len(columnIndex.MinValues) == numPages+1 {
+			columnIndex.MinValues = columnIndex.MinValues[1:]
+		}
+		if len(columnIndex.MaxValues) == numPages+1 {
+			columnIndex.MaxValues = columnIndex.MaxValues[1:]
+		}
+		if len(columnIndex.NullCounts) == numPages+1 {
+			columnIndex.NullCounts = columnIndex.NullCounts[1:]
+		}
+	}
+	if len(columnIndex.NullPages) != numPages || len(columnIndex.MinValues) != numPages || len(columnIndex.MaxValues) != numPages {
+		return fmt.Errorf("column index has %v pages but offset index has %v", len(columnIndex.NullPages), numPages)
+	}
+	if columnIndex.NullCounts != nil && len(columnIndex.NullCounts) != numPages {
+		return fmt.Errorf("column index has %v null counts but offset index has %v pages", len(columnIndex.NullCounts), numPages)
+	}
+	return nil
+}
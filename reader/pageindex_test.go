@@ -0,0 +1,87 @@
+package reader
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/common"
+)
+
+func TestReadPageIndex(t *testing.T) {
+	data := writeFilterFile(t, 2, 100)
+	pf, err := buffer.NewBufferFile(data)
+	assert.NoError(t, err)
+	pr, err := NewParquetReader(pf, new(filterRecord), 1)
+	assert.NoError(t, err)
+	defer pr.ReadStop()
+
+	pageIndex, err := pr.ReadPageIndex(1, common.ReformPathStr("parquet_go_root.id"))
+	assert.NoError(t, err)
+	assert.NotNil(t, pageIndex.ColumnIndex)
+	assert.True(t, len(pageIndex.Pages) > 1)
+
+	numRows := int64(0)
+	for _, page := range pageIndex.Pages {
+		assert.Equal(t, numRows, page.FirstRowIndex)
+		assert.Equal(t, 100+page.FirstRowIndex, page.MinValue)
+		assert.Equal(t, 100+page.FirstRowIndex+page.NumRows-1, page.MaxValue)
+		assert.Equal(t, int64(0), page.NullCount)
+		assert.False(t, page.NullPage)
+		numRows += page.NumRows
+	}
+	assert.Equal(t, int64(100), numRows)
+
+	pageIndex, err = pr.ReadPageIndex(0, common.ReformPathStr("parquet_go_root.tenant"))
+	assert.NoError(t, err)
+	assert.Equal(t, len(pageIndex.OffsetIndex.PageLocations), len(pageIndex.Pages))
+	assert.Equal(t, "tenant_0", pageIndex.Pages[0].MinValue)
+
+	_, err = pr.ReadPageIndex(2, common.ReformPathStr("parquet_go_root.id"))
+	assert.Error(t, err)
+	_, err = pr.ReadPageIndex(0, common.ReformPathStr("parquet_go_root.unknown"))
+	assert.Error(t, err)
+}
+
+func TestSkipRowsWithPageIndex(t *testing.T) {
+	data := writeFilterFile(t, 4, 100)
+
+	for _, skip := range []int64{1, 37, 99, 100, 101, 250, 395, 400, 500} {
+		for _, first := range []int64{0, 3} {
+			skip, first := skip, first
+			t.Run(fmt.Sprintf("%d-%d", first, skip), func(t *testing.T) {
+				pf, err := buffer.NewBufferFile(data)
+				assert.NoError(t, err)
+				pr, err := NewParquetReader(pf, new(filterRecord), 2)
+				assert.NoError(t, err)
+				defer pr.ReadStop()
+
+				// read a few rows first, so that some rows are buffered
+				rows := make([]filterRecord, first)
+				assert.NoError(t, pr.Read(&rows))
+				assert.NoError(t, pr.SkipRows(skip))
+				if first+skip > 100 && first+skip < 400 {
+					// pages of flat columns are skipped using the offset index
+					pathStr, err := pr.SchemaHandler.ConvertToInPathStr(common.ReformPathStr("parquet_go_root.id"))
+					assert.NoError(t, err)
+					cb := pr.ColumnBuffers[pathStr]
+					assert.NotNil(t, cb.OffsetIndex)
+				}
+
+				rows = make([]filterRecord, 10)
+				assert.NoError(t, pr.Read(&rows))
+				expected := make([]int64, 0)
+				for id := first + skip; id < first+skip+10 && id < 400; id++ {
+					expected = append(expected, id)
+				}
+				assert.Equal(t, expected, ids(rows))
+				for _, row := range rows {
+					assert.Equal(t, fmt.Sprintf("tenant_%d", row.Id%3), row.Tenant)
+					assert.Equal(t, []string{fmt.Sprintf("tag_%d", row.Id%5)}, row.Tags)
+					assert.Equal(t, row.Id%4 == 0, row.Score == nil)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package reader
+
+import (
+	"sync"
+
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/layout"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// prefetcher reads ahead the column chunks of the row groups of a file which is a
+// source.RangeReader. When a column starts reading a row group, the chunks of the
+// row group of all the columns being read are prefetched together, so that the
+// adjacent chunks are read at once. Only the pages in RowRanges and the dictionary
+// page are prefetched for the chunks whose pages are skipped with the OffsetIndex.
+// When the pages are read ahead, the next row group is prefetched in the background.
+type prefetcher struct {
+	pr     *ParquetReader
+	file   source.ParquetFile
+	maxGap int64
+
+	mutex sync.Mutex
+	//columns of the current read
+	paths []string
+	//prefetched ranges of each row group and column, which aren't released yet
+	rowGroups map[int64]map[string][]source.Range
+	//row groups being prefetched in the background, their channel is closed when it's done
+	pending map[int64]chan struct{}
+	//incremented by reset, so that the background prefetches started before are dropped
+	generation int
+}
+
+// Create a prefetcher if the file of pr is a source.RangeReader, nil otherwise
+func newPrefetcher(pr *ParquetReader, maxGap int64) (*prefetcher, error) {
+	if _, ok := pr.PFile.(source.RangeReader); !ok {
+		return nil, nil
+	}
+	file, err := pr.PFile.Open("")
+	if err != nil {
+		return nil, err
+	}
+	return &prefetcher{pr: pr, file: file, maxGap: maxGap,
+		rowGroups: make(map[int64]map[string][]source.Range), pending: make(map[int64]chan struct{})}, nil
+}
+
+func (p *prefetcher) rangeReader() source.RangeReader {
+	return p.file.(source.RangeReader)
+}
+
+// Set the columns which are read, their chunks are prefetched together
+func (p *prefetcher) setPaths(paths []string) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.paths = append(p.paths[:0], paths...)
+}
+
+// Prefetch the chunk of the column pathStr in a row group, with the chunks of the
+// other columns if the row group isn't prefetched yet. The ranges of the column in
+// the previous row groups are released. Errors are ignored: the pages which
+// aren't prefetched are read from the file.
+func (p *prefetcher) fetch(rowGroupIndex int64, pathStr string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for index := range p.rowGroups {
+		if index < rowGroupIndex {
+			p.releaseColumn(index, pathStr)
+		}
+	}
+	//wait for the prefetch of the row group in the background
+	for p.pending[rowGroupIndex] != nil {
+		done := p.pending[rowGroupIndex]
+		p.mutex.Unlock()
+		<-done
+		p.mutex.Lock()
+	}
+
+	columnRanges, ranges, _ := p.plan(rowGroupIndex, append(p.paths, pathStr))
+	err := p.rangeReader().Prefetch(ranges, p.maxGap)
+	p.record(rowGroupIndex, columnRanges, err)
+
+	if p.pr.readAheadPool == nil {
+		return
+	}
+	rowGroups := int64(len(p.pr.Footer.GetRowGroups()))
+	next := rowGroupIndex + 1
+	for next < rowGroups && p.pr.RowRanges != nil && len(p.pr.RowRanges[next]) == 0 {
+		next++
+	}
+	if _, ok := p.rowGroups[next]; !ok && next < rowGroups {
+		go p.prefetchNext(next, p.generation)
+	}
+}
+
+// Prefetch the next row group in the background, unless it's larger than the read-ahead memory
+func (p *prefetcher) prefetchNext(rowGroupIndex int64, generation int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.rowGroups[rowGroupIndex]; ok || p.pending[rowGroupIndex] != nil || p.generation != generation {
+		return
+	}
+	columnRanges, ranges, size := p.plan(rowGroupIndex, p.paths)
+	if maxMemory := p.pr.readAheadPool.maxMemory; len(ranges) == 0 || (maxMemory > 0 && size > maxMemory) {
+		return
+	}
+
+	done := make(chan struct{})
+	p.pending[rowGroupIndex] = done
+	p.mutex.Unlock()
+	err := p.rangeReader().Prefetch(ranges, p.maxGap)
+	p.mutex.Lock()
+	delete(p.pending, rowGroupIndex)
+	close(done)
+	if p.generation != generation {
+		//the prefetcher was reset while reading
+		if err == nil {
+			p.rangeReader().Release(ranges)
+		}
+		return
+	}
+	p.record(rowGroupIndex, columnRanges, err)
+}
+
+// Ranges of the chunks of the columns in paths of a row group which aren't prefetched
+// yet, with all the ranges and their size. The prefetcher must be locked.
+func (p *prefetcher) plan(rowGroupIndex int64, paths []string) (map[string][]source.Range, []source.Range, int64) {
+	columns := p.rowGroups[rowGroupIndex]
+	columnRanges := make(map[string][]source.Range)
+	var ranges []source.Range
+	var size int64
+	for _, path := range paths {
+		if _, ok := columns[path]; ok {
+			continue
+		}
+		if _, ok := columnRanges[path]; ok {
+			continue
+		}
+		columnRanges[path] = p.chunkRanges(rowGroupIndex, path)
+		for _, r := range columnRanges[path] {
+			ranges = append(ranges, r)
+			size += r.Length
+		}
+	}
+	return columnRanges, ranges, size
+}
+
+// Record the ranges prefetched with the error of the prefetch. The columns aren't
+// prefetched again in the row group, even if the prefetch failed. The prefetcher must be locked.
+func (p *prefetcher) record(rowGroupIndex int64, columnRanges map[string][]source.Range, err error) {
+	columns, ok := p.rowGroups[rowGroupIndex]
+	if !ok {
+		columns = make(map[string][]source.Range)
+		p.rowGroups[rowGroupIndex] = columns
+	}
+	for path, ranges := range columnRanges {
+		if err != nil {
+			ranges = nil
+		}
+		columns[path] = ranges
+	}
+}
+
+// Release the ranges of the column pathStr in a row group. The prefetcher must be locked.
+func (p *prefetcher) releaseColumn(rowGroupIndex int64, pathStr string) {
+	columns := p.rowGroups[rowGroupIndex]
+	ranges, ok := columns[pathStr]
+	if !ok {
+		return
+	}
+	p.rangeReader().Release(ranges)
+	//the column isn't prefetched again in the row group
+	columns[pathStr] = nil
+}
+
+// Release all the prefetched ranges, the row groups are prefetched again when they are read
+func (p *prefetcher) reset() {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for index, columns := range p.rowGroups {
+		for path := range columns {
+			p.releaseColumn(index, path)
+		}
+	}
+	p.rowGroups = make(map[int64]map[string][]source.Range)
+	p.generation++
+}
+
+// Ranges of the chunk of the column pathStr in a row group which are read, nil if the
+// chunk isn't in the file
+func (p *prefetcher) chunkRanges(rowGroupIndex int64, pathStr string) []source.Range {
+	pr := p.pr
+	rowGroup, chunk, err := pr.columnChunk(rowGroupIndex, pathStr)
+	if err != nil || chunk.FilePath != nil || chunk.MetaData == nil {
+		return nil
+	}
+	start := layout.ChunkOffset(chunk.MetaData)
+	end := start + chunk.MetaData.TotalCompressedSize
+	chunkRange := []source.Range{{Offset: start, Length: end - start}}
+
+	//the pages are skipped as in ColumnBufferType.NextRowGroup and skipPages
+	if pr.RowRanges == nil || chunk.OffsetIndexOffset == nil {
+		return chunkRange
+	}
+	if maxRL, _ := pr.SchemaHandler.MaxRepetitionLevel(common.StrToPath(pathStr)); maxRL > 0 {
+		return chunkRange
+	}
+	decryptor, err := pr.columnDecryptor(rowGroup, chunk)
+	if err != nil {
+		return chunkRange
+	}
+	offsetIndex, err := readOffsetIndex(p.file, chunk, decryptor)
+	if err != nil || len(offsetIndex.PageLocations) == 0 {
+		return chunkRange
+	}
+
+	locations := offsetIndex.PageLocations
+	var res []source.Range
+	if locations[0].Offset > start {
+		res = append(res, source.Range{Offset: start, Length: locations[0].Offset - start})
+	}
+	for i, location := range locations {
+		from, to := location.FirstRowIndex, rowGroup.NumRows
+		pageEnd := end
+		if i+1 < len(locations) {
+			to, pageEnd = locations[i+1].FirstRowIndex, locations[i+1].Offset
+		}
+		if overlapRowRanges(pr.RowRanges[rowGroupIndex], from, to) {
+			res = append(res, source.Range{Offset: location.Offset, Length: pageEnd - location.Offset})
+		}
+	}
+	return res
+}
@@ -0,0 +1,126 @@
+package reader
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/layout"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// io.ReaderAt counting its calls and the bytes read
+type countingReaderAt struct {
+	*bytes.Reader
+	mutex     sync.Mutex
+	calls     int
+	bytesRead int64
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.mutex.Lock()
+	r.calls++
+	r.bytesRead += int64(len(p))
+	r.mutex.Unlock()
+	return r.Reader.ReadAt(p, off)
+}
+
+func (r *countingReaderAt) reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls, r.bytesRead = 0, 0
+}
+
+func TestPrefetch(t *testing.T) {
+	data := writeFilterFile(t, 4, 100)
+	_, expected := readFiltered(t, data, nil)
+
+	r := &countingReaderAt{Reader: bytes.NewReader(data)}
+	pr, err := NewParquetReader(source.NewReaderAtFile(r, int64(len(data))), new(filterRecord), 2)
+	assert.NoError(t, err)
+	r.reset()
+	res := make([]filterRecord, 400)
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, expected, res)
+	//the adjacent chunks of each row group are read at once
+	assert.Equal(t, 4, r.calls)
+	pr.ReadStop()
+
+	//id and score aren't adjacent
+	type idScore struct {
+		Id    int64    `parquet:"name=id, type=INT64"`
+		Score *float64 `parquet:"name=score, type=DOUBLE"`
+	}
+	for _, gap := range []int64{0, -1} {
+		pr, err = NewParquetReader(source.NewReaderAtFile(r, int64(len(data))), new(idScore), 2, ParquetReaderOptions{MaxReadGap: gap})
+		assert.NoError(t, err)
+		r.reset()
+		rows := make([]idScore, 400)
+		assert.NoError(t, pr.Read(&rows))
+		for i, row := range rows {
+			assert.Equal(t, expected[i].Id, row.Id)
+			assert.Equal(t, expected[i].Score, row.Score)
+		}
+		if gap == 0 {
+			assert.Equal(t, 4, r.calls)
+		} else {
+			assert.Equal(t, 8, r.calls)
+		}
+		pr.ReadStop()
+	}
+
+	//only the pages of the last rows and the dictionary pages are read
+	pr, err = NewParquetReader(source.NewReaderAtFile(r, int64(len(data))), new(filterRecord), 2, ParquetReaderOptions{MaxReadGap: -1})
+	assert.NoError(t, err)
+	defer pr.ReadStop()
+	var chunksSize int64
+	for _, chunk := range pr.Footer.RowGroups[3].Columns {
+		chunksSize += chunk.MetaData.TotalCompressedSize
+	}
+	assert.NoError(t, pr.SetFilter(Gt(common.ReformPathStr("parquet_go_root.id"), 390)))
+	r.reset()
+	res = make([]filterRecord, 100)
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, expected[391:], res)
+	assert.Less(t, r.bytesRead, chunksSize)
+}
+
+func TestPrefetchZeroDictionaryPageOffset(t *testing.T) {
+	data := writeFilterFile(t, 4, 100)
+	_, expected := readFiltered(t, data, nil)
+
+	//the chunks without dictionary get a dictionary page offset of 0, as written by some writers
+	buf, _, offset, err := layout.ReadFooter(source.NewReaderAtFile(bytes.NewReader(data), int64(len(data))), 0)
+	assert.NoError(t, err)
+	footer, err := layout.DecodeFooter(buf)
+	assert.NoError(t, err)
+	for _, rowGroup := range footer.RowGroups {
+		for _, chunk := range rowGroup.Columns {
+			if chunk.MetaData.DictionaryPageOffset == nil {
+				chunk.MetaData.DictionaryPageOffset = new(int64)
+			}
+		}
+	}
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	buf, err = ts.Write(context.TODO(), footer)
+	assert.NoError(t, err)
+	data = append(append([]byte(nil), data[:offset]...), buf...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(buf)))
+	data = append(data, "PAR1"...)
+
+	r := &countingReaderAt{Reader: bytes.NewReader(data)}
+	pr, err := NewParquetReader(source.NewReaderAtFile(r, int64(len(data))), new(filterRecord), 2)
+	assert.NoError(t, err)
+	r.reset()
+	res := make([]filterRecord, 400)
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, expected, res)
+	assert.Equal(t, 4, r.calls)
+	pr.ReadStop()
+}
@@ -0,0 +1,206 @@
+package reader
+
+import (
+	"io"
+	"sync"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/xitongsys/parquet-go/layout"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/schema"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// readAheadPool bounds the pages read ahead by the columns of a reader: their
+// size and the number of pages decoded at the same time
+type readAheadPool struct {
+	//number of pages read ahead by each column
+	pages int
+	//max size of the pages read ahead, 0 if it isn't limited
+	maxMemory int64
+	//pages being decoded
+	workers chan struct{}
+
+	mutex  sync.Mutex
+	memory int64
+}
+
+func newReadAheadPool(pages int64, maxMemory int64, workers int64) *readAheadPool {
+	if pages <= 0 {
+		return nil
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	return &readAheadPool{pages: int(pages), maxMemory: maxMemory, workers: make(chan struct{}, workers)}
+}
+
+// Whether more pages can be read ahead
+func (p *readAheadPool) available() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.maxMemory <= 0 || p.memory < p.maxMemory
+}
+
+func (p *readAheadPool) add(size int64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.memory += size
+}
+
+// Page read ahead
+type aheadPage struct {
+	//offset of the page in the file
+	offset int64
+	//compressed and uncompressed size of the page, added to the memory of the pool
+	size int64
+	//closed when the page is decoded
+	done chan struct{}
+
+	page      *layout.Page
+	numValues int64
+	numRows   int64
+	err       error
+}
+
+// pageReadAhead reads the pages of a column chunk ahead in a goroutine and decodes
+// them with the workers of the pool. It owns the thrift reader of the column buffer
+// until it's stopped.
+type pageReadAhead struct {
+	pool          *readAheadPool
+	file          source.ParquetFile
+	thriftReader  *thrift.TBufferedTransport
+	schemaHandler *schema.SchemaHandler
+	metaData      *parquet.ColumnMetaData
+	limits        layout.PageLimits
+	//offset of the next page to read and end of the chunk
+	offset int64
+	end    int64
+
+	mutex sync.Mutex
+	//pages read ahead in order, which aren't returned by next yet
+	pages   []*aheadPage
+	reading bool
+	stopped bool
+	//done reading when reading is false
+	readDone *sync.Cond
+}
+
+func newPageReadAhead(pool *readAheadPool, file source.ParquetFile, thriftReader *thrift.TBufferedTransport, schemaHandler *schema.SchemaHandler, metaData *parquet.ColumnMetaData, limits layout.PageLimits, offset, end int64) *pageReadAhead {
+	r := &pageReadAhead{
+		pool:          pool,
+		file:          file,
+		thriftReader:  thriftReader,
+		schemaHandler: schemaHandler,
+		metaData:      metaData,
+		limits:        limits,
+		offset:        offset,
+		end:           end,
+	}
+	r.readDone = sync.NewCond(&r.mutex)
+	r.mutex.Lock()
+	r.start()
+	r.mutex.Unlock()
+	return r
+}
+
+// Start reading the next page in a goroutine if it can be read. The pages are read
+// one after the other, until the pool is full or the chunk is read. The next page
+// is always read if there is none ahead, as it's needed. The pageReadAhead must be locked.
+func (r *pageReadAhead) start() {
+	if r.reading || r.stopped || r.offset >= r.end {
+		return
+	}
+	if len(r.pages) >= r.pool.pages || (len(r.pages) > 0 && !r.pool.available()) {
+		return
+	}
+	page := &aheadPage{offset: r.offset, done: make(chan struct{})}
+	r.pages = append(r.pages, page)
+	r.reading = true
+	go r.read(page)
+}
+
+// Read a page and start decoding it
+func (r *pageReadAhead) read(page *aheadPage) {
+	compressedPage, err := r.limits.ReadCompressedPage(r.thriftReader)
+	if err == nil {
+		page.size = int64(len(compressedPage.Data)) + int64(compressedPage.Header.GetUncompressedPageSize())
+		r.pool.add(page.size)
+		go r.decode(page, compressedPage)
+	} else {
+		//the page is before the end of the chunk
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		page.err = err
+		close(page.done)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.offset = r.end
+	if err == nil {
+		if pos, err := r.file.Seek(0, io.SeekCurrent); err == nil {
+			r.offset = pos - int64(r.thriftReader.Reader.Buffered())
+		}
+	}
+	r.reading = false
+	r.readDone.Broadcast()
+	r.start()
+}
+
+// Decode a page with a worker of the pool
+func (r *pageReadAhead) decode(page *aheadPage, compressedPage *layout.CompressedPage) {
+	r.pool.workers <- struct{}{}
+	defer func() { <-r.pool.workers }()
+	page.page, page.numValues, page.numRows, page.err = r.limits.DecodePageVector(compressedPage, r.schemaHandler, r.metaData)
+	close(page.done)
+}
+
+// Next page of the chunk, with its number of values and rows. It returns io.EOF at the end of the chunk.
+func (r *pageReadAhead) next() (*layout.Page, int64, int64, error) {
+	r.mutex.Lock()
+	r.start()
+	if len(r.pages) == 0 {
+		r.mutex.Unlock()
+		return nil, 0, 0, io.EOF
+	}
+	page := r.pages[0]
+	r.pages = r.pages[1:]
+	r.start()
+	r.mutex.Unlock()
+
+	<-page.done
+	r.pool.add(-page.size)
+	return page.page, page.numValues, page.numRows, page.err
+}
+
+// Offset in the file of the page returned by the next call to next
+func (r *pageReadAhead) nextOffset() int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(r.pages) > 0 {
+		return r.pages[0].offset
+	}
+	return r.offset
+}
+
+// Stop reading ahead and discard the pages read ahead. It returns the offset of the
+// first discarded page, where the thrift reader must be moved to read the next page.
+func (r *pageReadAhead) stop() int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.stopped = true
+	for r.reading {
+		r.readDone.Wait()
+	}
+	offset := r.offset
+	if len(r.pages) > 0 {
+		offset = r.pages[0].offset
+	}
+	for _, page := range r.pages {
+		r.pool.add(-page.size)
+	}
+	r.pages = nil
+	return offset
+}
@@ -0,0 +1,67 @@
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+func TestReadAhead(t *testing.T) {
+	data := writeFilterFile(t, 4, 100)
+	_, expected := readFiltered(t, data, nil)
+
+	files := map[string]func() source.ParquetFile{
+		"buffer": func() source.ParquetFile {
+			pf, err := buffer.NewBufferFile(data)
+			assert.NoError(t, err)
+			return pf
+		},
+		"readerat": func() source.ParquetFile {
+			return source.NewReaderAtFile(bytes.NewReader(data), int64(len(data)))
+		},
+	}
+	for name, file := range files {
+		for _, memory := range []int64{0, 1} {
+			t.Run(fmt.Sprintf("%v-%v", name, memory), func(t *testing.T) {
+				opts := ParquetReaderOptions{ReadAheadPages: 4, ReadAheadMemory: memory}
+				pr, err := NewParquetReader(file(), new(filterRecord), 2, opts)
+				assert.NoError(t, err)
+				defer pr.ReadStop()
+
+				res := make([]filterRecord, 0)
+				for {
+					rows := make([]filterRecord, 30)
+					assert.NoError(t, pr.Read(&rows))
+					if len(rows) == 0 {
+						break
+					}
+					res = append(res, rows...)
+				}
+				assert.Equal(t, expected, res)
+				assert.Equal(t, int64(0), pr.readAheadPool.memory)
+
+				//the pages read ahead are dropped when rows are skipped
+				pr, err = NewParquetReader(file(), new(filterRecord), 2, opts)
+				assert.NoError(t, err)
+				defer pr.ReadStop()
+				rows := make([]filterRecord, 10)
+				assert.NoError(t, pr.Read(&rows))
+				assert.NoError(t, pr.SkipRows(150))
+				rows = make([]filterRecord, 100)
+				assert.NoError(t, pr.Read(&rows))
+				assert.Equal(t, expected[160:260], rows)
+
+				//pages are skipped by the filter
+				assert.NoError(t, pr.SetFilter(Gt(common.ReformPathStr("parquet_go_root.id"), 250)))
+				rows = make([]filterRecord, 200)
+				assert.NoError(t, pr.Read(&rows))
+				assert.Equal(t, expected[251:], rows)
+			})
+		}
+	}
+}
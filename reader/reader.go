@@ -2,14 +2,14 @@ package reader
 
 import (
 	"context"
-	"encoding/binary"
-	"io"
+	"fmt"
 	"reflect"
 	"strings"
 	"sync"
 
 	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/encryption"
 	"github.com/xitongsys/parquet-go/layout"
 	"github.com/xitongsys/parquet-go/marshal"
 	"github.com/xitongsys/parquet-go/parquet"
@@ -17,8 +17,68 @@ import (
 	"github.com/xitongsys/parquet-go/source"
 )
 
+// Default limits of the files read by a ParquetReader, see ParquetReaderOptions
+const (
+	DefaultMaxFooterSize   = layout.DefaultMaxFooterSize
+	DefaultMaxPageSize     = 1 << 30
+	DefaultMaxPageValues   = 1 << 26
+	DefaultMaxReadGap      = 1 << 20
+	DefaultReadAheadMemory = 256 << 20
+)
+
 type ParquetReaderOptions struct {
 	CaseInsensitive bool
+	//Decryption properties of files encrypted with the Parquet Modular Encryption
+	FileDecryptionProperties *encryption.FileDecryptionProperties
+
+	//Limits protecting the reader from malformed or malicious files, whose footer or page headers
+	//would make it allocate too much memory. 0 means the default limit and a negative value no limit.
+	//Max size of the footer in bytes
+	MaxFooterSize int64
+	//Max compressed and uncompressed size of a page in bytes
+	MaxPageSize int64
+	//Max number of values of a page, including the nulls
+	MaxPageValues int64
+
+	//Max gap in bytes between the column chunks or pages read together when the file is
+	//a source.RangeReader, such as source.ReaderAtFile. 0 means the default gap and a
+	//negative value that only adjacent ranges are read together.
+	MaxReadGap int64
+
+	//Number of pages of each column read ahead in the background, 0 disables the read-ahead.
+	//The pages are uncompressed and decoded by at most np goroutines, so that reading the
+	//file and decoding the pages overlap. When the file is a source.RangeReader, the column
+	//chunks of the next row group are also prefetched in the background.
+	ReadAheadPages int64
+	//Max size in bytes of the compressed and uncompressed pages read ahead, which are
+	//still read one at a time beyond it. 0 means the default size and a negative value no limit.
+	ReadAheadMemory int64
+
+	//The BYTE_ARRAY values of the UNCOMPRESSED pages with the PLAIN encoding of a source.MappedFile
+	//are views of the mapping instead of copies. They can't be used after the file is unmapped,
+	//so ReadStop doesn't unmap it: the mapping is released by Unmap or Close of the file.
+	MappedViews bool
+}
+
+// Limit of an option: 0 is the default limit and a negative value is no limit, which is 0 for layout.PageLimits
+func optionLimit(value int64, defaultValue int64) int64 {
+	if value == 0 {
+		return defaultValue
+	} else if value < 0 {
+		return 0
+	}
+	return value
+}
+
+func (opts ParquetReaderOptions) maxFooterSize() int64 {
+	return optionLimit(opts.MaxFooterSize, DefaultMaxFooterSize)
+}
+
+func (opts ParquetReaderOptions) pageLimits() layout.PageLimits {
+	return layout.PageLimits{
+		MaxPageSize:   optionLimit(opts.MaxPageSize, DefaultMaxPageSize),
+		MaxPageValues: optionLimit(opts.MaxPageValues, DefaultMaxPageValues),
+	}
 }
 
 type ParquetReader struct {
@@ -35,23 +95,57 @@ type ParquetReader struct {
 
 	//Determines whether case sensitivity is enabled
 	CaseInsensitive bool
+
+	UnmarshalFunc func(tableMap *map[string]*layout.Table, bgn int, end int, dstInterface interface{}, schemaHandler *schema.SchemaHandler, prefixPath string) error
+
+	//Filter set by SetFilter
+	Filter FilterExpr
+	//Rows which may match the filter of each row group
+	RowRanges map[int64][]RowRange
+
+	//position of the next row to read when a filter is set
+	filterRowGroup int64
+	filterRow      int64
+
+	decryptionProperties *encryption.FileDecryptionProperties
+	//nil if the file isn't encrypted
+	decryptor *encryption.FileDecryptor
+
+	//0 if the size isn't limited
+	maxFooterSize int64
+	pageLimits    layout.PageLimits
+	//nil if the file isn't a source.RangeReader
+	prefetcher *prefetcher
+	//nil if the pages aren't read ahead
+	readAheadPool *readAheadPool
+	//the values of a source.MappedFile may be views of the mapping
+	mappedViews bool
 }
 
 // Create a parquet reader: obj is a object with schema tags or a JSON schema string
 func NewParquetReader(pFile source.ParquetFile, obj interface{}, np int64, opts ...ParquetReaderOptions) (*ParquetReader, error) {
-	var caseInsensitive bool
+	var options ParquetReaderOptions
 	if len(opts) > 0 {
-		caseInsensitive = opts[0].CaseInsensitive
+		options = opts[0]
 	}
 
 	var err error
 	res := new(ParquetReader)
 	res.NP = np
 	res.PFile = pFile
-	res.CaseInsensitive = caseInsensitive
+	res.CaseInsensitive = options.CaseInsensitive
+	res.UnmarshalFunc = marshal.Unmarshal
+	res.decryptionProperties = options.FileDecryptionProperties
+	res.maxFooterSize = options.maxFooterSize()
+	res.pageLimits = options.pageLimits()
+	res.mappedViews = options.MappedViews
 	if err = res.ReadFooter(); err != nil {
 		return nil, err
 	}
+	res.readAheadPool = newReadAheadPool(options.ReadAheadPages, optionLimit(options.ReadAheadMemory, DefaultReadAheadMemory), np)
+	if res.prefetcher, err = newPrefetcher(res, optionLimit(options.MaxReadGap, DefaultMaxReadGap)); err != nil {
+		return nil, err
+	}
 	res.ColumnBuffers = make(map[string]*ColumnBufferType)
 
 	if obj != nil {
@@ -79,7 +173,7 @@ func NewParquetReader(pFile source.ParquetFile, obj interface{}, np int64, opts
 		schema := res.SchemaHandler.SchemaElements[i]
 		if schema.GetNumChildren() == 0 {
 			pathStr := res.SchemaHandler.IndexMap[int32(i)]
-			if res.ColumnBuffers[pathStr], err = NewColumnBuffer(pFile, res.Footer, res.SchemaHandler, pathStr); err != nil {
+			if res.ColumnBuffers[pathStr], err = res.newColumnBuffer(pathStr); err != nil {
 				return res, err
 			}
 		}
@@ -100,7 +194,7 @@ func (pr *ParquetReader) SetSchemaHandlerFromJSON(jsonSchema string) error {
 		schemaElement := pr.SchemaHandler.SchemaElements[i]
 		if schemaElement.GetNumChildren() == 0 {
 			pathStr := pr.SchemaHandler.IndexMap[int32(i)]
-			if pr.ColumnBuffers[pathStr], err = NewColumnBuffer(pr.PFile, pr.Footer, pr.SchemaHandler, pathStr); err != nil {
+			if pr.ColumnBuffers[pathStr], err = pr.newColumnBuffer(pathStr); err != nil {
 				return err
 			}
 		}
@@ -144,86 +238,266 @@ func (pr *ParquetReader) GetNumRows() int64 {
 	return pr.Footer.GetNumRows()
 }
 
-// Get the footer size
+// Get the footer size, it fails if the size is larger than the file or the limit of the reader
 func (pr *ParquetReader) GetFooterSize() (uint32, error) {
-	var err error
-	buf := make([]byte, 4)
-	if _, err = pr.PFile.Seek(-8, io.SeekEnd); err != nil {
-		return 0, err
-	}
-	if _, err = io.ReadFull(pr.PFile, buf); err != nil {
-		return 0, err
-	}
-	size := binary.LittleEndian.Uint32(buf)
+	size, _, err := layout.ReadFooterSize(pr.PFile, pr.maxFooterSize)
 	return size, err
 }
 
 // Read footer from parquet file
 func (pr *ParquetReader) ReadFooter() error {
-	size, err := pr.GetFooterSize()
+	buf, encrypted, _, err := layout.ReadFooter(pr.PFile, pr.maxFooterSize)
 	if err != nil {
 		return err
 	}
-	if _, err = pr.PFile.Seek(-(int64)(8+size), io.SeekEnd); err != nil {
+
+	pr.decryptor = nil
+	if encrypted {
+		if buf, err = pr.decryptFooter(buf); err != nil {
+			return err
+		}
+	}
+	if pr.Footer, err = layout.DecodeFooter(buf); err != nil {
 		return err
 	}
-	pr.Footer = parquet.NewFileMetaData()
-	pf := thrift.NewTCompactProtocolFactory()
-	thriftReader := thrift.NewStreamTransportR(pr.PFile)
-	bufferReader := thrift.NewTBufferedTransport(thriftReader, int(size))
-	protocol := pf.GetProtocol(bufferReader)
-	return pr.Footer.Read(context.TODO(), protocol)
+
+	if pr.decryptor == nil && pr.Footer.EncryptionAlgorithm != nil {
+		//plaintext footer of an encrypted file, followed by its signature
+		if len(buf) < encryption.SignatureLength {
+			return fmt.Errorf("invalid footer size %v", len(buf))
+		}
+		if pr.decryptor, err = encryption.NewFileDecryptor(pr.decryptionProperties, pr.Footer.EncryptionAlgorithm, pr.Footer.FooterSigningKeyMetadata); err != nil {
+			return err
+		}
+		if pr.decryptionProperties != nil {
+			footerSize := len(buf) - encryption.SignatureLength
+			if err = pr.decryptor.VerifyFooterSignature(buf[:footerSize], buf[footerSize:]); err != nil {
+				return err
+			}
+		}
+	}
+	if pr.decryptor == nil {
+		if pr.decryptionProperties != nil && !pr.decryptionProperties.PlaintextFilesAllowed {
+			return fmt.Errorf("file isn't encrypted and plaintext files aren't allowed")
+		}
+		return nil
+	}
+	return pr.decryptColumnMetaData()
+}
+
+// Decrypt an encrypted footer, which is a FileCryptoMetaData followed by the encrypted FileMetaData
+func (pr *ParquetReader) decryptFooter(buf []byte) ([]byte, error) {
+	transport := thrift.NewTMemoryBuffer()
+	if _, err := transport.Write(buf); err != nil {
+		return nil, err
+	}
+	cryptoMetaData := parquet.NewFileCryptoMetaData()
+	if err := cryptoMetaData.Read(context.TODO(), thrift.NewTCompactProtocolFactory().GetProtocol(transport)); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if pr.decryptor, err = encryption.NewFileDecryptor(pr.decryptionProperties, cryptoMetaData.EncryptionAlgorithm, cryptoMetaData.KeyMetadata); err != nil {
+		return nil, err
+	}
+	return pr.decryptor.DecryptFooter(buf[len(buf)-transport.Len():])
+}
+
+// Decrypt the ColumnMetaData of the encrypted column chunks. The column chunks
+// whose key isn't available only get the path in schema of the column.
+func (pr *ParquetReader) decryptColumnMetaData() error {
+	td := thrift.NewTDeserializer()
+	td.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(td.Transport)
+	for i, rowGroup := range pr.Footer.RowGroups {
+		for j, chunk := range rowGroup.Columns {
+			if chunk.CryptoMetadata == nil || chunk.EncryptedColumnMetadata == nil {
+				continue
+			}
+			decryptor, err := pr.decryptor.ColumnDecryptor(chunk.CryptoMetadata, rowGroupOrdinal(rowGroup, i), j)
+			if err != nil {
+				if chunk.MetaData == nil {
+					chunk.MetaData = parquet.NewColumnMetaData()
+					chunk.MetaData.PathInSchema = chunk.CryptoMetadata.GetENCRYPTION_WITH_COLUMN_KEY().GetPathInSchema()
+				}
+				continue
+			}
+
+			buf, err := decryptor.DecryptModule(encryption.ModuleColumnMetaData, 0, chunk.EncryptedColumnMetadata)
+			if err != nil {
+				return err
+			}
+			metaData := parquet.NewColumnMetaData()
+			if err = td.Read(context.TODO(), metaData, buf); err != nil {
+				return err
+			}
+			chunk.MetaData = metaData
+		}
+	}
+	return nil
+}
+
+// Ordinal of a row group used in the AADs of the encrypted modules
+func rowGroupOrdinal(rowGroup *parquet.RowGroup, index int) int {
+	if rowGroup.IsSetOrdinal() {
+		return int(rowGroup.GetOrdinal())
+	}
+	return index
+}
+
+// Get the decryptor of a column chunk, nil if the chunk isn't encrypted
+func (pr *ParquetReader) columnDecryptor(rowGroup *parquet.RowGroup, chunk *parquet.ColumnChunk) (*encryption.ColumnDecryptor, error) {
+	if pr.decryptor == nil || chunk.CryptoMetadata == nil {
+		return nil, nil
+	}
+	for i, rg := range pr.Footer.RowGroups {
+		if rg != rowGroup {
+			continue
+		}
+		for j, c := range rg.Columns {
+			if c == chunk {
+				return pr.decryptor.ColumnDecryptor(chunk.CryptoMetadata, rowGroupOrdinal(rg, i), j)
+			}
+		}
+	}
+	return nil, fmt.Errorf("column chunk not found in the footer")
+}
+
+// SetFilter sets the filter used by Read, ReadByNumber, ReadPartial and ReadPartialByNumber.
+// Row groups and pages which can't contain matching rows are skipped and only the
+// matching rows are unmarshalled, so the read functions return fewer rows than
+// requested only when there are no more matching rows. SkipRows skips rows of the
+// selected row groups before they are filtered. A nil filter reads all the rows again.
+//
+// SetFilter restarts reading from the first row of the file.
+func (pr *ParquetReader) SetFilter(expr FilterExpr) error {
+	var err error
+	if expr != nil {
+		if err = expr.bind(pr.SchemaHandler); err != nil {
+			return err
+		}
+	}
+
+	//the chunks are prefetched in the background with RowRanges
+	pr.prefetcher.reset()
+	pr.Filter, pr.RowRanges = expr, nil
+	pr.filterRowGroup, pr.filterRow = 0, 0
+	if expr != nil {
+		pr.RowRanges = make(map[int64][]RowRange)
+		for i, rowGroup := range pr.Footer.GetRowGroups() {
+			if ranges := expr.rowRanges(newFilterEnv(pr, rowGroup)); len(ranges) > 0 {
+				pr.RowRanges[int64(i)] = ranges
+			}
+		}
+	}
+
+	for pathStr, cb := range pr.ColumnBuffers {
+		if cb != nil {
+			cb.close()
+		}
+		if pr.ColumnBuffers[pathStr], err = pr.newColumnBuffer(pathStr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pr *ParquetReader) newColumnBuffer(pathStr string) (*ColumnBufferType, error) {
+	cb, err := newColumnBuffer(pr.PFile, pr.Footer, pr.SchemaHandler, pathStr, pr.RowRanges, pr.decryptor, pr.pageLimits)
+	if cb != nil {
+		cb.prefetcher = pr.prefetcher
+		cb.readAheadPool = pr.readAheadPool
+		cb.mappedViews = pr.mappedViews
+	}
+	return cb, err
 }
 
 // Skip rows of parquet file
 func (pr *ParquetReader) SkipRows(num int64) error {
+	return pr.SkipRowsContext(context.Background(), num)
+}
+
+// Skip rows of parquet file, it stops when ctx is done and returns the error of ctx.
+// The columns may then be at different rows, the reader shouldn't be used anymore.
+func (pr *ParquetReader) SkipRowsContext(ctx context.Context, num int64) error {
 	var err error
 	if num <= 0 {
 		return nil
 	}
-	doneChan := make(chan int, pr.NP)
-	taskChan := make(chan string, len(pr.SchemaHandler.ValueColumns))
-	stopChan := make(chan int)
 
 	for _, pathStr := range pr.SchemaHandler.ValueColumns {
 		if _, ok := pr.ColumnBuffers[pathStr]; !ok {
-			if pr.ColumnBuffers[pathStr], err = NewColumnBuffer(pr.PFile, pr.Footer, pr.SchemaHandler, pathStr); err != nil {
+			if pr.ColumnBuffers[pathStr], err = pr.newColumnBuffer(pathStr); err != nil {
 				return err
 			}
 		}
 	}
 
+	paths := make([]string, 0, len(pr.ColumnBuffers))
+	for key := range pr.ColumnBuffers {
+		paths = append(paths, key)
+	}
+	pr.prefetcher.setPaths(paths)
+	if err = pr.forEachColumn(ctx, paths, func(pathStr string) error {
+		_, err := pr.ColumnBuffers[pathStr].SkipRows(int64(num))
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if pr.Filter != nil {
+		for i := int64(0); i < num; i++ {
+			pr.nextFilterRow()
+		}
+	}
+	return err
+}
+
+// Run task for each path with NP goroutines. It stops running new tasks when ctx is done
+// or a task fails, waits for the running ones and returns the first error.
+func (pr *ParquetReader) forEachColumn(ctx context.Context, paths []string, task func(pathStr string) error) error {
+	taskChan := make(chan string)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var taskErr error
+	failed := make(chan struct{})
 	for i := int64(0); i < pr.NP; i++ {
+		wg.Add(1)
 		go func() {
-			for {
-				select {
-				case <-stopChan:
-					return
-				case pathStr := <-taskChan:
-					cb := pr.ColumnBuffers[pathStr]
-					cb.SkipRows(int64(num))
-					doneChan <- 0
+			defer wg.Done()
+			for pathStr := range taskChan {
+				if err := task(pathStr); err != nil {
+					once.Do(func() {
+						taskErr = err
+						close(failed)
+					})
 				}
 			}
 		}()
 	}
 
-	for key, _ := range pr.ColumnBuffers {
-		taskChan <- key
-	}
-
-	for i := 0; i < len(pr.ColumnBuffers); i++ {
-		<-doneChan
+	var err error
+dispatch:
+	for _, pathStr := range paths {
+		select {
+		case taskChan <- pathStr:
+		case <-failed:
+			break dispatch
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		}
 	}
-	for i := int64(0); i < pr.NP; i++ {
-		stopChan <- 0
+	close(taskChan)
+	wg.Wait()
+	if taskErr != nil {
+		return taskErr
 	}
 	return err
 }
 
 // Read rows of parquet file and unmarshal all to dst
 func (pr *ParquetReader) Read(dstInterface interface{}) error {
-	return pr.read(dstInterface, "")
+	return pr.read(context.Background(), dstInterface, "")
 }
 
 // Read maxReadNumber objects
@@ -259,7 +533,7 @@ func (pr *ParquetReader) ReadPartial(dstInterface interface{}, prefixPath string
 		return err
 	}
 
-	return pr.read(dstInterface, prefixPath)
+	return pr.read(context.Background(), dstInterface, prefixPath)
 }
 
 // Read maxReadNumber partial objects
@@ -288,57 +562,23 @@ func (pr *ParquetReader) ReadPartialByNumber(maxReadNumber int, prefixPath strin
 	return ret, nil
 }
 
-// Read rows of parquet file with a prefixPath
-func (pr *ParquetReader) read(dstInterface interface{}, prefixPath string) error {
+// Read rows of parquet file with a prefixPath, it stops when ctx is done and returns the error of ctx
+func (pr *ParquetReader) read(ctx context.Context, dstInterface interface{}, prefixPath string) error {
 	var err error
-	tmap := make(map[string]*layout.Table)
-	locker := new(sync.Mutex)
 	ot := reflect.TypeOf(dstInterface).Elem().Elem()
 	num := reflect.ValueOf(dstInterface).Elem().Len()
 	if num <= 0 {
 		return nil
 	}
 
-	doneChan := make(chan int, pr.NP)
-	taskChan := make(chan string, len(pr.ColumnBuffers))
-	stopChan := make(chan int)
-
-	for i := int64(0); i < pr.NP; i++ {
-		go func() {
-			for {
-				select {
-				case <-stopChan:
-					return
-				case pathStr := <-taskChan:
-					cb := pr.ColumnBuffers[pathStr]
-					table, _ := cb.ReadRows(int64(num))
-					locker.Lock()
-					if _, ok := tmap[pathStr]; ok {
-						tmap[pathStr].Merge(table)
-					} else {
-						tmap[pathStr] = layout.NewTableFromTable(table)
-						tmap[pathStr].Merge(table)
-					}
-					locker.Unlock()
-					doneChan <- 0
-				}
-			}
-		}()
-	}
-
-	readNum := 0
-	for key, _ := range pr.ColumnBuffers {
-		if strings.HasPrefix(key, prefixPath) {
-			taskChan <- key
-			readNum++
-		}
-	}
-	for i := 0; i < readNum; i++ {
-		<-doneChan
+	var tmap map[string]*layout.Table
+	if pr.Filter != nil {
+		tmap, num, err = pr.readFilteredTables(ctx, num, prefixPath)
+	} else {
+		tmap, _, err = pr.readTables(ctx, num, pr.readPaths(prefixPath))
 	}
-
-	for i := int64(0); i < pr.NP; i++ {
-		stopChan <- 0
+	if err != nil {
+		return err
 	}
 
 	dstList := make([]interface{}, pr.NP)
@@ -361,7 +601,7 @@ func (pr *ParquetReader) read(dstInterface interface{}, prefixPath string) error
 			}()
 
 			dstList[index] = reflect.New(reflect.SliceOf(ot)).Interface()
-			if err2 := marshal.Unmarshal(&tmap, b, e, dstList[index], pr.SchemaHandler, prefixPath); err2 != nil {
+			if err2 := pr.UnmarshalFunc(&tmap, b, e, dstList[index], pr.SchemaHandler, prefixPath); err2 != nil {
 				err = err2
 			}
 		}(int(bgn), int(end), int(c))
@@ -378,11 +618,210 @@ func (pr *ParquetReader) read(dstInterface interface{}, prefixPath string) error
 	return err
 }
 
-// Stop Read
+// Get the paths of the columns needed to read the objects with prefixPath
+func (pr *ParquetReader) readPaths(prefixPath string) []string {
+	paths := make([]string, 0)
+	for key := range pr.ColumnBuffers {
+		if strings.HasPrefix(key, prefixPath) {
+			paths = append(paths, key)
+		}
+	}
+	if pr.Filter != nil {
+		for _, key := range pr.Filter.columns(nil) {
+			if !strings.HasPrefix(key, prefixPath) {
+				paths = append(paths, key)
+			}
+		}
+	}
+	return paths
+}
+
+// Read num rows of the columns in paths. It returns the tables and the number of rows read.
+func (pr *ParquetReader) readTables(ctx context.Context, num int, paths []string) (map[string]*layout.Table, int64, error) {
+	tmap := make(map[string]*layout.Table)
+	numRows := int64(0)
+	locker := new(sync.Mutex)
+
+	readPaths := make([]string, 0, len(paths))
+	for _, key := range paths {
+		if _, ok := pr.ColumnBuffers[key]; ok {
+			readPaths = append(readPaths, key)
+		}
+	}
+
+	pr.prefetcher.setPaths(readPaths)
+	err := pr.forEachColumn(ctx, readPaths, func(pathStr string) error {
+		cb := pr.ColumnBuffers[pathStr]
+		table, _, err := cb.readRows(int64(num))
+		if err != nil {
+			return err
+		}
+		n := int64(0)
+		for _, rl := range table.RepetitionLevels {
+			if rl == 0 {
+				n++
+			}
+		}
+		locker.Lock()
+		defer locker.Unlock()
+		if _, ok := tmap[pathStr]; ok {
+			tmap[pathStr].Merge(table)
+		} else {
+			tmap[pathStr] = layout.NewTableFromTable(table)
+			tmap[pathStr].Merge(table)
+		}
+		if n > numRows {
+			numRows = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return tmap, numRows, nil
+}
+
+// Read rows until num rows matching the filter are found or there are no more rows.
+// It returns the tables of the matching rows and their number.
+func (pr *ParquetReader) readFilteredTables(ctx context.Context, num int, prefixPath string) (map[string]*layout.Table, int, error) {
+	paths := pr.readPaths(prefixPath)
+	res := make(map[string]*layout.Table)
+	cnt := 0
+	for cnt < num {
+		tmap, numRows, err := pr.readTables(ctx, num-cnt, paths)
+		if err != nil {
+			return nil, 0, err
+		}
+		if numRows <= 0 {
+			break
+		}
+
+		for _, table := range tmap {
+			table.ToValues()
+		}
+		selected, numSelected := pr.filterRows(tmap, numRows)
+		for pathStr, table := range tmap {
+			table = selectRows(table, selected)
+			if _, ok := res[pathStr]; ok {
+				res[pathStr].Merge(table)
+			} else {
+				res[pathStr] = layout.NewTableFromTable(table)
+				res[pathStr].Merge(table)
+			}
+		}
+		cnt += numSelected
+	}
+	return res, cnt, nil
+}
+
+// Evaluate the filter on numRows rows of tmap
+func (pr *ParquetReader) filterRows(tmap map[string]*layout.Table, numRows int64) ([]bool, int) {
+	rowStarts := make(map[string][]int)
+	for _, pathStr := range pr.Filter.columns(nil) {
+		if _, ok := rowStarts[pathStr]; ok {
+			continue
+		}
+		starts := make([]int, 0, numRows+1)
+		if table, ok := tmap[pathStr]; ok {
+			for i, rl := range table.RepetitionLevels {
+				if rl == 0 {
+					starts = append(starts, i)
+				}
+			}
+			starts = append(starts, len(table.Values))
+		}
+		rowStarts[pathStr] = starts
+	}
+
+	var r int
+	row := func(pathStr string) []interface{} {
+		starts := rowStarts[pathStr]
+		if r+1 >= len(starts) {
+			return []interface{}{nil}
+		}
+		return tmap[pathStr].Values[starts[r]:starts[r+1]]
+	}
+
+	selected := make([]bool, numRows)
+	numSelected := 0
+	for r = 0; r < int(numRows); r++ {
+		if pr.nextFilterRow() && pr.Filter.match(row) {
+			selected[r] = true
+			numSelected++
+		}
+	}
+	return selected, numSelected
+}
+
+// Move to the next row of the row groups selected by the filter.
+// It returns whether the row is in RowRanges.
+func (pr *ParquetReader) nextFilterRow() bool {
+	rowGroups := pr.Footer.GetRowGroups()
+	for pr.filterRowGroup < int64(len(rowGroups)) &&
+		(pr.filterRow >= rowGroups[pr.filterRowGroup].GetNumRows() || len(pr.RowRanges[pr.filterRowGroup]) == 0) {
+		pr.filterRowGroup++
+		pr.filterRow = 0
+	}
+	if pr.filterRowGroup >= int64(len(rowGroups)) {
+		return false
+	}
+
+	res := overlapRowRanges(pr.RowRanges[pr.filterRowGroup], pr.filterRow, pr.filterRow+1)
+	pr.filterRow++
+	return res
+}
+
+// Get the rows of table which are selected
+func selectRows(table *layout.Table, selected []bool) *layout.Table {
+	res := layout.NewTableFromTable(table)
+	res.RepetitionType = table.RepetitionType
+	res.MaxDefinitionLevel = table.MaxDefinitionLevel
+	res.MaxRepetitionLevel = table.MaxRepetitionLevel
+	row := -1
+	for i := 0; i < len(table.Values); i++ {
+		if table.RepetitionLevels[i] == 0 {
+			row++
+		}
+		if row < len(selected) && selected[row] {
+			res.Values = append(res.Values, table.Values[i])
+			res.RepetitionLevels = append(res.RepetitionLevels, table.RepetitionLevels[i])
+			res.DefinitionLevels = append(res.DefinitionLevels, table.DefinitionLevels[i])
+		}
+	}
+	return res
+}
+
+// Get the column chunk of the column pathStr in a row group
+func (pr *ParquetReader) columnChunk(rowGroupIndex int64, pathStr string) (*parquet.RowGroup, *parquet.ColumnChunk, error) {
+	pathStr, err := pr.SchemaHandler.ConvertToInPathStr(pathStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	rowGroups := pr.Footer.GetRowGroups()
+	if rowGroupIndex < 0 || rowGroupIndex >= int64(len(rowGroups)) {
+		return nil, nil, fmt.Errorf("row group index %v out of range %v", rowGroupIndex, len(rowGroups))
+	}
+
+	rowGroup := rowGroups[rowGroupIndex]
+	for _, chunk := range rowGroup.GetColumns() {
+		path := append([]string{pr.SchemaHandler.GetRootInName()}, chunk.GetMetaData().GetPathInSchema()...)
+		if common.PathToStr(path) == pathStr {
+			return rowGroup, chunk, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("column %v not found in row group %v", pathStr, rowGroupIndex)
+}
+
+// Stop Read. The mapping of a source.MappedFile is released, unless its values are
+// views of the mapping with ParquetReaderOptions.MappedViews.
 func (pr *ParquetReader) ReadStop() {
+	pr.prefetcher.reset()
 	for _, cb := range pr.ColumnBuffers {
 		if cb != nil {
-			cb.PFile.Close()
+			cb.close()
 		}
 	}
+	if file, ok := pr.PFile.(source.MappedFile); ok && !pr.mappedViews {
+		file.Unmap()
+	}
 }
@@ -0,0 +1,136 @@
+package reader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// RowIterator iterates over the rows of a ParquetReader, see ParquetReader.Rows
+type RowIterator struct {
+	//Number of rows read at a time, default 1000
+	BatchSize int
+
+	pr  *ParquetReader
+	ctx context.Context
+
+	//pointer of the slice of the rows of the current batch
+	batch reflect.Value
+	index int
+	last  bool
+
+	err    error
+	closed bool
+}
+
+// Rows returns an iterator over the remaining rows of the file, which are read in batches.
+// The iteration stops when ctx is done. The file handles of the column buffers are released
+// as ReadStop does when the iteration ends or the iterator is closed, so the reader can't be
+// used anymore after that.
+//
+//	rows := pr.Rows(ctx)
+//	defer rows.Close()
+//	for rows.Next() {
+//		var stu Student
+//		if err := rows.Scan(&stu); err != nil {
+//			return err
+//		}
+//	}
+//	return rows.Err()
+func (pr *ParquetReader) Rows(ctx context.Context) *RowIterator {
+	return &RowIterator{
+		BatchSize: 1000,
+		pr:        pr,
+		ctx:       ctx,
+	}
+}
+
+// Next prepares the next row for Scan. It returns false when there are no more rows,
+// the context is done or an error happens, Err tells them apart.
+func (it *RowIterator) Next() bool {
+	if it.closed {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.close(err)
+		return false
+	}
+
+	it.index++
+	if it.batch.IsValid() && it.index < it.batch.Elem().Len() {
+		return true
+	}
+	//a batch is short only at the end of the rows
+	if it.last {
+		it.close(nil)
+		return false
+	}
+	if err := it.readBatch(); err != nil {
+		it.close(err)
+		return false
+	}
+	if it.batch.Elem().Len() == 0 {
+		it.close(nil)
+		return false
+	}
+	return true
+}
+
+func (it *RowIterator) readBatch() error {
+	if it.BatchSize <= 0 {
+		return fmt.Errorf("invalid batch size %v", it.BatchSize)
+	}
+	if !it.batch.IsValid() || it.batch.Elem().Cap() != it.BatchSize {
+		var err error
+		if it.pr.ObjType == nil {
+			if it.pr.ObjType, err = it.pr.SchemaHandler.GetType(it.pr.SchemaHandler.GetRootInName()); err != nil {
+				return err
+			}
+		}
+		it.batch = reflect.New(reflect.SliceOf(it.pr.ObjType))
+		it.batch.Elem().Set(reflect.MakeSlice(it.batch.Elem().Type(), it.BatchSize, it.BatchSize))
+	}
+	//the rows of the previous batch are overwritten, Scan copies them
+	it.batch.Elem().SetLen(it.BatchSize)
+	if err := it.pr.read(it.ctx, it.batch.Interface(), ""); err != nil {
+		return err
+	}
+	it.index = 0
+	it.last = it.batch.Elem().Len() < it.BatchSize
+	return nil
+}
+
+// Scan copies the current row to dst, which is a pointer of the type of the rows
+func (it *RowIterator) Scan(dst interface{}) error {
+	if it.closed || !it.batch.IsValid() || it.index >= it.batch.Elem().Len() {
+		return errors.New("Scan called without a successful Next")
+	}
+	row := it.batch.Elem().Index(it.index)
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() || !row.Type().AssignableTo(dstValue.Elem().Type()) {
+		return fmt.Errorf("can't scan a row of type %v to %T", row.Type(), dst)
+	}
+	dstValue.Elem().Set(row)
+	return nil
+}
+
+// Err returns the error which stopped the iteration, it's nil at the end of the rows
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and releases the file handles of the column buffers
+func (it *RowIterator) Close() error {
+	it.close(nil)
+	return nil
+}
+
+func (it *RowIterator) close(err error) {
+	if it.closed {
+		return
+	}
+	it.closed, it.err = true, err
+	it.batch = reflect.Value{}
+	it.pr.ReadStop()
+}
@@ -0,0 +1,110 @@
+package reader
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// countingFile counts the opened and closed handles of a file
+type countingFile struct {
+	source.ParquetFile
+	opened, closed *int32
+}
+
+func (f countingFile) Open(name string) (source.ParquetFile, error) {
+	pf, err := f.ParquetFile.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddInt32(f.opened, 1)
+	return countingFile{pf, f.opened, f.closed}, nil
+}
+
+func (f countingFile) Close() error {
+	atomic.AddInt32(f.closed, 1)
+	return f.ParquetFile.Close()
+}
+
+func newRowsReader(t *testing.T, records []genericRecord) (*ParquetReader, *int32, *int32) {
+	var buf bytes.Buffer
+	pw, err := writer.NewGenericWriterFromWriter[genericRecord](&buf, 2)
+	assert.NoError(t, err)
+	pw.PageSize = 512
+	_, err = pw.Write(records)
+	assert.NoError(t, err)
+	assert.NoError(t, pw.WriteStop())
+
+	pf, err := buffer.NewBufferFile(buf.Bytes())
+	assert.NoError(t, err)
+	opened, closed := new(int32), new(int32)
+	pr, err := NewParquetReader(countingFile{pf, opened, closed}, new(genericRecord), 2)
+	assert.NoError(t, err)
+	return pr, opened, closed
+}
+
+func TestRows(t *testing.T) {
+	records := make([]genericRecord, 2500)
+	for i := range records {
+		records[i] = genericRecord{ID: int64(i)}
+	}
+
+	pr, opened, closed := newRowsReader(t, records)
+	assert.NoError(t, pr.SkipRows(100))
+	rows := pr.Rows(context.Background())
+	rows.BatchSize = 300
+	res := []genericRecord{}
+	for rows.Next() {
+		var record genericRecord
+		assert.NoError(t, rows.Scan(&record))
+		res = append(res, record)
+	}
+	assert.NoError(t, rows.Err())
+	assert.Equal(t, records[100:], res)
+	assert.Equal(t, *opened, *closed)
+	assert.Error(t, rows.Scan(new(genericRecord)))
+	assert.NoError(t, rows.Close())
+
+	// the rows are of the type of the reader
+	pr, _, _ = newRowsReader(t, records)
+	rows = pr.Rows(context.Background())
+	assert.True(t, rows.Next())
+	assert.Error(t, rows.Scan(new(int64)))
+	assert.NoError(t, rows.Close())
+	assert.False(t, rows.Next())
+}
+
+func TestRowsCancel(t *testing.T) {
+	records := make([]genericRecord, 2500)
+	for i := range records {
+		records[i] = genericRecord{ID: int64(i)}
+	}
+
+	pr, opened, closed := newRowsReader(t, records)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rows := pr.Rows(ctx)
+	rows.BatchSize = 100
+	n := 0
+	for rows.Next() {
+		if n++; n == 150 {
+			cancel()
+		}
+	}
+	assert.Equal(t, 150, n)
+	assert.Equal(t, context.Canceled, rows.Err())
+	assert.Equal(t, *opened, *closed)
+
+	// the workers stop before reading the columns
+	pr, _, _ = newRowsReader(t, records)
+	assert.Equal(t, context.Canceled, pr.SkipRowsContext(ctx, 10))
+	rows = pr.Rows(ctx)
+	assert.False(t, rows.Next())
+	assert.Equal(t, context.Canceled, rows.Err())
+}
@@ -0,0 +1,101 @@
+// Command brotli writes brotli.parquet with pages compressed by the reference brotli
+// library (libbrotlienc) instead of the Go port used by the writer.
+//
+//	go run ./reader/testdata/brotli
+package main
+
+/*
+#cgo LDFLAGS: -lbrotlienc -lbrotlidec
+#include <brotli/encode.h>
+#include <brotli/decode.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"unsafe"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/compress"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type Record struct {
+	Id       int64     `parquet:"name=id, type=INT64"`
+	Name     string    `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Category *string   `parquet:"name=category, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Scores   []float64 `parquet:"name=scores, type=DOUBLE, repetitiontype=REPEATED"`
+}
+
+func compressBrotli(buf []byte) []byte {
+	size := C.BrotliEncoderMaxCompressedSize(C.size_t(len(buf)))
+	res := make([]byte, size+1)
+	in := unsafe.Pointer(nil)
+	if len(buf) > 0 {
+		in = unsafe.Pointer(&buf[0])
+	}
+	if C.BrotliEncoderCompress(C.BROTLI_DEFAULT_QUALITY, C.BROTLI_DEFAULT_WINDOW, C.BROTLI_MODE_GENERIC,
+		C.size_t(len(buf)), (*C.uint8_t)(in), &size, (*C.uint8_t)(unsafe.Pointer(&res[0]))) != C.BROTLI_TRUE {
+		log.Fatal("BrotliEncoderCompress failed")
+	}
+	return res[:size]
+}
+
+func uncompressBrotli(buf []byte) ([]byte, error) {
+	res := make([]byte, 64<<20)
+	size := C.size_t(len(res))
+	if len(buf) == 0 || C.BrotliDecoderDecompress(C.size_t(len(buf)), (*C.uint8_t)(unsafe.Pointer(&buf[0])),
+		&size, (*C.uint8_t)(unsafe.Pointer(&res[0]))) != C.BROTLI_DECODER_RESULT_SUCCESS {
+		return nil, errors.New("BrotliDecoderDecompress failed")
+	}
+	return res[:size], nil
+}
+
+func main() {
+	compress.RegisterCodec(parquet.CompressionCodec_BROTLI, &compress.Compressor{
+		Compress:   compressBrotli,
+		Uncompress: uncompressBrotli,
+	})
+	name := "reader/testdata/brotli.parquet"
+	if len(os.Args) > 1 {
+		name = os.Args[1]
+	}
+	fw, err := local.NewLocalFileWriter(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pw, err := writer.NewParquetWriter(fw, new(Record), 1)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pw.CompressionType = parquet.CompressionCodec_BROTLI
+	pw.PageSize = 4 << 10
+	for i := 0; i < 2000; i++ {
+		record := Record{Id: int64(i), Name: fmt.Sprintf("name_%d", i)}
+		if i%3 != 0 {
+			category := fmt.Sprintf("category_%d", i%7)
+			record.Category = &category
+		}
+		for j := 0; j < i%4; j++ {
+			record.Scores = append(record.Scores, float64(i)+float64(j)/4)
+		}
+		if err = pw.Write(record); err != nil {
+			log.Fatal(err)
+		}
+		if i == 1200 {
+			if err = pw.Flush(true); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	if err = pw.WriteStop(); err != nil {
+		log.Fatal(err)
+	}
+	if err = fw.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,339 @@
+// Package httpfile reads parquet files from HTTP servers with Range requests
+package httpfile
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xitongsys/parquet-go/source"
+)
+
+const (
+	DefaultBlockSize   = 64 << 10
+	DefaultCacheBlocks = 64
+	DefaultRetries     = 3
+	DefaultRetryDelay  = 100 * time.Millisecond
+	DefaultTimeout     = 30 * time.Second
+)
+
+// Options of a Reader, the zero values are the defaults
+type Options struct {
+	//Client sending the requests, default is http.DefaultClient
+	Client *http.Client
+	//Header added to the requests, such as Authorization
+	Header http.Header
+	//Size of the blocks of the cache
+	BlockSize int64
+	//Max number of blocks of the LRU cache, negative for no cache.
+	//The reads larger than the cache aren't cached.
+	CacheBlocks int
+	//Number of retries of a failed request, negative for no retry.
+	//The network errors and the 429 and 5xx statuses are retried.
+	Retries int
+	//Delay before the first retry, it's doubled at each retry
+	RetryDelay time.Duration
+	//Timeout of a request, negative for no timeout
+	Timeout time.Duration
+}
+
+// Reader is an io.ReaderAt of the file at a URL, which is safe for concurrent use.
+// It reads blocks of the file with Range requests and keeps the last used ones in an LRU cache.
+type Reader struct {
+	url     string
+	options Options
+	size    int64
+
+	mutex  sync.Mutex
+	blocks map[int64]*list.Element
+	lru    *list.List
+}
+
+// Block of the cache
+type block struct {
+	index int64
+	buf   []byte
+}
+
+// Error of a request which isn't retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// NewReader gets the size of the file at url with a HEAD request
+func NewReader(url string, options Options) (*Reader, error) {
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+	if options.BlockSize <= 0 {
+		options.BlockSize = DefaultBlockSize
+	}
+	if options.CacheBlocks == 0 {
+		options.CacheBlocks = DefaultCacheBlocks
+	}
+	if options.Retries == 0 {
+		options.Retries = DefaultRetries
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = DefaultRetryDelay
+	}
+	if options.Timeout == 0 {
+		options.Timeout = DefaultTimeout
+	}
+	r := &Reader{
+		url:     url,
+		options: options,
+		blocks:  make(map[int64]*list.Element),
+		lru:     list.New(),
+	}
+	var err error
+	if r.size, err = r.fetchSize(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewHttpFile opens the file at url, it's a source.ReaderAtFile over a Reader
+func NewHttpFile(url string, options Options) (*source.ReaderAtFile, error) {
+	r, err := NewReader(url, options)
+	if err != nil {
+		return nil, err
+	}
+	return source.NewReaderAtFile(r, r.Size()), nil
+}
+
+// URL of the file
+func (r *Reader) URL() string {
+	return r.url
+}
+
+// Size of the file
+func (r *Reader) Size() int64 {
+	return r.size
+}
+
+func (r *Reader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("ReadAt: negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	end := off + int64(len(p))
+	if end > r.size {
+		end = r.size
+	}
+	first, last := off/r.options.BlockSize, (end-1)/r.options.BlockSize
+	if r.options.CacheBlocks < 0 || last-first+1 > int64(r.options.CacheBlocks) {
+		if err := r.fetch(p[:end-off], off); err != nil {
+			return 0, err
+		}
+	} else if err := r.readBlocks(p[:end-off], off, first, last); err != nil {
+		return 0, err
+	}
+	if end-off < int64(len(p)) {
+		return int(end - off), io.EOF
+	}
+	return len(p), nil
+}
+
+// Read p at off from the blocks first to last, the missing blocks are fetched with a request per run of blocks
+func (r *Reader) readBlocks(p []byte, off int64, first, last int64) error {
+	bufs := make([][]byte, last-first+1)
+	r.mutex.Lock()
+	for i := range bufs {
+		if elem, ok := r.blocks[first+int64(i)]; ok {
+			r.lru.MoveToFront(elem)
+			bufs[i] = elem.Value.(*block).buf
+		}
+	}
+	r.mutex.Unlock()
+
+	for i := 0; i < len(bufs); {
+		if bufs[i] != nil {
+			i++
+			continue
+		}
+		j := i
+		for j < len(bufs) && bufs[j] == nil {
+			j++
+		}
+		start := (first + int64(i)) * r.options.BlockSize
+		end := (first + int64(j)) * r.options.BlockSize
+		if end > r.size {
+			end = r.size
+		}
+		buf := make([]byte, end-start)
+		if err := r.fetch(buf, start); err != nil {
+			return err
+		}
+		for k := i; k < j; k++ {
+			blockStart := int64(k-i) * r.options.BlockSize
+			blockEnd := blockStart + r.options.BlockSize
+			if blockEnd > int64(len(buf)) {
+				blockEnd = int64(len(buf))
+			}
+			bufs[k] = buf[blockStart:blockEnd:blockEnd]
+			r.add(first+int64(k), bufs[k])
+		}
+		i = j
+	}
+
+	for i, buf := range bufs {
+		blockStart := (first + int64(i)) * r.options.BlockSize
+		from := int64(0)
+		if off > blockStart {
+			from = off - blockStart
+		}
+		to := int64(0)
+		if blockStart > off {
+			to = blockStart - off
+		}
+		copy(p[to:], buf[from:])
+	}
+	return nil
+}
+
+// Add a block to the cache, the least recently used block is evicted if the cache is full
+func (r *Reader) add(index int64, buf []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if elem, ok := r.blocks[index]; ok {
+		r.lru.MoveToFront(elem)
+		return
+	}
+	r.blocks[index] = r.lru.PushFront(&block{index: index, buf: buf})
+	for r.lru.Len() > r.options.CacheBlocks {
+		elem := r.lru.Back()
+		r.lru.Remove(elem)
+		delete(r.blocks, elem.Value.(*block).index)
+	}
+}
+
+// Get the size of the file with a HEAD request.
+// If the server doesn't answer HEAD requests, it's got from the Content-Range of a GET of the first byte.
+func (r *Reader) fetchSize() (int64, error) {
+	var size int64
+	err := r.retry(func(ctx context.Context) error {
+		res, err := r.do(ctx, http.MethodHead, "")
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode == http.StatusOK && res.ContentLength >= 0 {
+			size = res.ContentLength
+			return nil
+		}
+		if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusMethodNotAllowed {
+			return statusError(res)
+		}
+
+		res, err = r.do(ctx, http.MethodGet, "bytes=0-0")
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		switch res.StatusCode {
+		case http.StatusPartialContent:
+		case http.StatusRequestedRangeNotSatisfiable:
+			//empty file
+			size = 0
+			return nil
+		default:
+			return statusError(res)
+		}
+		contentRange := res.Header.Get("Content-Range")
+		i := strings.LastIndexByte(contentRange, '/')
+		if i < 0 {
+			return &permanentError{fmt.Errorf("invalid Content-Range %q of %v", contentRange, r.url)}
+		}
+		if size, err = strconv.ParseInt(contentRange[i+1:], 10, 64); err != nil {
+			return &permanentError{fmt.Errorf("invalid Content-Range %q of %v", contentRange, r.url)}
+		}
+		return nil
+	})
+	return size, err
+}
+
+// Read p at off with a Range request
+func (r *Reader) fetch(p []byte, off int64) error {
+	return r.retry(func(ctx context.Context) error {
+		res, err := r.do(ctx, http.MethodGet, fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		if res.StatusCode == http.StatusOK {
+			return &permanentError{fmt.Errorf("%v doesn't support Range requests", r.url)}
+		}
+		if res.StatusCode != http.StatusPartialContent {
+			return statusError(res)
+		}
+		_, err = io.ReadFull(res.Body, p)
+		return err
+	})
+}
+
+func (r *Reader) do(ctx context.Context, method string, rangeHeader string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, r.url, nil)
+	if err != nil {
+		return nil, &permanentError{err}
+	}
+	for key, values := range r.options.Header {
+		req.Header[key] = values
+	}
+	if rangeHeader != "" {
+		req.Header.Set("Range", rangeHeader)
+	}
+	return r.options.Client.Do(req)
+}
+
+// Call the request f until it succeeds, fails with a permanentError or the retries are exhausted
+func (r *Reader) retry(f func(ctx context.Context) error) error {
+	delay := r.options.RetryDelay
+	for i := 0; ; i++ {
+		err := r.attempt(f)
+		if err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if i >= r.options.Retries {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func (r *Reader) attempt(f func(ctx context.Context) error) error {
+	ctx := context.Background()
+	if r.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.options.Timeout)
+		defer cancel()
+	}
+	return f(ctx)
+}
+
+// Error of an unexpected status, only the 429 and 5xx statuses are retried
+func statusError(res *http.Response) error {
+	err := fmt.Errorf("%v %v: %v", res.Request.Method, res.Request.URL, res.Status)
+	if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500 {
+		return err
+	}
+	return &permanentError{err}
+}
@@ -0,0 +1,131 @@
+package httpfile
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Server of content, which fails the first failures requests and counts the GETs
+type server struct {
+	content  []byte
+	failures int32
+	noHead   bool
+	gets     int32
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if atomic.AddInt32(&s.failures, -1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodHead && s.noHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Method == http.MethodGet {
+		atomic.AddInt32(&s.gets, 1)
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(s.content))
+}
+
+func TestReader(t *testing.T) {
+	content := make([]byte, 1000)
+	for i := range content {
+		content[i] = byte(i)
+	}
+	s := &server{content: content, failures: 2, noHead: true}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	r, err := NewReader(ts.URL, Options{BlockSize: 100, CacheBlocks: 3, RetryDelay: time.Millisecond})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(content)), r.Size())
+
+	gets := atomic.LoadInt32(&s.gets)
+	buf := make([]byte, 150)
+	_, err = r.ReadAt(buf, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, content[50:200], buf)
+	assert.Equal(t, gets+1, atomic.LoadInt32(&s.gets))
+
+	//the cached blocks aren't fetched again
+	_, err = r.ReadAt(buf, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, content[:150], buf)
+	assert.Equal(t, gets+1, atomic.LoadInt32(&s.gets))
+
+	//the reads larger than the cache aren't cached
+	n, err := r.ReadAt(make([]byte, 500), 600)
+	assert.Equal(t, 400, n)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, gets+2, atomic.LoadInt32(&s.gets))
+	_, err = r.ReadAt(buf, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, gets+2, atomic.LoadInt32(&s.gets))
+
+	//the least recently used block is evicted
+	_, err = r.ReadAt(buf[:10], 950)
+	assert.NoError(t, err)
+	assert.Equal(t, content[950:960], buf[:10])
+	_, err = r.ReadAt(buf[:10], 150)
+	assert.NoError(t, err)
+	assert.Equal(t, gets+3, atomic.LoadInt32(&s.gets))
+	_, err = r.ReadAt(buf[:10], 250)
+	assert.NoError(t, err)
+	assert.Equal(t, gets+4, atomic.LoadInt32(&s.gets))
+
+	//the retries are exhausted
+	atomic.StoreInt32(&s.failures, 2)
+	_, err = NewReader(ts.URL, Options{Retries: 1, RetryDelay: time.Millisecond})
+	assert.Error(t, err)
+	atomic.StoreInt32(&s.failures, 0)
+
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	_, err = NewReader(notFound.URL, Options{})
+	assert.Error(t, err)
+}
+
+func TestHttpFile(t *testing.T) {
+	type Student struct {
+		Name string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Age  int32   `parquet:"name=age, type=INT32"`
+		Tags []int64 `parquet:"name=tags, type=INT64, repetitiontype=REPEATED"`
+	}
+	students := make([]Student, 1000)
+	for i := range students {
+		students[i] = Student{string(rune('a' + i%26)), int32(i), []int64{int64(i)}}
+	}
+	var buf bytes.Buffer
+	pw, err := writer.NewParquetWriter(writerfile.NewWriterFile(&buf), new(Student), 2)
+	assert.NoError(t, err)
+	for i, student := range students {
+		assert.NoError(t, pw.Write(student))
+		if i == 500 {
+			assert.NoError(t, pw.Flush(true))
+		}
+	}
+	assert.NoError(t, pw.WriteStop())
+
+	ts := httptest.NewServer(&server{content: buf.Bytes(), failures: 1})
+	defer ts.Close()
+	fr, err := NewHttpFile(ts.URL, Options{BlockSize: 1 << 10, RetryDelay: time.Millisecond})
+	assert.NoError(t, err)
+	pr, err := reader.NewParquetReader(fr, new(Student), 2)
+	assert.NoError(t, err)
+	assert.Greater(t, len(pr.Footer.RowGroups), 1)
+	res := make([]Student, len(students))
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, students, res)
+	pr.ReadStop()
+}
@@ -0,0 +1,186 @@
+// Package mem is an in-memory filesystem of ParquetFiles, to write parquet files in memory and read them back
+package mem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// FS is an in-memory filesystem of named files, which is safe for concurrent use
+type FS struct {
+	mutex sync.Mutex
+	files map[string]*fileData
+}
+
+// Content of a file, shared by its Files
+type fileData struct {
+	mutex sync.RWMutex
+	buf   []byte
+}
+
+// File is a cursor of a file of an FS, it's a source.ParquetFile.
+// The Files of the same file share its content but have their own offset.
+type File struct {
+	fs     *FS
+	name   string
+	data   *fileData
+	offset int64
+}
+
+// NewFS creates an empty FS
+func NewFS() *FS {
+	return &FS{files: make(map[string]*fileData)}
+}
+
+// Create creates the file name, it's truncated if it exists
+func (fs *FS) Create(name string) (*File, error) {
+	if name == "" {
+		return nil, errors.New("empty file name")
+	}
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	data := new(fileData)
+	fs.files[name] = data
+	return &File{fs: fs, name: name, data: data}, nil
+}
+
+// Open opens the file name to read it from the start
+func (fs *FS) Open(name string) (*File, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	data, ok := fs.files[name]
+	if !ok {
+		return nil, fmt.Errorf("file %v not found", name)
+	}
+	return &File{fs: fs, name: name, data: data}, nil
+}
+
+// WriteFile creates the file name with the content buf, which is copied
+func (fs *FS) WriteFile(name string, buf []byte) error {
+	file, err := fs.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(buf)
+	return err
+}
+
+// ReadFile returns a copy of the content of the file name
+func (fs *FS) ReadFile(name string) ([]byte, error) {
+	file, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return file.Bytes(), nil
+}
+
+// Remove removes the file name, its opened Files can still be used
+func (fs *FS) Remove(name string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	if _, ok := fs.files[name]; !ok {
+		return fmt.Errorf("file %v not found", name)
+	}
+	delete(fs.files, name)
+	return nil
+}
+
+// Names of the files
+func (fs *FS) Names() []string {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	names := make([]string, 0, len(fs.files))
+	for name := range fs.files {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Name of the file in its FS
+func (f *File) Name() string {
+	return f.name
+}
+
+// Bytes returns a copy of the content of the file
+func (f *File) Bytes() []byte {
+	f.data.mutex.RLock()
+	defer f.data.mutex.RUnlock()
+	return append([]byte(nil), f.data.buf...)
+}
+
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		f.data.mutex.RLock()
+		offset += int64(len(f.data.buf))
+		f.data.mutex.RUnlock()
+	default:
+		return 0, errors.New("Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("Seek: invalid offset")
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	f.data.mutex.RLock()
+	defer f.data.mutex.RUnlock()
+	if f.offset >= int64(len(f.data.buf)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data.buf[f.offset:])
+	f.offset += int64(n)
+	return n, nil
+}
+
+// Write p at the offset of the file, the file is extended with zeros if the offset is after its end
+func (f *File) Write(p []byte) (int, error) {
+	f.data.mutex.Lock()
+	defer f.data.mutex.Unlock()
+	end := f.offset + int64(len(p))
+	if end > int64(len(f.data.buf)) {
+		if end > int64(cap(f.data.buf)) {
+			buf := make([]byte, len(f.data.buf), 2*end)
+			copy(buf, f.data.buf)
+			f.data.buf = buf
+		}
+		f.data.buf = f.data.buf[:end]
+	}
+	copy(f.data.buf[f.offset:], p)
+	f.offset = end
+	return len(p), nil
+}
+
+func (f *File) Close() error {
+	return nil
+}
+
+// Open opens the file name of the FS, or a new cursor of the file if name is empty
+func (f *File) Open(name string) (source.ParquetFile, error) {
+	if name == "" {
+		return &File{fs: f.fs, name: f.name, data: f.data}, nil
+	}
+	file, err := f.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Create creates the file name in the FS
+func (f *File) Create(name string) (source.ParquetFile, error) {
+	file, err := f.fs.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
@@ -0,0 +1,95 @@
+package mem
+
+import (
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+func TestFile(t *testing.T) {
+	fs := NewFS()
+	file, err := fs.Create("a")
+	assert.NoError(t, err)
+	_, err = file.Write([]byte("0123"))
+	assert.NoError(t, err)
+	_, err = file.Seek(6, io.SeekStart)
+	assert.NoError(t, err)
+	_, err = file.Write([]byte("67"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("0123\x00\x0067"), file.Bytes())
+
+	//the opened files are independent cursors
+	c1, err := file.Open("")
+	assert.NoError(t, err)
+	c2, err := file.Open("")
+	assert.NoError(t, err)
+	_, err = c2.Seek(-2, io.SeekEnd)
+	assert.NoError(t, err)
+	buf := make([]byte, 2)
+	_, err = io.ReadFull(c1, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("01"), buf)
+	_, err = io.ReadFull(c2, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("67"), buf)
+	_, err = c2.Read(buf)
+	assert.Equal(t, io.EOF, err)
+
+	b, err := file.Create("b")
+	assert.NoError(t, err)
+	_, err = b.Write([]byte("b"))
+	assert.NoError(t, err)
+	opened, err := c1.Open("b")
+	assert.NoError(t, err)
+	content, err := io.ReadAll(opened)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("b"), content)
+
+	names := fs.Names()
+	sort.Strings(names)
+	assert.Equal(t, []string{"a", "b"}, names)
+	assert.NoError(t, fs.Remove("b"))
+	_, err = file.Open("b")
+	assert.Error(t, err)
+	_, err = fs.Create("")
+	assert.Error(t, err)
+}
+
+func TestWriteRead(t *testing.T) {
+	type Student struct {
+		Name string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Age  int32   `parquet:"name=age, type=INT32"`
+		Tags []int64 `parquet:"name=tags, type=INT64, repetitiontype=REPEATED"`
+	}
+	students := []Student{{"a", 10, []int64{1, 2}}, {"b", 11, nil}, {"c", 12, []int64{3}}}
+
+	fs := NewFS()
+	fw, err := fs.Create("students.parquet")
+	assert.NoError(t, err)
+	pw, err := writer.NewParquetWriter(fw, new(Student), 2)
+	assert.NoError(t, err)
+	for _, student := range students {
+		assert.NoError(t, pw.Write(student))
+	}
+	assert.NoError(t, pw.WriteStop())
+	assert.NoError(t, fw.Close())
+
+	fr, err := fs.Open("students.parquet")
+	assert.NoError(t, err)
+	pr, err := reader.NewParquetReader(fr, new(Student), 2)
+	assert.NoError(t, err)
+	res := make([]Student, len(students))
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, students, res)
+	pr.ReadStop()
+
+	cb, err := reader.NewColumnBuffer(fr, pr.Footer, pr.SchemaHandler, "Parquet_go_root\x01Age")
+	assert.NoError(t, err)
+	table, _, err := cb.ReadRows(int64(len(students)))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int32(10), int32(11), int32(12)}, table.Values)
+}
@@ -0,0 +1,139 @@
+package source
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// MappedFile is a ParquetFile whose bytes are in memory, such as an MmapFile.
+// The readers read its pages from its bytes without copying them: the values of
+// the UNCOMPRESSED columns with the PLAIN encoding of BYTE_ARRAY are views of its
+// bytes, which are only valid until it's unmapped.
+type MappedFile interface {
+	ParquetFile
+	//Bytes of the file, nil once it's unmapped
+	Bytes() []byte
+	//Release the bytes of the file, it can't be read anymore
+	Unmap() error
+}
+
+// MmapFile is a read only ParquetFile of a local file mapped in memory, see MappedFile.
+// The mapping is shared by the files returned by Open with an empty name. It's released
+// by Unmap, which is called by ParquetReader.ReadStop, or once all these files are closed.
+type MmapFile struct {
+	mapping *mapping
+	offset  int64
+	closed  bool
+}
+
+// Memory mapping of a file, shared by the MmapFiles of the file
+type mapping struct {
+	mutex sync.Mutex
+	data  []byte
+	//number of MmapFiles which aren't closed
+	refs     int
+	unmapped bool
+}
+
+var errUnmapped = errors.New("MmapFile is unmapped")
+
+// NewMmapFile maps the local file name in memory
+func NewMmapFile(name string) (*MmapFile, error) {
+	data, err := mapFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &MmapFile{mapping: &mapping{data: data, refs: 1}}, nil
+}
+
+func (f *MmapFile) Bytes() []byte {
+	f.mapping.mutex.Lock()
+	defer f.mapping.mutex.Unlock()
+	return f.mapping.data
+}
+
+func (f *MmapFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += int64(len(f.Bytes()))
+	default:
+		return 0, errors.New("Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("Seek: invalid offset")
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *MmapFile) Read(p []byte) (int, error) {
+	f.mapping.mutex.Lock()
+	defer f.mapping.mutex.Unlock()
+	if f.mapping.unmapped {
+		return 0, errUnmapped
+	}
+	if f.offset >= int64(len(f.mapping.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.mapping.data[f.offset:])
+	f.offset += int64(n)
+	return n, nil
+}
+
+func (f *MmapFile) Write(p []byte) (int, error) {
+	return 0, errors.New("MmapFile is read only")
+}
+
+// Close the file, the mapping is released when all the files sharing it are closed
+func (f *MmapFile) Close() error {
+	f.mapping.mutex.Lock()
+	defer f.mapping.mutex.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	if f.mapping.refs--; f.mapping.refs > 0 {
+		return nil
+	}
+	return f.mapping.unmap()
+}
+
+// Unmap releases the mapping of the file and of the files sharing it
+func (f *MmapFile) Unmap() error {
+	f.mapping.mutex.Lock()
+	defer f.mapping.mutex.Unlock()
+	return f.mapping.unmap()
+}
+
+// Open returns a file sharing the mapping if name is empty, otherwise it maps the file name
+func (f *MmapFile) Open(name string) (ParquetFile, error) {
+	if name != "" {
+		return NewMmapFile(name)
+	}
+	f.mapping.mutex.Lock()
+	defer f.mapping.mutex.Unlock()
+	if f.mapping.unmapped {
+		return nil, errUnmapped
+	}
+	f.mapping.refs++
+	return &MmapFile{mapping: f.mapping}, nil
+}
+
+func (f *MmapFile) Create(name string) (ParquetFile, error) {
+	return nil, errors.New("MmapFile is read only")
+}
+
+// Release the mapping, the mapping must be locked
+func (m *mapping) unmap() error {
+	if m.unmapped {
+		return nil
+	}
+	m.unmapped = true
+	data := m.data
+	m.data = nil
+	return unmapFile(data)
+}
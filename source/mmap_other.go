@@ -0,0 +1,17 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package source
+
+import (
+	"os"
+)
+
+// Read the file name in memory, as it can't be mapped
+func mapFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func unmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,51 @@
+package source
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMmapFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(path, []byte("0123456789"), 0644))
+
+	file, err := NewMmapFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("0123456789"), file.Bytes())
+	_, err = file.Seek(-4, io.SeekEnd)
+	assert.NoError(t, err)
+	buf, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("6789"), buf)
+
+	//the mapping is released when all the files sharing it are closed
+	opened, err := file.Open("")
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+	assert.NoError(t, file.Close())
+	assert.Equal(t, []byte("0123456789"), opened.(MappedFile).Bytes())
+	assert.NoError(t, opened.Close())
+	assert.Nil(t, file.Bytes())
+
+	file, err = NewMmapFile(path)
+	assert.NoError(t, err)
+	opened, err = file.Open("")
+	assert.NoError(t, err)
+	assert.NoError(t, file.Unmap())
+	_, err = opened.Read(make([]byte, 1))
+	assert.Error(t, err)
+	_, err = file.Open("")
+	assert.Error(t, err)
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	assert.NoError(t, os.WriteFile(empty, nil, 0644))
+	file, err = NewMmapFile(empty)
+	assert.NoError(t, err)
+	_, err = file.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+	assert.NoError(t, file.Close())
+}
@@ -0,0 +1,241 @@
+package source
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Range is a range of bytes of a file
+type Range struct {
+	Offset int64
+	Length int64
+}
+
+// End of the range, exclusive
+func (r Range) End() int64 {
+	return r.Offset + r.Length
+}
+
+// RangeReader is implemented by the files which can read ranges of bytes ahead,
+// such as ReaderAtFile. The readers of the file, including the ones returned by
+// Open, read the prefetched bytes from memory until the ranges are released.
+type RangeReader interface {
+	//Read the ranges, the ranges separated by at most maxGap bytes are read together
+	Prefetch(ranges []Range, maxGap int64) error
+	//Release ranges which were prefetched
+	Release(ranges []Range)
+}
+
+// CoalesceRanges sorts the ranges and merges the ones which overlap or are separated
+// by at most maxGap bytes. Empty ranges are removed.
+func CoalesceRanges(ranges []Range, maxGap int64) []Range {
+	sorted := make([]Range, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Length > 0 {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	res := make([]Range, 0, len(sorted))
+	for _, r := range sorted {
+		if n := len(res); n > 0 && r.Offset <= res[n-1].End()+maxGap {
+			if r.End() > res[n-1].End() {
+				res[n-1].Length = r.End() - res[n-1].Offset
+			}
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// ReaderAtFile is a read only ParquetFile of an io.ReaderAt of known size, such as
+// an object of an object store. Its reads are ranged reads of the io.ReaderAt.
+// It's a RangeReader: the prefetched ranges are read with one ReadAt call for
+// each coalesced range and are shared by the files returned by Open.
+type ReaderAtFile struct {
+	reader io.ReaderAt
+	size   int64
+	offset int64
+	cache  *rangeCache
+}
+
+// Prefetched bytes of a ReaderAtFile
+type rangeCache struct {
+	mutex  sync.Mutex
+	blocks []*rangeBlock
+}
+
+// Bytes read with one ReadAt call
+type rangeBlock struct {
+	Range
+	data []byte
+	//number of prefetched ranges in the block which aren't released
+	refs int
+}
+
+var errReadOnly = errors.New("ReaderAtFile is read only")
+
+// NewReaderAtFile creates a ReaderAtFile reading the size bytes of reader
+func NewReaderAtFile(reader io.ReaderAt, size int64) *ReaderAtFile {
+	return &ReaderAtFile{reader: reader, size: size, cache: new(rangeCache)}
+}
+
+// Size of the file
+func (f *ReaderAtFile) Size() int64 {
+	return f.size
+}
+
+func (f *ReaderAtFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	default:
+		return 0, errors.New("Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("Seek: invalid offset")
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// Read from the prefetched bytes if they contain the offset of the file, otherwise with
+// a ReadAt call. It doesn't read across the end of the prefetched bytes.
+func (f *ReaderAtFile) Read(p []byte) (int, error) {
+	if f.offset >= f.size {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > f.size-f.offset {
+		p = p[:f.size-f.offset]
+	}
+	if n := f.cache.read(p, f.offset); n > 0 {
+		f.offset += int64(n)
+		return n, nil
+	}
+
+	n, err := f.reader.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *ReaderAtFile) Write(p []byte) (int, error) {
+	return 0, errReadOnly
+}
+
+func (f *ReaderAtFile) Close() error {
+	return nil
+}
+
+// Open returns a new reader of the file, which shares its prefetched bytes. Only the
+// file itself can be opened, name must be empty.
+func (f *ReaderAtFile) Open(name string) (ParquetFile, error) {
+	if name != "" {
+		return nil, errors.New("ReaderAtFile can't open other files")
+	}
+	return &ReaderAtFile{reader: f.reader, size: f.size, cache: f.cache}, nil
+}
+
+func (f *ReaderAtFile) Create(name string) (ParquetFile, error) {
+	return nil, errReadOnly
+}
+
+// Prefetch reads the ranges, the ranges separated by at most maxGap bytes are read together.
+// The ranges already prefetched aren't read again. Each range must be released once.
+func (f *ReaderAtFile) Prefetch(ranges []Range, maxGap int64) error {
+	clipped := make([]Range, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Offset < 0 || r.Length <= 0 || r.Offset >= f.size {
+			continue
+		}
+		if r.End() > f.size {
+			r.Length = f.size - r.Offset
+		}
+		clipped = append(clipped, r)
+	}
+
+	var missing []Range
+	f.cache.mutex.Lock()
+	for _, r := range clipped {
+		if f.cache.find(r) == nil {
+			missing = append(missing, r)
+		}
+	}
+	f.cache.mutex.Unlock()
+
+	var blocks []*rangeBlock
+	for _, r := range CoalesceRanges(missing, maxGap) {
+		block := &rangeBlock{Range: r, data: make([]byte, r.Length)}
+		if n, err := f.reader.ReadAt(block.data, r.Offset); n < len(block.data) {
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		blocks = append(blocks, block)
+	}
+
+	f.cache.mutex.Lock()
+	defer f.cache.mutex.Unlock()
+	f.cache.blocks = append(f.cache.blocks, blocks...)
+	for _, r := range clipped {
+		if block := f.cache.find(r); block != nil {
+			block.refs++
+		}
+	}
+	return nil
+}
+
+// Release ranges which were prefetched, the bytes which aren't in other prefetched ranges are freed
+func (f *ReaderAtFile) Release(ranges []Range) {
+	f.cache.mutex.Lock()
+	defer f.cache.mutex.Unlock()
+	for _, r := range ranges {
+		if r.End() > f.size {
+			r.Length = f.size - r.Offset
+		}
+		if block := f.cache.find(r); block != nil && block.refs > 0 {
+			block.refs--
+		}
+	}
+
+	blocks := f.cache.blocks[:0]
+	for _, block := range f.cache.blocks {
+		if block.refs > 0 {
+			blocks = append(blocks, block)
+		}
+	}
+	for i := len(blocks); i < len(f.cache.blocks); i++ {
+		f.cache.blocks[i] = nil
+	}
+	f.cache.blocks = blocks
+}
+
+// Block containing the range, nil if there is none. The cache must be locked.
+func (c *rangeCache) find(r Range) *rangeBlock {
+	for _, block := range c.blocks {
+		if block.Offset <= r.Offset && r.End() <= block.End() {
+			return block
+		}
+	}
+	return nil
+}
+
+// Copy the prefetched bytes at offset to p, it returns 0 if they aren't prefetched
+func (c *rangeCache) read(p []byte, offset int64) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if block := c.find(Range{Offset: offset, Length: 1}); block != nil {
+		return copy(p, block.data[offset-block.Offset:])
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package source
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// io.ReaderAt counting its calls
+type countingReaderAt struct {
+	*bytes.Reader
+	calls int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.calls++
+	return r.Reader.ReadAt(p, off)
+}
+
+func TestCoalesceRanges(t *testing.T) {
+	ranges := []Range{{40, 10}, {0, 10}, {10, 5}, {20, 0}, {18, 4}, {45, 2}}
+	assert.Equal(t, []Range{{0, 15}, {18, 4}, {40, 10}}, CoalesceRanges(ranges, 0))
+	assert.Equal(t, []Range{{0, 22}, {40, 10}}, CoalesceRanges(ranges, 3))
+	assert.Equal(t, []Range{{0, 50}}, CoalesceRanges(ranges, 100))
+	assert.Empty(t, CoalesceRanges(nil, 0))
+}
+
+func TestReaderAtFile(t *testing.T) {
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	r := &countingReaderAt{Reader: bytes.NewReader(data)}
+	file := NewReaderAtFile(r, int64(len(data)))
+
+	pos, err := file.Seek(-10, io.SeekEnd)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(90), pos)
+	buf, err := io.ReadAll(file)
+	assert.NoError(t, err)
+	assert.Equal(t, data[90:], buf)
+	assert.Equal(t, 1, r.calls)
+
+	ranges := []Range{{10, 10}, {25, 5}, {60, 10}, {95, 10}}
+	assert.NoError(t, file.Prefetch(ranges, 5))
+	assert.Equal(t, 4, r.calls)
+
+	//the opened files read the prefetched bytes
+	opened, err := file.Open("")
+	assert.NoError(t, err)
+	buf = make([]byte, 20)
+	_, err = opened.Seek(10, io.SeekStart)
+	assert.NoError(t, err)
+	_, err = io.ReadFull(opened, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, data[10:30], buf)
+	_, err = opened.Seek(60, io.SeekStart)
+	assert.NoError(t, err)
+	_, err = io.ReadFull(opened, buf[:10])
+	assert.NoError(t, err)
+	assert.Equal(t, data[60:70], buf[:10])
+	assert.Equal(t, 4, r.calls)
+
+	//the bytes are read again once they are released
+	file.Release(ranges[:2])
+	_, err = opened.Seek(10, io.SeekStart)
+	assert.NoError(t, err)
+	_, err = io.ReadFull(opened, buf[:5])
+	assert.NoError(t, err)
+	assert.Equal(t, 5, r.calls)
+
+	_, err = file.Open("other")
+	assert.Error(t, err)
+	_, err = file.Write(buf)
+	assert.Error(t, err)
+}
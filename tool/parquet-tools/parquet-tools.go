@@ -18,19 +18,26 @@ import (
 	"github.com/xitongsys/parquet-go-source/s3"
 	"github.com/xitongsys/parquet-go/reader"
 	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/source/httpfile"
 	"github.com/xitongsys/parquet-go/tool/parquet-tools/schematool"
 	"github.com/xitongsys/parquet-go/tool/parquet-tools/sizetool"
+	"github.com/xitongsys/parquet-go/writer"
 )
 
 func main() {
-	cmd := flag.String("cmd", "schema", "command to run. Allowed values: schema, rowcount, size, cat")
-	fileName := flag.String("file", "", "file name")
+	cmd := flag.String("cmd", "schema", "command to run. Allowed values: schema, rowcount, size, cat, merge, split")
+	fileName := flag.String("file", "", "file name, comma separated file names with merge")
 	withTags := flag.Bool("tag", false, "show struct tags")
 	withPrettySize := flag.Bool("pretty", false, "show pretty size")
 	uncompressedSize := flag.Bool("uncompressed", false, "show uncompressed size")
 	catCount := flag.Int("count", 1000, "max count to cat. If it is nil, only show first 1000 records.")
 	skipCount := flag.Int64("skip", 0, "skip count with cat. If it is nil,skip 0 records.")
 	schemaFormat := flag.String("schema-format", "json", "schema format go/json (default to JSON schema)")
+	outputName := flag.String("output", "", "local file name of the merged file, or of the split files with their index before .parquet")
+	minRowGroupRows := flag.Int64("minrows", 0, "row groups with fewer rows are coalesced by merge. If it is 0, all the row groups are copied.")
+	numFiles := flag.Int64("numfiles", 0, "number of files of split, the row groups aren't cut. It can't be used with maxrows or maxsize.")
+	maxRows := flag.Int64("maxrows", 0, "max number of rows of the files of split. If it is 0, the rows aren't limited.")
+	maxSize := flag.Int64("maxsize", 0, "max size in bytes of the files of split. If it is 0, the size isn't limited.")
 
 	flag.Parse()
 
@@ -46,48 +53,15 @@ func main() {
 		os.Exit(1)
 	}
 
-	// validate file scheme (s3 or file)
-	uri, err := url.Parse(*fileName)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "unable to parse file location [%s]\n", *fileName)
-		os.Exit(1)
-	}
-	if uri.Scheme == "" {
-		uri.Scheme = "file"
-	}
-
-	var fr source.ParquetFile
-	switch uri.Scheme {
-	case "s3":
-		// determine S3 bucket's region
-		ctx := context.Background()
-		sess := session.Must(session.NewSession())
-		region, err := s3manager.GetBucketRegion(ctx, sess, uri.Host, "us-east-1")
-		if err != nil {
-			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NotFound" {
-				fmt.Fprintf(os.Stderr, "unable to find bucket %s's region not found", uri.Host)
-			} else {
-				fmt.Fprintf(os.Stderr, "AWS error: %s", err.Error())
-			}
-			os.Exit(1)
-		}
-
-		fr, err = s3.NewS3FileReader(ctx, uri.Host, strings.TrimLeft(uri.Path, "/"), &aws.Config{Region: aws.String(region)})
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to open S3 object [%s]: %s\n", *fileName, err.Error())
-			os.Exit(1)
-		}
-	case "file":
-		fr, err = local.NewLocalFileReader(uri.Path)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to open local file [%s]: %s\n", uri.Path, err.Error())
-			os.Exit(1)
-		}
-	default:
-		fmt.Fprintf(os.Stderr, "unknown location scheme [%s]\n", uri.Scheme)
-		os.Exit(1)
+	if *cmd == "merge" {
+		merge(strings.Split(*fileName, ","), *outputName, *minRowGroupRows)
+		return
+	} else if *cmd == "split" {
+		split(*fileName, *outputName, writer.SplitOptions{NumFiles: *numFiles, MaxRows: *maxRows, MaxSize: *maxSize})
+		return
 	}
 
+	fr := openFile(*fileName)
 	pr, err := reader.NewParquetReader(fr, nil, 1)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Can't create parquet reader: %s\n", err)
@@ -143,3 +117,100 @@ func main() {
 	}
 
 }
+
+// Open a local, S3 or HTTP parquet file, it exits if it fails
+func openFile(fileName string) source.ParquetFile {
+	// validate file scheme (s3, http, https or file)
+	uri, err := url.Parse(fileName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to parse file location [%s]\n", fileName)
+		os.Exit(1)
+	}
+	if uri.Scheme == "" {
+		uri.Scheme = "file"
+	}
+
+	var fr source.ParquetFile
+	switch uri.Scheme {
+	case "s3":
+		// determine S3 bucket's region
+		ctx := context.Background()
+		sess := session.Must(session.NewSession())
+		region, err := s3manager.GetBucketRegion(ctx, sess, uri.Host, "us-east-1")
+		if err != nil {
+			if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "NotFound" {
+				fmt.Fprintf(os.Stderr, "unable to find bucket %s's region not found", uri.Host)
+			} else {
+				fmt.Fprintf(os.Stderr, "AWS error: %s", err.Error())
+			}
+			os.Exit(1)
+		}
+
+		fr, err = s3.NewS3FileReader(ctx, uri.Host, strings.TrimLeft(uri.Path, "/"), &aws.Config{Region: aws.String(region)})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open S3 object [%s]: %s\n", fileName, err.Error())
+			os.Exit(1)
+		}
+	case "http", "https":
+		fr, err = httpfile.NewHttpFile(fileName, httpfile.Options{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open HTTP file [%s]: %s\n", fileName, err.Error())
+			os.Exit(1)
+		}
+	case "file":
+		fr, err = local.NewLocalFileReader(uri.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open local file [%s]: %s\n", uri.Path, err.Error())
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown location scheme [%s]\n", uri.Scheme)
+		os.Exit(1)
+	}
+	return fr
+}
+
+// Merge the files to a local file, it exits if it fails
+func merge(fileNames []string, outputName string, minRowGroupRows int64) {
+	if outputName == "" {
+		fmt.Fprintf(os.Stderr, "missing location of merged file\n")
+		os.Exit(1)
+	}
+
+	files := make([]source.ParquetFile, len(fileNames))
+	for i, fileName := range fileNames {
+		files[i] = openFile(fileName)
+	}
+	fw, err := local.NewLocalFileWriter(outputName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create local file [%s]: %s\n", outputName, err.Error())
+		os.Exit(1)
+	}
+	if err = writer.MergeFiles(fw, files, writer.MergeOptions{MinRowGroupRows: minRowGroupRows}); err != nil {
+		fmt.Fprintf(os.Stderr, "Can't merge: %s\n", err)
+		os.Exit(1)
+	}
+	if err = fw.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Can't close merged file: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// Split a file to local files named after outputName with their index, it exits if it fails
+func split(fileName string, outputName string, opts writer.SplitOptions) {
+	if outputName == "" {
+		fmt.Fprintf(os.Stderr, "missing location of split files\n")
+		os.Exit(1)
+	}
+
+	fr := openFile(fileName)
+	prefix := strings.TrimSuffix(outputName, ".parquet")
+	numFiles, err := writer.SplitFile(fr, func(index int) (source.ParquetFile, error) {
+		return local.NewLocalFileWriter(fmt.Sprintf("%s-%d.parquet", prefix, index))
+	}, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Can't split: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(numFiles)
+}
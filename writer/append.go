@@ -0,0 +1,91 @@
+package writer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/xitongsys/parquet-go/layout"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+var errEncryptedFile = errors.New("encrypted files can't be appended or merged")
+
+// NewParquetAppender opens an existing parquet file to write new row groups after its row groups.
+// pFile must be readable and writable, the new row groups are written from the start of its footer.
+// Obj is a object with tags or JSON schema string, as in NewParquetWriter, whose schema must match
+// the schema of the file. The schema of the file is used if obj is nil, its columns have no bloom filters.
+// The row groups, column and offset indexes, bloom filters and key-value metadata of the file are kept
+// in the new footer. Encrypted files can't be appended.
+func NewParquetAppender(pFile source.ParquetFile, obj interface{}, np int64) (*ParquetWriter, error) {
+	footer, footerStart, err := readFooter(pFile)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newParquetWriter(pFile, np)
+	if err != nil {
+		return nil, err
+	}
+	fileSchema := footer.Schema
+	if obj == nil {
+		obj = fileSchema
+	}
+	res.Footer = footer
+	res.Footer.Schema = nil
+	if err = res.setSchema(obj); err != nil {
+		return nil, err
+	}
+	if err = res.checkFileSchema(fileSchema); err != nil {
+		return nil, err
+	}
+
+	res.Offset = footerStart
+	if _, err = pFile.Seek(res.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	res.firstRowGroup = len(res.Footer.RowGroups)
+	res.stopped = false
+	return res, nil
+}
+
+// Read the footer of a file which isn't encrypted and its offset
+func readFooter(pFile source.ParquetFile) (*parquet.FileMetaData, int64, error) {
+	buf, encrypted, offset, err := layout.ReadFooter(pFile, layout.DefaultMaxFooterSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	if encrypted {
+		return nil, 0, errEncryptedFile
+	}
+	footer, err := layout.DecodeFooter(buf)
+	if err != nil {
+		return nil, 0, err
+	}
+	if footer.EncryptionAlgorithm != nil {
+		return nil, 0, errEncryptedFile
+	}
+	return footer, offset, nil
+}
+
+// Check that the schema of the writer is the schema of an appended file, their roots may have different names
+func (pw *ParquetWriter) checkFileSchema(fileSchema []*parquet.SchemaElement) error {
+	elements := pw.SchemaHandler.SchemaElements
+	if len(fileSchema) != len(elements) {
+		return fmt.Errorf("schema has %v elements, the schema of the file has %v", len(elements), len(fileSchema))
+	}
+	for i := 1; i < len(elements); i++ {
+		a, b := fileSchema[i], elements[i]
+		if a.GetName() != pw.SchemaHandler.Infos[i].ExName || a.GetNumChildren() != b.GetNumChildren() ||
+			a.IsSetType() != b.IsSetType() || a.GetType() != b.GetType() || a.GetTypeLength() != b.GetTypeLength() ||
+			a.GetRepetitionType() != b.GetRepetitionType() ||
+			a.IsSetConvertedType() != b.IsSetConvertedType() || a.GetConvertedType() != b.GetConvertedType() ||
+			a.GetScale() != b.GetScale() || a.GetPrecision() != b.GetPrecision() ||
+			(a.IsSetLogicalType() && b.IsSetLogicalType() && !reflect.DeepEqual(a.LogicalType, b.LogicalType)) {
+			return fmt.Errorf("%v doesn't match the schema of the file", pw.columnPath(pw.SchemaHandler.IndexMap[int32(i)]))
+		}
+	}
+	return nil
+}
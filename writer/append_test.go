@@ -0,0 +1,93 @@
+package writer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/bloomfilter"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func TestParquetAppender(t *testing.T) {
+	type Entry struct {
+		Id   int64  `parquet:"name=id, type=INT64"`
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, bloomfilter=true"`
+	}
+	entries := make([]Entry, 300)
+	for i := range entries {
+		entries[i] = Entry{Id: int64(i), Name: string(rune('a' + i%26))}
+	}
+
+	path := filepath.Join(t.TempDir(), "append.parquet")
+	fw, err := local.NewLocalFileWriter(path)
+	assert.NoError(t, err)
+	pw, err := NewParquetWriter(fw, new(Entry), 1)
+	assert.NoError(t, err)
+	key, value := "origin", "hourly"
+	pw.Footer.KeyValueMetadata = []*parquet.KeyValue{{Key: key, Value: &value}}
+	for _, entry := range entries[:100] {
+		assert.NoError(t, pw.Write(entry))
+	}
+	assert.NoError(t, pw.WriteStop())
+	assert.NoError(t, fw.Close())
+
+	appendEntries := func(obj interface{}, entries []Entry) {
+		file, err := os.OpenFile(path, os.O_RDWR, 0)
+		assert.NoError(t, err)
+		pw, err := NewParquetAppender(&local.LocalFile{FilePath: path, File: file}, obj, 2)
+		assert.NoError(t, err)
+		for _, entry := range entries {
+			assert.NoError(t, pw.Write(entry))
+		}
+		assert.NoError(t, pw.WriteStop())
+		assert.NoError(t, file.Close())
+	}
+	appendEntries(new(Entry), entries[100:250])
+	appendEntries(nil, entries[250:])
+
+	fr, err := local.NewLocalFileReader(path)
+	assert.NoError(t, err)
+	defer fr.Close()
+	pr, err := reader.NewParquetReader(fr, new(Entry), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(entries)), pr.GetNumRows())
+	assert.Len(t, pr.Footer.RowGroups, 3)
+	assert.Equal(t, []*parquet.KeyValue{{Key: key, Value: &value}}, pr.Footer.KeyValueMetadata)
+	res := make([]Entry, len(entries))
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, entries, res)
+
+	for i, rowGroup := range pr.Footer.RowGroups {
+		assert.Equal(t, []string{"Id"}, rowGroup.Columns[0].MetaData.PathInSchema)
+		pageIndex, err := pr.ReadPageIndex(int64(i), "Parquet_go_root\x01Id")
+		assert.NoError(t, err)
+		assert.NotNil(t, pageIndex.ColumnIndex)
+		var numRows int64
+		for _, page := range pageIndex.Pages {
+			numRows += page.NumRows
+		}
+		assert.Equal(t, rowGroup.NumRows, numRows)
+		bloomFilter, err := pr.ReadBloomFilter(int64(i), "Parquet_go_root\x01Name")
+		assert.NoError(t, err)
+		if i == 2 {
+			//the schema of the file has no bloom filters
+			assert.Nil(t, bloomFilter)
+		} else if assert.NotNil(t, bloomFilter) {
+			assert.True(t, bloomFilter.Check(bloomfilter.Hash("a")))
+		}
+	}
+
+	type Other struct {
+		Id   int32  `parquet:"name=id, type=INT32"`
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	}
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	assert.NoError(t, err)
+	defer file.Close()
+	_, err = NewParquetAppender(&local.LocalFile{FilePath: path, File: file}, new(Other), 1)
+	assert.EqualError(t, err, "id doesn't match the schema of the file")
+}
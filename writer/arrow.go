@@ -45,6 +45,7 @@ func NewArrowWriter(arrowSchema *arrow.Schema, pfile source.ParquetFile,
 	res.CompressionType = parquet.CompressionCodec_GZIP
 	res.PagesMapBuf = make(map[string][]*layout.Page)
 	res.DictRecs = make(map[string]*layout.DictRecType)
+	res.BloomFilterHashes = make(map[string]map[uint64]bool)
 	res.NP = np
 	res.Footer = parquet.NewFileMetaData()
 	res.Footer.Version = footerVersion
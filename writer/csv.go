@@ -36,6 +36,7 @@ func NewCSVWriter(md []string, pfile source.ParquetFile, np int64) (*CSVWriter,
 	res.CompressionType = parquet.CompressionCodec_SNAPPY
 	res.PagesMapBuf = make(map[string][]*layout.Page)
 	res.DictRecs = make(map[string]*layout.DictRecType)
+	res.BloomFilterHashes = make(map[string]map[uint64]bool)
 	res.NP = np
 	res.Footer = parquet.NewFileMetaData()
 	res.Footer.Version = 1
@@ -0,0 +1,60 @@
+package writer
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/marshal"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// GenericWriter is a ParquetWriter of the rows of type T, the schema is built from the tags of T.
+// The encoders of T are compiled once and reused for all the rows, see marshal.FastMarshaler.
+type GenericWriter[T any] struct {
+	*ParquetWriter
+}
+
+func NewGenericWriterFromWriter[T any](w io.Writer, np int64, opts ...ParquetWriterOptions) (*GenericWriter[T], error) {
+	wf := writerfile.NewWriterFile(w)
+	return NewGenericWriter[T](wf, np, opts...)
+}
+
+// Create a parquet writer of the rows of type T
+func NewGenericWriter[T any](pFile source.ParquetFile, np int64, opts ...ParquetWriterOptions) (*GenericWriter[T], error) {
+	pw, err := NewParquetWriter(pFile, new(T), np, opts...)
+	if err != nil {
+		return nil, err
+	}
+	//MarshalFast doesn't support maps
+	if !containsMap(reflect.TypeOf((*T)(nil)).Elem()) {
+		pw.MarshalFunc = new(marshal.FastMarshaler).Marshal
+	}
+	return &GenericWriter[T]{pw}, nil
+}
+
+// Write rows to parquet file, it returns the number of rows written
+func (w *GenericWriter[T]) Write(rows []T) (int, error) {
+	for i := range rows {
+		if err := w.ParquetWriter.Write(rows[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(rows), nil
+}
+
+func containsMap(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return containsMap(typ.Elem())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			if containsMap(typ.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -35,6 +35,7 @@ func NewJSONWriter(jsonSchema string, pfile source.ParquetFile, np int64) (*JSON
 	res.CompressionType = parquet.CompressionCodec_SNAPPY
 	res.PagesMapBuf = make(map[string][]*layout.Page)
 	res.DictRecs = make(map[string]*layout.DictRecType)
+	res.BloomFilterHashes = make(map[string]map[uint64]bool)
 	res.NP = np
 	res.Footer = parquet.NewFileMetaData()
 	res.Footer.Version = 1
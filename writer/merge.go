@@ -0,0 +1,381 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/thrift/lib/go/thrift"
+
+	"github.com/xitongsys/parquet-go/bloomfilter"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/layout"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// MergeOptions are the options of MergeFiles
+type MergeOptions struct {
+	//Row groups with fewer rows are re-encoded and coalesced with the next such row groups,
+	//to row groups of at least MinRowGroupRows rows. 0 copies all the row groups.
+	MinRowGroupRows int64
+}
+
+// MergeFiles concatenates the row groups of files with identical schemas to pFile.
+// The column chunks are copied without decoding them, only their offsets and the offsets
+// of their column and offset indexes and bloom filters are rewritten. The coalesced row
+// groups of MergeOptions.MinRowGroupRows keep the codecs of the columns in the first file
+// and are dictionary encoded if they were. The key-value metadata of the files are merged,
+// the first value of a key is kept. Encrypted files can't be merged.
+func MergeFiles(pFile source.ParquetFile, files []source.ParquetFile, opts ...MergeOptions) error {
+	var options MergeOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if len(files) == 0 {
+		return errors.New("no files to merge")
+	}
+
+	footers := make([]*parquet.FileMetaData, len(files))
+	for i, file := range files {
+		footer, _, err := readFooter(file)
+		if err != nil {
+			return fmt.Errorf("file %v: %v", i, err)
+		}
+		footers[i] = footer
+	}
+
+	pw, err := newCopyWriter(pFile, footers)
+	if err != nil {
+		return err
+	}
+	for i, file := range files {
+		for _, rowGroup := range footers[i].RowGroups {
+			if rowGroup.NumRows < options.MinRowGroupRows {
+				if err = pw.appendRowGroupPages(file, rowGroup); err != nil {
+					return fmt.Errorf("file %v: %v", i, err)
+				}
+				if pw.NumRows >= options.MinRowGroupRows {
+					err = pw.Flush(true)
+				}
+			} else if err = pw.Flush(true); err == nil {
+				err = pw.copyRowGroup(file, rowGroup)
+			}
+			if err != nil {
+				return fmt.Errorf("file %v: %v", i, err)
+			}
+		}
+	}
+	return pw.WriteStop()
+}
+
+// Writer of the row groups of files with the schema of the first footer, see MergeFiles
+func newCopyWriter(pFile source.ParquetFile, footers []*parquet.FileMetaData) (*ParquetWriter, error) {
+	pw, err := newParquetWriter(pFile, 1)
+	if err != nil {
+		return nil, err
+	}
+	if err = pw.setSchema(footers[0].Schema); err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(footers); i++ {
+		if err = pw.checkFileSchema(footers[i].Schema); err != nil {
+			return nil, fmt.Errorf("file %v: %v", i, err)
+		}
+	}
+	pw.Footer.ColumnOrders = footers[0].ColumnOrders
+	pw.Footer.KeyValueMetadata = mergeKeyValueMetadata(footers)
+	pw.setMergeInfos(footers)
+
+	if _, err = pFile.Write([]byte(pw.magic())); err != nil {
+		return nil, err
+	}
+	pw.stopped = false
+	return pw, nil
+}
+
+// Key-value metadata of the files, with the first value of each key
+func mergeKeyValueMetadata(footers []*parquet.FileMetaData) []*parquet.KeyValue {
+	var res []*parquet.KeyValue
+	keys := make(map[string]bool)
+	for _, footer := range footers {
+		for _, keyValue := range footer.KeyValueMetadata {
+			if !keys[keyValue.Key] {
+				keys[keyValue.Key] = true
+				res = append(res, keyValue)
+			}
+		}
+	}
+	return res
+}
+
+// Set the codecs and encodings of the columns of the coalesced row groups from the first column chunks of the files
+func (pw *ParquetWriter) setMergeInfos(footers []*parquet.FileMetaData) {
+	for _, footer := range footers {
+		if len(footer.RowGroups) == 0 {
+			continue
+		}
+		for _, chunk := range footer.RowGroups[0].Columns {
+			name := pw.chunkPathStr(chunk)
+			index, ok := pw.SchemaHandler.MapIndex[name]
+			if !ok || chunk.MetaData == nil {
+				continue
+			}
+			info := *pw.SchemaHandler.Infos[index]
+			codec := chunk.MetaData.Codec
+			info.Compression = &codec
+			for _, encoding := range chunk.MetaData.Encodings {
+				if encoding == parquet.Encoding_PLAIN_DICTIONARY || encoding == parquet.Encoding_RLE_DICTIONARY {
+					info.Encoding = parquet.Encoding_RLE_DICTIONARY
+				}
+			}
+			info.BloomFilter = chunk.MetaData.BloomFilterOffset != nil
+			pw.SchemaHandler.Infos[index] = &info
+		}
+		return
+	}
+}
+
+// Path of a column chunk in the schema handler
+func (pw *ParquetWriter) chunkPathStr(chunk *parquet.ColumnChunk) string {
+	path := append([]string{pw.SchemaHandler.GetRootExName()}, chunk.GetMetaData().GetPathInSchema()...)
+	return pw.SchemaHandler.ExPathToInPath[common.PathToStr(path)]
+}
+
+// Check that a column chunk of a merged file is in the file and isn't encrypted
+func checkMergedChunk(chunk *parquet.ColumnChunk) error {
+	if chunk.FilePath != nil {
+		return fmt.Errorf("column chunk in file %v", *chunk.FilePath)
+	} else if chunk.CryptoMetadata != nil || chunk.MetaData == nil {
+		return errEncryptedFile
+	}
+	return nil
+}
+
+// Copy a row group of a file after the written row groups without decoding its pages
+func (pw *ParquetWriter) copyRowGroup(pFile source.ParquetFile, rowGroup *parquet.RowGroup) error {
+	res := *rowGroup
+	res.Columns = make([]*parquet.ColumnChunk, len(rowGroup.Columns))
+	if rowGroup.FileOffset != nil {
+		offset := pw.Offset
+		res.FileOffset = &offset
+	}
+	if rowGroup.Ordinal != nil {
+		ordinal := int16(len(pw.Footer.RowGroups))
+		res.Ordinal = &ordinal
+	}
+
+	for i, chunk := range rowGroup.Columns {
+		if err := checkMergedChunk(chunk); err != nil {
+			return err
+		}
+		name := pw.chunkPathStr(chunk)
+		if _, ok := pw.SchemaHandler.MapIndex[name]; !ok {
+			return fmt.Errorf("unknown column %v", common.PathToStr(chunk.MetaData.PathInSchema))
+		}
+
+		columnIndex, offsetIndex, err := readChunkIndexes(pFile, chunk)
+		if err != nil {
+			return err
+		}
+
+		offset := layout.ChunkOffset(chunk.MetaData)
+		if _, err = pFile.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err = io.CopyN(pw.PFile, pFile, chunk.MetaData.TotalCompressedSize); err != nil {
+			return err
+		}
+		delta := pw.Offset - offset
+		pw.Offset += chunk.MetaData.TotalCompressedSize
+
+		metaData := *chunk.MetaData
+		//the paths are renamed by WriteStop
+		metaData.PathInSchema = common.StrToPath(name)
+		metaData.DataPageOffset += delta
+		//the dictionary page offsets which aren't the start of the chunk, such as 0, are cleared
+		if metaData.DictionaryPageOffset != nil && *metaData.DictionaryPageOffset == offset {
+			dictionaryPageOffset := offset + delta
+			metaData.DictionaryPageOffset = &dictionaryPageOffset
+		} else {
+			metaData.DictionaryPageOffset = nil
+		}
+		if metaData.IndexPageOffset != nil {
+			indexPageOffset := *metaData.IndexPageOffset + delta
+			metaData.IndexPageOffset = &indexPageOffset
+		}
+		if offsetIndex != nil {
+			for _, pageLocation := range offsetIndex.PageLocations {
+				pageLocation.Offset += delta
+			}
+		}
+
+		resChunk := *chunk
+		resChunk.MetaData = &metaData
+		resChunk.FileOffset += delta
+		resChunk.ColumnIndexOffset, resChunk.ColumnIndexLength = nil, nil
+		resChunk.OffsetIndexOffset, resChunk.OffsetIndexLength = nil, nil
+		res.Columns[i] = &resChunk
+		pw.ColumnIndexes = append(pw.ColumnIndexes, columnIndex)
+		pw.OffsetIndexes = append(pw.OffsetIndexes, offsetIndex)
+	}
+
+	//write the bloom filters after the chunks of the row group
+	for _, chunk := range res.Columns {
+		if chunk.MetaData.BloomFilterOffset == nil {
+			continue
+		}
+		if _, err := pFile.Seek(*chunk.MetaData.BloomFilterOffset, io.SeekStart); err != nil {
+			return err
+		}
+		filter, err := bloomfilter.Read(pFile)
+		if err != nil {
+			return err
+		}
+		data, err := filter.Marshal()
+		if err != nil {
+			return err
+		}
+		if _, err = pw.PFile.Write(data); err != nil {
+			return err
+		}
+		offset := pw.Offset
+		chunk.MetaData.BloomFilterOffset = &offset
+		pw.Offset += int64(len(data))
+	}
+
+	pw.Footer.RowGroups = append(pw.Footer.RowGroups, &res)
+	pw.Footer.NumRows += res.NumRows
+	return nil
+}
+
+// Read the column and offset indexes of a column chunk, they are nil if the chunk has none
+func readChunkIndexes(pFile source.ParquetFile, chunk *parquet.ColumnChunk) (*parquet.ColumnIndex, *parquet.OffsetIndex, error) {
+	var columnIndex *parquet.ColumnIndex
+	var offsetIndex *parquet.OffsetIndex
+	if chunk.ColumnIndexOffset != nil && chunk.ColumnIndexLength != nil {
+		columnIndex = parquet.NewColumnIndex()
+		if err := readThriftStruct(pFile, *chunk.ColumnIndexOffset, *chunk.ColumnIndexLength, columnIndex); err != nil {
+			return nil, nil, fmt.Errorf("failed to read column index: %v", err)
+		}
+	}
+	if chunk.OffsetIndexOffset != nil && chunk.OffsetIndexLength != nil {
+		offsetIndex = parquet.NewOffsetIndex()
+		if err := readThriftStruct(pFile, *chunk.OffsetIndexOffset, *chunk.OffsetIndexLength, offsetIndex); err != nil {
+			return nil, nil, fmt.Errorf("failed to read offset index: %v", err)
+		}
+	}
+	return columnIndex, offsetIndex, nil
+}
+
+// Read a thrift struct of length bytes at offset of the file
+func readThriftStruct(pFile source.ParquetFile, offset int64, length int32, obj thrift.TStruct) error {
+	if length <= 0 {
+		return fmt.Errorf("invalid thrift struct length %v", length)
+	}
+	if _, err := pFile.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(pFile, buf); err != nil {
+		return err
+	}
+	td := thrift.NewTDeserializer()
+	td.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(td.Transport)
+	return td.Read(context.TODO(), obj, buf)
+}
+
+// Decode the column chunks of a row group of a file and add their pages to the next row group
+func (pw *ParquetWriter) appendRowGroupPages(pFile source.ParquetFile, rowGroup *parquet.RowGroup) error {
+	tables, err := pw.readRowGroupTables(pFile, rowGroup)
+	if err != nil {
+		return err
+	}
+	return pw.appendTablePages(tables, rowGroup.NumRows)
+}
+
+// Decode the column chunks of a row group of a file to tables by their paths
+func (pw *ParquetWriter) readRowGroupTables(pFile source.ParquetFile, rowGroup *parquet.RowGroup) (map[string]*layout.Table, error) {
+	tables := make(map[string]*layout.Table, len(rowGroup.Columns))
+	for _, chunk := range rowGroup.Columns {
+		if err := checkMergedChunk(chunk); err != nil {
+			return nil, err
+		}
+		name := pw.chunkPathStr(chunk)
+		index, ok := pw.SchemaHandler.MapIndex[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %v", common.PathToStr(chunk.MetaData.PathInSchema))
+		}
+
+		table, err := pw.readChunkTable(pFile, chunk, name)
+		if err != nil {
+			return nil, err
+		}
+		if table == nil {
+			continue
+		}
+		table.Schema = pw.SchemaHandler.SchemaElements[index]
+		table.RepetitionType = table.Schema.GetRepetitionType()
+		table.Info = pw.SchemaHandler.Infos[index]
+		tables[name] = table
+	}
+	return tables, nil
+}
+
+// Encode the tables of numRows rows and add their pages to the next row group
+func (pw *ParquetWriter) appendTablePages(tables map[string]*layout.Table, numRows int64) error {
+	for name, table := range tables {
+		pages, err := pw.chunkTableToPages(name, table)
+		if err != nil {
+			return err
+		}
+		for _, page := range pages {
+			pw.Size += int64(len(page.RawData))
+			page.DataTable = nil //release memory
+		}
+		pw.PagesMapBuf[name] = append(pw.PagesMapBuf[name], pages...)
+	}
+	pw.NumRows += numRows
+	pw.Footer.NumRows += numRows
+	return nil
+}
+
+// Encode the table of a column chunk
+func (pw *ParquetWriter) chunkTableToPages(name string, table *layout.Table) ([]*layout.Page, error) {
+	//the writer of MergeFiles has a single goroutine
+	pages, err := pw.tableToPages(name, table, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode column %v: %v", pw.columnPath(name), err)
+	}
+	return pages, nil
+}
+
+// Decode the values of a column chunk to a table, it's nil if the chunk has no values
+func (pw *ParquetWriter) readChunkTable(pFile source.ParquetFile, chunk *parquet.ColumnChunk, name string) (table *layout.Table, err error) {
+	//the pages are read with the paths of the schema handler
+	metaData := *chunk.MetaData
+	metaData.PathInSchema = common.StrToPath(name)[1:]
+	thriftReader := source.ConvertToThriftReader(pFile, layout.ChunkOffset(chunk.MetaData))
+
+	var dictPage *layout.Page
+	for readValues := int64(0); readValues < metaData.NumValues; {
+		page, numValues, _, err := layout.ReadPageVector(thriftReader, pw.SchemaHandler, &metaData)
+		if err != nil {
+			return nil, fmt.Errorf("column %v: %v", pw.columnPath(name), err)
+		}
+		if page.Header.GetType() == parquet.PageType_DICTIONARY_PAGE {
+			dictPage = page
+			continue
+		}
+		if err = page.Decode(dictPage); err != nil {
+			return nil, fmt.Errorf("column %v: %v", pw.columnPath(name), err)
+		}
+		if table == nil {
+			table = layout.NewTableFromTable(page.DataTable)
+		}
+		table.Merge(page.DataTable)
+		readValues += numValues
+	}
+	return table, nil
+}
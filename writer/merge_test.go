@@ -0,0 +1,219 @@
+package writer
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/bloomfilter"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/source/mem"
+)
+
+func TestMergeFiles(t *testing.T) {
+	type Entry struct {
+		Id    int64   `parquet:"name=id, type=INT64"`
+		Name  string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY, bloomfilter=true"`
+		Score *int32  `parquet:"name=score, type=INT32, repetitiontype=OPTIONAL"`
+		Tags  []int32 `parquet:"name=tags, type=INT32, repetitiontype=REPEATED"`
+	}
+	entries := make([]Entry, 1000)
+	for i := range entries {
+		entries[i] = Entry{Id: int64(i), Name: string(rune('a' + i%26))}
+		for j := 0; j < i%3; j++ {
+			entries[i].Tags = append(entries[i].Tags, int32(j))
+		}
+		if i%2 == 0 {
+			score := int32(i)
+			entries[i].Score = &score
+		}
+	}
+
+	dir := t.TempDir()
+	//row groups of 300 and 10 / 10, 200 and 5 / 5 and 470 rows
+	rowGroups := [][][]Entry{
+		{entries[:300], entries[300:310]},
+		{entries[310:320], entries[320:520], entries[520:525]},
+		{entries[525:530], entries[530:]},
+	}
+	files := make([]source.ParquetFile, len(rowGroups))
+	for i, fileRowGroups := range rowGroups {
+		path := filepath.Join(dir, string(rune('a'+i))+".parquet")
+		fw, err := local.NewLocalFileWriter(path)
+		assert.NoError(t, err)
+		pw, err := NewParquetWriter(fw, new(Entry), 1)
+		assert.NoError(t, err)
+		pw.CompressionType = parquet.CompressionCodec_GZIP
+		value := string(rune('a' + i))
+		pw.Footer.KeyValueMetadata = []*parquet.KeyValue{{Key: "file", Value: &value}}
+		for _, rowGroup := range fileRowGroups {
+			for _, entry := range rowGroup {
+				assert.NoError(t, pw.Write(entry))
+			}
+			assert.NoError(t, pw.Flush(true))
+		}
+		assert.NoError(t, pw.WriteStop())
+		assert.NoError(t, fw.Close())
+
+		files[i], err = local.NewLocalFileReader(path)
+		assert.NoError(t, err)
+		defer files[i].Close()
+	}
+
+	merge := func(opts MergeOptions) *reader.ParquetReader {
+		path := filepath.Join(dir, "merged.parquet")
+		fw, err := local.NewLocalFileWriter(path)
+		assert.NoError(t, err)
+		assert.NoError(t, MergeFiles(fw, files, opts))
+		assert.NoError(t, fw.Close())
+
+		fr, err := local.NewLocalFileReader(path)
+		assert.NoError(t, err)
+		t.Cleanup(func() { fr.Close() })
+		pr, err := reader.NewParquetReader(fr, new(Entry), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(entries)), pr.GetNumRows())
+		file := "a"
+		assert.Equal(t, []*parquet.KeyValue{{Key: "file", Value: &file}}, pr.Footer.KeyValueMetadata)
+		res := make([]Entry, len(entries))
+		assert.NoError(t, pr.Read(&res))
+		assert.Equal(t, entries, res)
+
+		firstRow := 0
+		for i, rowGroup := range pr.Footer.RowGroups {
+			assert.Equal(t, []string{"Name"}, rowGroup.Columns[1].MetaData.PathInSchema)
+			assert.Equal(t, parquet.CompressionCodec_GZIP, rowGroup.Columns[1].MetaData.Codec)
+			assert.NotNil(t, rowGroup.Columns[1].MetaData.DictionaryPageOffset)
+			pageIndex, err := pr.ReadPageIndex(int64(i), "Parquet_go_root\x01Id")
+			assert.NoError(t, err)
+			assert.Equal(t, rowGroup.Columns[0].MetaData.DataPageOffset, pageIndex.Pages[0].Offset)
+			var numRows int64
+			for _, page := range pageIndex.Pages {
+				numRows += page.NumRows
+			}
+			assert.Equal(t, rowGroup.NumRows, numRows)
+			bloomFilter, err := pr.ReadBloomFilter(int64(i), "Parquet_go_root\x01Name")
+			assert.NoError(t, err)
+			if assert.NotNil(t, bloomFilter) {
+				assert.True(t, bloomFilter.Check(bloomfilter.Hash(entries[firstRow].Name)))
+			}
+			firstRow += int(rowGroup.NumRows)
+		}
+		return pr
+	}
+
+	pr := merge(MergeOptions{})
+	var numRows []int64
+	for _, rowGroup := range pr.Footer.RowGroups {
+		numRows = append(numRows, rowGroup.NumRows)
+	}
+	assert.Equal(t, []int64{300, 10, 10, 200, 5, 5, 470}, numRows)
+
+	//the consecutive row groups of 10 and 5 rows are coalesced, even across files
+	pr = merge(MergeOptions{MinRowGroupRows: 100})
+	numRows = nil
+	for _, rowGroup := range pr.Footer.RowGroups {
+		numRows = append(numRows, rowGroup.NumRows)
+	}
+	assert.Equal(t, []int64{300, 20, 200, 10, 470}, numRows)
+
+	type Other struct {
+		Id   int32  `parquet:"name=id, type=INT32"`
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	}
+	path := filepath.Join(dir, "other.parquet")
+	fw, err := local.NewLocalFileWriter(path)
+	assert.NoError(t, err)
+	pw, err := NewParquetWriter(fw, new(Other), 1)
+	assert.NoError(t, err)
+	assert.NoError(t, pw.WriteStop())
+	assert.NoError(t, fw.Close())
+	fr, err := local.NewLocalFileReader(path)
+	assert.NoError(t, err)
+	defer fr.Close()
+	fw, err = local.NewLocalFileWriter(filepath.Join(dir, "merged.parquet"))
+	assert.NoError(t, err)
+	defer fw.Close()
+	assert.EqualError(t, MergeFiles(fw, []source.ParquetFile{files[0], fr}), "file 1: schema has 5 elements, the schema of the file has 3")
+}
+
+func TestMergeZeroDictionaryPageOffset(t *testing.T) {
+	type Entry struct {
+		Id   int64  `parquet:"name=id, type=INT64"`
+		Name string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	}
+	entries := make([]Entry, 600)
+	for i := range entries {
+		entries[i] = Entry{Id: int64(i), Name: string(rune('a' + i%26))}
+	}
+
+	fs := mem.NewFS()
+	for i, name := range []string{"a.parquet", "b.parquet"} {
+		fw, err := fs.Create(name)
+		assert.NoError(t, err)
+		pw, err := NewParquetWriter(fw, new(Entry), 1)
+		assert.NoError(t, err)
+		for _, entry := range entries[i*300 : (i+1)*300] {
+			assert.NoError(t, pw.Write(entry))
+		}
+		assert.NoError(t, pw.WriteStop())
+	}
+
+	//the chunks of b without dictionary get a dictionary page offset of 0, as written by some writers
+	data, err := fs.ReadFile("b.parquet")
+	assert.NoError(t, err)
+	fr, err := fs.Open("b.parquet")
+	assert.NoError(t, err)
+	footer, offset, err := readFooter(fr)
+	assert.NoError(t, err)
+	for _, rowGroup := range footer.RowGroups {
+		for _, chunk := range rowGroup.Columns {
+			if chunk.MetaData.DictionaryPageOffset == nil {
+				chunk.MetaData.DictionaryPageOffset = new(int64)
+			}
+		}
+	}
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	footerBuf, err := ts.Write(context.TODO(), footer)
+	assert.NoError(t, err)
+	data = append(data[:offset], footerBuf...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(footerBuf)))
+	assert.NoError(t, fs.WriteFile("b.parquet", append(data, "PAR1"...)))
+
+	read := func(name string, numRows int) []Entry {
+		fr, err := fs.Open(name)
+		assert.NoError(t, err)
+		pr, err := reader.NewParquetReader(fr, new(Entry), 1)
+		assert.NoError(t, err)
+		res := make([]Entry, numRows)
+		assert.NoError(t, pr.Read(&res))
+		pr.ReadStop()
+		return res
+	}
+	assert.Equal(t, entries[300:], read("b.parquet", 300))
+
+	a, err := fs.Open("a.parquet")
+	assert.NoError(t, err)
+	b, err := fs.Open("b.parquet")
+	assert.NoError(t, err)
+	fw, err := fs.Create("merged.parquet")
+	assert.NoError(t, err)
+	assert.NoError(t, MergeFiles(fw, []source.ParquetFile{a, b}, MergeOptions{}))
+	assert.Equal(t, entries, read("merged.parquet", 600))
+
+	numFiles, err := SplitFile(b, func(index int) (source.ParquetFile, error) {
+		return fs.Create(fmt.Sprintf("split%d.parquet", index))
+	}, SplitOptions{MaxRows: 200})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, numFiles)
+	assert.Equal(t, entries[300:500], read("split0.parquet", 200))
+	assert.Equal(t, entries[500:], read("split1.parquet", 100))
+}
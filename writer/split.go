@@ -0,0 +1,213 @@
+package writer
+
+import (
+	"errors"
+
+	"github.com/xitongsys/parquet-go/layout"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/source"
+)
+
+// SplitOptions are the options of SplitFile, one of them must be set
+type SplitOptions struct {
+	//Number of files with about the same number of rows, the row groups aren't cut.
+	//It can't be set with MaxRows or MaxSize.
+	NumFiles int64
+	//Max number of rows of a file, 0 means no limit
+	MaxRows int64
+	//Max size in bytes of the column chunks of a file, 0 means no limit
+	MaxSize int64
+}
+
+// SplitFile splits the row groups of pFile to the files returned by create with their
+// indexes, which are closed after their footer is written. It returns the number of files.
+// The row groups are copied without decoding them, as in MergeFiles. The row groups which
+// must be cut for SplitOptions.MaxRows or SplitOptions.MaxSize are re-encoded, their size
+// is estimated from the size of their rows. Encrypted files can't be split.
+func SplitFile(pFile source.ParquetFile, create func(index int) (source.ParquetFile, error), opts SplitOptions) (int, error) {
+	if opts.NumFiles <= 0 && opts.MaxRows <= 0 && opts.MaxSize <= 0 {
+		return 0, errors.New("no split option is set")
+	}
+	if opts.NumFiles > 0 && (opts.MaxRows > 0 || opts.MaxSize > 0) {
+		return 0, errors.New("the number of files can't be set with the max rows or size of the files")
+	}
+	footer, _, err := readFooter(pFile)
+	if err != nil {
+		return 0, err
+	}
+
+	s := &splitter{file: pFile, footer: footer, create: create, options: opts}
+	var firstRow int64
+	for _, rowGroup := range footer.RowGroups {
+		if opts.NumFiles > 0 {
+			err = s.splitByNumFiles(rowGroup, firstRow)
+		} else {
+			err = s.split(rowGroup)
+		}
+		if err != nil {
+			s.close()
+			return s.numFiles, err
+		}
+		firstRow += rowGroup.NumRows
+	}
+	if s.pw == nil {
+		//the file has no rows
+		if err = s.next(); err != nil {
+			return s.numFiles, err
+		}
+	}
+	return s.numFiles, s.finish()
+}
+
+// State of SplitFile
+type splitter struct {
+	file    source.ParquetFile
+	footer  *parquet.FileMetaData
+	create  func(index int) (source.ParquetFile, error)
+	options SplitOptions
+
+	numFiles int
+	//Writer of the current file, its number of rows and size
+	pw   *ParquetWriter
+	rows int64
+	size int64
+}
+
+// Start the next file
+func (s *splitter) next() error {
+	if err := s.finish(); err != nil {
+		return err
+	}
+	pFile, err := s.create(s.numFiles)
+	if err != nil {
+		return err
+	}
+	s.numFiles++
+	if s.pw, err = newCopyWriter(pFile, []*parquet.FileMetaData{s.footer}); err != nil {
+		pFile.Close()
+		s.pw = nil
+		return err
+	}
+	s.rows, s.size = 0, 0
+	return nil
+}
+
+// Write the footer of the current file and close it
+func (s *splitter) finish() error {
+	if s.pw == nil {
+		return nil
+	}
+	pw := s.pw
+	s.pw = nil
+	if err := pw.WriteStop(); err != nil {
+		pw.PFile.Close()
+		return err
+	}
+	return pw.PFile.Close()
+}
+
+// Close the current file after an error
+func (s *splitter) close() {
+	if s.pw != nil {
+		s.pw.PFile.Close()
+		s.pw = nil
+	}
+}
+
+// Copy a row group to the file of its first row
+func (s *splitter) splitByNumFiles(rowGroup *parquet.RowGroup, firstRow int64) error {
+	index := int64(0)
+	if s.footer.NumRows > 0 {
+		index = firstRow * s.options.NumFiles / s.footer.NumRows
+	}
+	if s.pw == nil || index >= int64(s.numFiles) {
+		if err := s.next(); err != nil {
+			return err
+		}
+	}
+	return s.pw.copyRowGroup(s.file, rowGroup)
+}
+
+// Number of rows of a row group which fit in the current file
+func (s *splitter) fit(rows, size int64) int64 {
+	n := rows
+	if s.options.MaxRows > 0 && s.options.MaxRows-s.rows < n {
+		n = s.options.MaxRows - s.rows
+	}
+	if s.options.MaxSize > 0 && size > 0 && (s.options.MaxSize-s.size)*rows/size < n {
+		n = (s.options.MaxSize - s.size) * rows / size
+	}
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// Copy a row group to the current files, it's cut if it doesn't fit in a file
+func (s *splitter) split(rowGroup *parquet.RowGroup) error {
+	rows, size := rowGroup.NumRows, compressedSize(rowGroup)
+	n := s.fit(rows, size)
+	if s.pw == nil || (n < rows && s.rows > 0) {
+		if err := s.next(); err != nil {
+			return err
+		}
+		n = s.fit(rows, size)
+	}
+	if n >= rows {
+		s.rows += rows
+		s.size += size
+		return s.pw.copyRowGroup(s.file, rowGroup)
+	}
+
+	tables, err := s.pw.readRowGroupTables(s.file, rowGroup)
+	if err != nil {
+		return err
+	}
+	for left := rows; left > 0; {
+		//a file has at least one row
+		if n == 0 {
+			n = 1
+		}
+		if n > left {
+			n = left
+		}
+		if err = s.pw.appendTablePages(popTables(tables, n), n); err != nil {
+			return err
+		}
+		if err = s.pw.Flush(true); err != nil {
+			return err
+		}
+		s.rows += n
+		s.size += size * n / rows
+
+		if left -= n; left > 0 {
+			if err = s.next(); err != nil {
+				return err
+			}
+			n = s.fit(rows, size)
+		}
+	}
+	return nil
+}
+
+// Pop numRows rows of the tables
+func popTables(tables map[string]*layout.Table, numRows int64) map[string]*layout.Table {
+	res := make(map[string]*layout.Table, len(tables))
+	for name, table := range tables {
+		res[name] = table.Pop(numRows)
+		//the levels of the popped rows may be lower than the levels of the column
+		res[name].MaxDefinitionLevel = table.MaxDefinitionLevel
+		res[name].MaxRepetitionLevel = table.MaxRepetitionLevel
+		res[name].RepetitionType = table.RepetitionType
+	}
+	return res
+}
+
+// Compressed size of the column chunks of a row group
+func compressedSize(rowGroup *parquet.RowGroup) int64 {
+	var size int64
+	for _, chunk := range rowGroup.Columns {
+		size += chunk.GetMetaData().GetTotalCompressedSize()
+	}
+	return size
+}
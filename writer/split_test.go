@@ -0,0 +1,87 @@
+package writer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/source"
+	"github.com/xitongsys/parquet-go/source/mem"
+)
+
+func TestSplitFile(t *testing.T) {
+	type Entry struct {
+		Id    int64   `parquet:"name=id, type=INT64"`
+		Name  string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		Score *int32  `parquet:"name=score, type=INT32, repetitiontype=OPTIONAL"`
+		Tags  []int32 `parquet:"name=tags, type=INT32, repetitiontype=REPEATED"`
+	}
+	entries := make([]Entry, 1000)
+	for i := range entries {
+		entries[i] = Entry{Id: int64(i), Name: string(rune('a' + i%26))}
+		for j := 0; j < i%3; j++ {
+			entries[i].Tags = append(entries[i].Tags, int32(j))
+		}
+		//no scores after the 700th row
+		if i%2 == 0 && i < 700 {
+			score := int32(i)
+			entries[i].Score = &score
+		}
+	}
+
+	fs := mem.NewFS()
+	fw, err := fs.Create("entries.parquet")
+	assert.NoError(t, err)
+	pw, err := NewParquetWriter(fw, new(Entry), 1)
+	assert.NoError(t, err)
+	for _, rowGroup := range [][]Entry{entries[:300], entries[300:500], entries[500:]} {
+		for _, entry := range rowGroup {
+			assert.NoError(t, pw.Write(entry))
+		}
+		assert.NoError(t, pw.Flush(true))
+	}
+	assert.NoError(t, pw.WriteStop())
+	assert.NoError(t, fw.Close())
+	fr, err := fs.Open("entries.parquet")
+	assert.NoError(t, err)
+
+	//split the file and return the number of rows of the row groups of the files
+	split := func(opts SplitOptions) [][]int64 {
+		numFiles, err := SplitFile(fr, func(index int) (source.ParquetFile, error) {
+			return fs.Create(fmt.Sprintf("%v.parquet", index))
+		}, opts)
+		assert.NoError(t, err)
+
+		var res []Entry
+		numRows := make([][]int64, numFiles)
+		for i := 0; i < numFiles; i++ {
+			fr, err := fs.Open(fmt.Sprintf("%v.parquet", i))
+			assert.NoError(t, err)
+			pr, err := reader.NewParquetReader(fr, new(Entry), 1)
+			assert.NoError(t, err)
+			for _, rowGroup := range pr.Footer.RowGroups {
+				numRows[i] = append(numRows[i], rowGroup.NumRows)
+			}
+			fileEntries := make([]Entry, pr.GetNumRows())
+			assert.NoError(t, pr.Read(&fileEntries))
+			res = append(res, fileEntries...)
+		}
+		assert.Equal(t, entries, res)
+		return numRows
+	}
+
+	assert.Equal(t, [][]int64{{300, 200}, {500}}, split(SplitOptions{NumFiles: 2}))
+	assert.Equal(t, [][]int64{{300}, {200}, {500}}, split(SplitOptions{NumFiles: 5}))
+	//the last row group is cut
+	assert.Equal(t, [][]int64{{300}, {200}, {400}, {100}}, split(SplitOptions{MaxRows: 400}))
+	numRows := split(SplitOptions{MaxSize: 1000})
+	assert.Greater(t, len(numRows), 3)
+
+	_, err = SplitFile(fr, nil, SplitOptions{})
+	assert.EqualError(t, err, "no split option is set")
+	_, err = SplitFile(fr, nil, SplitOptions{NumFiles: 2, MaxRows: 100})
+	assert.Error(t, err)
+	_, err = SplitFile(fr, nil, SplitOptions{NumFiles: 2, MaxSize: 1 << 20})
+	assert.Error(t, err)
+}
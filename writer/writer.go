@@ -1,16 +1,23 @@
 package writer
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"math"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/bloomfilter"
 	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/compress"
+	"github.com/xitongsys/parquet-go/encryption"
 	"github.com/xitongsys/parquet-go/layout"
 	"github.com/xitongsys/parquet-go/marshal"
 	"github.com/xitongsys/parquet-go/parquet"
@@ -18,6 +25,34 @@ import (
 	"github.com/xitongsys/parquet-go/source"
 )
 
+const (
+	//Default limits of the dictionaries of the columns with a dictionary encoding
+	DefaultMaxDictionarySize    = 1 << 20
+	DefaultMaxDictionaryEntries = math.MaxInt32
+)
+
+// ParquetWriterOptions are the options of NewParquetWriter
+type ParquetWriterOptions struct {
+	//Encrypt the file with the Parquet Modular Encryption
+	Encryption *encryption.FileEncryptionProperties
+}
+
+// ColumnCompression is the compression of a column
+type ColumnCompression struct {
+	Codec parquet.CompressionCodec
+	//Compression level of the codec, 0 is its default level, see compress.CheckLevel
+	Level int
+}
+
+// SortingColumn is a sort key of the rows of the row groups, see ParquetWriter.SortingColumns
+type SortingColumn struct {
+	//Path of a column which isn't repeated, without the root, like "address.city"
+	Path       string
+	Descending bool
+	//Null values are before the other values
+	NullsFirst bool
+}
+
 // ParquetWriter is a writer  parquet file
 type ParquetWriter struct {
 	SchemaHandler *schema.SchemaHandler
@@ -29,6 +64,25 @@ type ParquetWriter struct {
 	RowGroupSize    int64
 	CompressionType parquet.CompressionCodec
 	Offset          int64
+	//Version of the data pages: 1 (or 0) for DATA_PAGE, 2 for DATA_PAGE_V2 whose
+	//levels aren't compressed and which start on row boundaries
+	DataPageVersion int32
+	//Max size in bytes and max number of entries of the dictionary of a column chunk,
+	//the next pages of the chunk have the fallback encoding of the column when it's larger.
+	//They are overridden by the tags of the columns. 0 means the default limit and a
+	//negative value no limit.
+	MaxDictionarySize    int64
+	MaxDictionaryEntries int64
+	//Select the encodings of the columns with the PLAIN encoding (the default of the tags)
+	//from the values of their first page, see layout.SelectEncoding
+	AutoEncoding bool
+	//Compressions of columns by their paths without the root, like "address.city".
+	//They override the compression and compressionlevel tags of the columns, which
+	//override CompressionType.
+	ColumnCompressions map[string]ColumnCompression
+	//Sort keys of the rows of each row group, which are recorded in the SortingColumns of the
+	//row groups. The rows are buffered until their row group is full, they are marshalled together.
+	SortingColumns []SortingColumn
 
 	Objs              []interface{}
 	ObjsSize          int64
@@ -41,21 +95,53 @@ type ParquetWriter struct {
 
 	DictRecs map[string]*layout.DictRecType
 
+	//Hashes of the values of the columns with bloom filters in the current row group
+	BloomFilterHashes map[string]map[uint64]bool
+
 	ColumnIndexes []*parquet.ColumnIndex
 	OffsetIndexes []*parquet.OffsetIndex
 
 	MarshalFunc func(src []interface{}, sh *schema.SchemaHandler) (*map[string]*layout.Table, error)
 
 	stopped bool
+	//Number of the row groups of the file before the writer, see NewParquetAppender
+	firstRowGroup int
+
+	//Tags of the columns with the encodings selected by AutoEncoding
+	autoEncodingInfos map[string]*common.Tag
+
+	encryptor *encryption.FileEncryptor
+	//Encryptors of the column chunks of each row group, nil for plaintext chunks
+	columnEncryptors [][]*encryption.ColumnEncryptor
 }
 
-func NewParquetWriterFromWriter(w io.Writer, obj interface{}, np int64) (*ParquetWriter, error) {
+func NewParquetWriterFromWriter(w io.Writer, obj interface{}, np int64, opts ...ParquetWriterOptions) (*ParquetWriter, error) {
 	wf := writerfile.NewWriterFile(w)
-	return NewParquetWriter(wf, obj, np)
+	return NewParquetWriter(wf, obj, np, opts...)
 }
 
 // Create a parquet handler. Obj is a object with tags or JSON schema string.
-func NewParquetWriter(pFile source.ParquetFile, obj interface{}, np int64) (*ParquetWriter, error) {
+func NewParquetWriter(pFile source.ParquetFile, obj interface{}, np int64, opts ...ParquetWriterOptions) (*ParquetWriter, error) {
+	res, err := newParquetWriter(pFile, np, opts...)
+	if err != nil {
+		return nil, err
+	}
+	_, err = res.PFile.Write([]byte(res.magic()))
+	if err != nil {
+		return nil, err
+	}
+	if err = res.setSchema(obj); err != nil {
+		return res, err
+	}
+
+	// Enable writing after init completed successfully
+	res.stopped = false
+
+	return res, err
+}
+
+// Writer with the default settings, which is stopped until its schema is set
+func newParquetWriter(pFile source.ParquetFile, np int64, opts ...ParquetWriterOptions) (*ParquetWriter, error) {
 	var err error
 
 	res := new(ParquetWriter)
@@ -63,6 +149,7 @@ func NewParquetWriter(pFile source.ParquetFile, obj interface{}, np int64) (*Par
 	res.PageSize = 8 * 1024              //8K
 	res.RowGroupSize = 128 * 1024 * 1024 //128M
 	res.CompressionType = parquet.CompressionCodec_SNAPPY
+	res.DataPageVersion = 1
 	res.ObjsSize = 0
 	res.CheckSizeCritical = 0
 	res.Size = 0
@@ -71,6 +158,7 @@ func NewParquetWriter(pFile source.ParquetFile, obj interface{}, np int64) (*Par
 	res.PFile = pFile
 	res.PagesMapBuf = make(map[string][]*layout.Page)
 	res.DictRecs = make(map[string]*layout.DictRecType)
+	res.BloomFilterHashes = make(map[string]map[uint64]bool)
 	res.Footer = parquet.NewFileMetaData()
 	res.Footer.Version = 1
 	res.ColumnIndexes = make([]*parquet.ColumnIndex, 0)
@@ -79,38 +167,38 @@ func NewParquetWriter(pFile source.ParquetFile, obj interface{}, np int64) (*Par
 	//WARN  CorruptStatistics:118 - Ignoring statistics because created_by is null or empty! See PARQUET-251 and PARQUET-297
 	createdBy := "parquet-go version latest"
 	res.Footer.CreatedBy = &createdBy
-	_, err = res.PFile.Write([]byte("PAR1"))
-	if err != nil {
-		return nil, err
+	if len(opts) > 0 && opts[0].Encryption != nil {
+		if res.encryptor, err = encryption.NewFileEncryptor(opts[0].Encryption); err != nil {
+			return nil, err
+		}
 	}
 	res.MarshalFunc = marshal.Marshal
 	res.stopped = true
+	return res, nil
+}
 
+// Set the schema from a object with tags, a JSON schema string, a schema handler or schema elements
+func (pw *ParquetWriter) setSchema(obj interface{}) error {
+	var err error
 	if obj != nil {
 		if sa, ok := obj.(string); ok {
-			err = res.SetSchemaHandlerFromJSON(sa)
-			res.stopped = err != nil
-			return res, err
+			return pw.SetSchemaHandlerFromJSON(sa)
 
 		} else if sa, ok := obj.(*schema.SchemaHandler); ok {
-			res.SchemaHandler = schema.NewSchemaHandlerFromSchemaHandler(sa)
+			pw.SchemaHandler = schema.NewSchemaHandlerFromSchemaHandler(sa)
 
 		} else if sa, ok := obj.([]*parquet.SchemaElement); ok {
-			res.SchemaHandler = schema.NewSchemaHandlerFromSchemaList(sa)
+			pw.SchemaHandler = schema.NewSchemaHandlerFromSchemaList(sa)
 
 		} else {
-			if res.SchemaHandler, err = schema.NewSchemaHandlerFromStruct(obj); err != nil {
-				return res, err
+			if pw.SchemaHandler, err = schema.NewSchemaHandlerFromStruct(obj); err != nil {
+				return err
 			}
 		}
 
-		res.Footer.Schema = append(res.Footer.Schema, res.SchemaHandler.SchemaElements...)
+		pw.Footer.Schema = append(pw.Footer.Schema, pw.SchemaHandler.SchemaElements...)
 	}
-
-	// Enable writing after init completed successfully
-	res.stopped = false
-
-	return res, err
+	return nil
 }
 
 func (pw *ParquetWriter) SetSchemaHandlerFromJSON(jsonSchema string) error {
@@ -128,7 +216,7 @@ func (pw *ParquetWriter) RenameSchema() {
 	for i := 0; i < len(pw.Footer.Schema); i++ {
 		pw.Footer.Schema[i].Name = pw.SchemaHandler.Infos[i].ExName
 	}
-	for _, rowGroup := range pw.Footer.RowGroups {
+	for _, rowGroup := range pw.Footer.RowGroups[pw.firstRowGroup:] {
 		for _, chunk := range rowGroup.Columns {
 			inPathStr := common.PathToStr(chunk.MetaData.PathInSchema)
 			exPathStr := pw.SchemaHandler.InPathToExPath[inPathStr]
@@ -138,6 +226,14 @@ func (pw *ParquetWriter) RenameSchema() {
 	}
 }
 
+// Magic number of the file, "PARE" if the footer is encrypted
+func (pw *ParquetWriter) magic() string {
+	if pw.encryptor != nil && !pw.encryptor.PlaintextFooter() {
+		return "PARE"
+	}
+	return "PAR1"
+}
+
 // Write the footer and stop writing
 func (pw *ParquetWriter) WriteStop() error {
 	if pw.stopped {
@@ -156,12 +252,22 @@ func (pw *ParquetWriter) WriteStop() error {
 	// write ColumnIndex
 	if len(pw.ColumnIndexes) > 0 {
 		idx := 0
-		for _, rowGroup := range pw.Footer.RowGroups {
-			for _, columnChunk := range rowGroup.Columns {
+		for i, rowGroup := range pw.Footer.RowGroups[pw.firstRowGroup:] {
+			for j, columnChunk := range rowGroup.Columns {
+				//the chunks of merged files may have no index
+				if pw.ColumnIndexes[idx] == nil {
+					idx++
+					continue
+				}
 				columnIndexBuf, err := ts.Write(context.TODO(), pw.ColumnIndexes[idx])
 				if err != nil {
 					return err
 				}
+				if encryptor := pw.columnEncryptor(i, j); encryptor != nil {
+					if columnIndexBuf, err = encryptor.EncryptModule(encryption.ModuleColumnIndex, 0, columnIndexBuf); err != nil {
+						return err
+					}
+				}
 				if _, err = pw.PFile.Write(columnIndexBuf); err != nil {
 					return err
 				}
@@ -181,12 +287,22 @@ func (pw *ParquetWriter) WriteStop() error {
 	// write OffsetIndex
 	if len(pw.OffsetIndexes) > 0 {
 		idx := 0
-		for _, rowGroup := range pw.Footer.RowGroups {
-			for _, columnChunk := range rowGroup.Columns {
+		for i, rowGroup := range pw.Footer.RowGroups[pw.firstRowGroup:] {
+			for j, columnChunk := range rowGroup.Columns {
+				//the chunks of merged files may have no index
+				if pw.OffsetIndexes[idx] == nil {
+					idx++
+					continue
+				}
 				offsetIndexBuf, err := ts.Write(context.TODO(), pw.OffsetIndexes[idx])
 				if err != nil {
 					return err
 				}
+				if encryptor := pw.columnEncryptor(i, j); encryptor != nil {
+					if offsetIndexBuf, err = encryptor.EncryptModule(encryption.ModuleOffsetIndex, 0, offsetIndexBuf); err != nil {
+						return err
+					}
+				}
 				if _, err = pw.PFile.Write(offsetIndexBuf); err != nil {
 					return err
 				}
@@ -203,10 +319,25 @@ func (pw *ParquetWriter) WriteStop() error {
 		}
 	}
 
+	if pw.encryptor != nil {
+		if err = pw.encryptColumnMetaData(); err != nil {
+			return err
+		}
+		if pw.encryptor.PlaintextFooter() {
+			pw.Footer.EncryptionAlgorithm = pw.encryptor.Algorithm()
+			pw.Footer.FooterSigningKeyMetadata = pw.encryptor.FooterKeyMetadata()
+		}
+	}
+
 	footerBuf, err := ts.Write(context.TODO(), pw.Footer)
 	if err != nil {
 		return err
 	}
+	if pw.encryptor != nil {
+		if footerBuf, err = pw.encryptFooter(footerBuf); err != nil {
+			return err
+		}
+	}
 
 	if _, err = pw.PFile.Write(footerBuf); err != nil {
 		return err
@@ -217,13 +348,116 @@ func (pw *ParquetWriter) WriteStop() error {
 	if _, err = pw.PFile.Write(footerSizeBuf); err != nil {
 		return err
 	}
-	if _, err = pw.PFile.Write([]byte("PAR1")); err != nil {
+	if _, err = pw.PFile.Write([]byte(pw.magic())); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Encryptor of a column chunk of a written row group
+func (pw *ParquetWriter) columnEncryptor(rowGroupIndex, columnIndex int) *encryption.ColumnEncryptor {
+	if rowGroupIndex >= len(pw.columnEncryptors) || columnIndex >= len(pw.columnEncryptors[rowGroupIndex]) {
+		return nil
+	}
+	return pw.columnEncryptors[rowGroupIndex][columnIndex]
+}
+
+// Set the crypto metadata of the encrypted column chunks and encrypt their ColumnMetaData.
+// With an encrypted footer, the ColumnMetaData of the columns encrypted with the
+// footer key is only encrypted with the footer.
+func (pw *ParquetWriter) encryptColumnMetaData() error {
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	for i, rowGroup := range pw.Footer.RowGroups {
+		for j, columnChunk := range rowGroup.Columns {
+			encryptor := pw.columnEncryptor(i, j)
+			if encryptor == nil {
+				continue
+			}
+			columnChunk.CryptoMetadata = encryptor.CryptoMetaData
+			if !pw.encryptor.PlaintextFooter() && encryptor.WithFooterKey() {
+				continue
+			}
+
+			metaDataBuf, err := ts.Write(context.TODO(), columnChunk.MetaData)
+			if err != nil {
+				return err
+			}
+			if columnChunk.EncryptedColumnMetadata, err = encryptor.EncryptModule(encryption.ModuleColumnMetaData, 0, metaDataBuf); err != nil {
+				return err
+			}
+
+			if pw.encryptor.PlaintextFooter() {
+				//keep the metadata for the readers without the key, without the statistics
+				metaData := *columnChunk.MetaData
+				metaData.Statistics = nil
+				metaData.EncodingStats = nil
+				columnChunk.MetaData = &metaData
+			} else {
+				columnChunk.MetaData = nil
+			}
+		}
+	}
+	return nil
+}
+
+// Encrypt the footer, or sign it if it's written in plaintext
+func (pw *ParquetWriter) encryptFooter(footerBuf []byte) ([]byte, error) {
+	if pw.encryptor.PlaintextFooter() {
+		signature, err := pw.encryptor.SignFooter(footerBuf)
+		if err != nil {
+			return nil, err
+		}
+		return append(footerBuf, signature...), nil
+	}
+
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	cryptoMetaDataBuf, err := ts.Write(context.TODO(), pw.encryptor.FileCryptoMetaData())
+	if err != nil {
+		return nil, err
+	}
+	encryptedFooter, err := pw.encryptor.EncryptFooter(footerBuf)
+	if err != nil {
+		return nil, err
+	}
+	return append(cryptoMetaDataBuf, encryptedFooter...), nil
+}
+
+// Encrypt the header and the data of a page. CompressedPageSize of the header
+// is set to the size of the encrypted data.
+func encryptPage(encryptor *encryption.ColumnEncryptor, page *layout.Page, pageOrdinal int) ([]byte, error) {
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	headerBuf, err := ts.Write(context.TODO(), page.Header)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(page.RawData, headerBuf) {
+		return nil, errors.New("page data doesn't start with its header")
+	}
+
+	pageModule, headerModule := encryption.ModuleDataPage, encryption.ModuleDataPageHeader
+	if page.Header.Type == parquet.PageType_DICTIONARY_PAGE {
+		pageModule, headerModule = encryption.ModuleDictionaryPage, encryption.ModuleDictionaryPageHeader
+	}
+	data, err := encryptor.EncryptModule(pageModule, pageOrdinal, page.RawData[len(headerBuf):])
+	if err != nil {
+		return nil, err
+	}
+
+	page.Header.CompressedPageSize = int32(len(data))
+	if headerBuf, err = ts.Write(context.TODO(), page.Header); err != nil {
+		return nil, err
+	}
+	header, err := encryptor.EncryptModule(headerModule, pageOrdinal, headerBuf)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, data...), nil
+}
+
 // Write one object to parquet file
 func (pw *ParquetWriter) Write(src interface{}) error {
 	if pw.stopped {
@@ -264,6 +498,16 @@ func (pw *ParquetWriter) flushObjs() error {
 	if l <= 0 {
 		return nil
 	}
+	if pw.DataPageVersion < 0 || pw.DataPageVersion > 2 {
+		return fmt.Errorf("unsupported data page version %v", pw.DataPageVersion)
+	}
+	if err = pw.checkColumnCompressions(); err != nil {
+		return err
+	}
+	sortKeys, err := pw.sortKeys()
+	if err != nil {
+		return err
+	}
 	pagesMapList := make([]map[string][]*layout.Page, pw.NP)
 	for i := 0; i < int(pw.NP); i++ {
 		pagesMapList[i] = make(map[string][]*layout.Page)
@@ -271,6 +515,10 @@ func (pw *ParquetWriter) flushObjs() error {
 
 	var c int64 = 0
 	delta := (l + pw.NP - 1) / pw.NP
+	if len(sortKeys) > 0 {
+		//the rows are sorted in a single table
+		delta = l
+	}
 	lock := new(sync.Mutex)
 	var wg sync.WaitGroup
 	var errs []error = make([]error, pw.NP)
@@ -306,27 +554,15 @@ func (pw *ParquetWriter) flushObjs() error {
 			}
 
 			tableMap, err2 := pw.MarshalFunc(pw.Objs[b:e], pw.SchemaHandler)
+			if err2 == nil {
+				err2 = layout.SortTables(*tableMap, sortKeys)
+			}
 
 			if err2 == nil {
 				for name, table := range *tableMap {
-					if table.Info.Encoding == parquet.Encoding_PLAIN_DICTIONARY ||
-						table.Info.Encoding == parquet.Encoding_RLE_DICTIONARY {
-
-						func() {
-							if pw.NP > 1 {
-								lock.Lock()
-								defer lock.Unlock()
-							}
-							if _, ok := pw.DictRecs[name]; !ok {
-								pw.DictRecs[name] = layout.NewDictRec(*table.Schema.Type)
-							}
-							pagesMapList[index][name], _ = layout.TableToDictDataPages(pw.DictRecs[name],
-								table, int32(pw.PageSize), 32, pw.CompressionType)
-						}()
-
-					} else {
-						pagesMapList[index][name], _ = layout.TableToDataPages(table, int32(pw.PageSize),
-							pw.CompressionType)
+					if pagesMapList[index][name], err2 = pw.tableToPages(name, table, lock); err2 != nil {
+						errs[index] = err2
+						return
 					}
 				}
 			} else {
@@ -363,10 +599,93 @@ func (pw *ParquetWriter) flushObjs() error {
 	return err
 }
 
+// Encode the table of a column to pages. The dictionaries and bloom filters of the
+// columns are shared with the other goroutines of flushObjs under lock.
+func (pw *ParquetWriter) tableToPages(name string, table *layout.Table, lock *sync.Mutex) ([]*layout.Page, error) {
+	tableToDataPages, tableToDictDataPages := layout.TableToDataPages, layout.TableToDictDataPages
+	if pw.DataPageVersion == 2 {
+		tableToDataPages, tableToDictDataPages = layout.TableToDataPagesV2, layout.TableToDictDataPagesV2
+	}
+
+	var compressType parquet.CompressionCodec
+	var err error
+	if compressType, table.Info, err = pw.columnCompression(name, table.Info); err != nil {
+		return nil, err
+	}
+
+	if pw.AutoEncoding && table.Info.Encoding == parquet.Encoding_PLAIN {
+		func() {
+			if pw.NP > 1 {
+				lock.Lock()
+				defer lock.Unlock()
+			}
+			table.Info = pw.autoEncodingInfo(name, table, compressType)
+		}()
+	}
+
+	if table.Info.BloomFilter {
+		hashes := make(map[uint64]bool)
+		for _, v := range table.Values {
+			if v != nil {
+				hashes[bloomfilter.Hash(v)] = true
+			}
+		}
+		if table.Vector != nil {
+			for i := 0; i < table.Vector.Len(); i++ {
+				hashes[bloomfilter.Hash(table.Vector.Value(i))] = true
+			}
+		}
+
+		func() {
+			if pw.NP > 1 {
+				lock.Lock()
+				defer lock.Unlock()
+			}
+			if _, ok := pw.BloomFilterHashes[name]; !ok {
+				pw.BloomFilterHashes[name] = hashes
+				return
+			}
+			for hash := range hashes {
+				pw.BloomFilterHashes[name][hash] = true
+			}
+		}()
+	}
+
+	var pages []*layout.Page
+	if table.Info.Encoding == parquet.Encoding_PLAIN_DICTIONARY ||
+		table.Info.Encoding == parquet.Encoding_RLE_DICTIONARY {
+
+		func() {
+			if pw.NP > 1 {
+				lock.Lock()
+				defer lock.Unlock()
+			}
+			if _, ok := pw.DictRecs[name]; !ok {
+				dictRec := layout.NewDictRec(*table.Schema.Type)
+				dictRec.MaxSize = dictionaryLimit(table.Info.MaxDictionarySize, pw.MaxDictionarySize, DefaultMaxDictionarySize)
+				dictRec.MaxEntries = dictionaryLimit(table.Info.MaxDictionaryEntries, pw.MaxDictionaryEntries, DefaultMaxDictionaryEntries)
+				dictRec.CompressionLevel = table.Info.CompressionLevel
+				pw.DictRecs[name] = dictRec
+			}
+			pages, _, err = tableToDictDataPages(pw.DictRecs[name],
+				table, int32(pw.PageSize), 32, compressType)
+		}()
+
+	} else {
+		pages, _, err = tableToDataPages(table, int32(pw.PageSize),
+			compressType)
+	}
+	return pages, err
+}
+
 // Flush the write buffer to parquet file
 func (pw *ParquetWriter) Flush(flag bool) error {
 	var err error
 
+	//the rows of a row group are sorted together
+	if len(pw.SortingColumns) > 0 && !flag && pw.ObjsSize < pw.RowGroupSize {
+		return nil
+	}
 	if err = pw.flushObjs(); err != nil {
 		return err
 	}
@@ -375,8 +694,12 @@ func (pw *ParquetWriter) Flush(flag bool) error {
 		//pages -> chunk
 		chunkMap := make(map[string]*layout.Chunk)
 		for name, pages := range pw.PagesMapBuf {
-			if len(pages) > 0 && (pages[0].Info.Encoding == parquet.Encoding_PLAIN_DICTIONARY || pages[0].Info.Encoding == parquet.Encoding_RLE_DICTIONARY) {
-				dictPage, _ := layout.DictRecToDictPage(pw.DictRecs[name], int32(pw.PageSize), pw.CompressionType)
+			//the pages may have the fallback encoding after the dict pages
+			if dictRec, ok := pw.DictRecs[name]; ok && len(pages) > 0 {
+				dictPage, _, err := layout.DictRecToDictPage(dictRec, int32(pw.PageSize), pages[0].CompressType)
+				if err != nil {
+					return fmt.Errorf("failed to compress dict page: %v", err)
+				}
 				tmp := append([]*layout.Page{dictPage}, pages...)
 				chunkMap[name] = layout.PagesToDictChunk(tmp)
 			} else {
@@ -390,6 +713,7 @@ func (pw *ParquetWriter) Flush(flag bool) error {
 		//chunks -> rowGroup
 		rowGroup := layout.NewRowGroup()
 		rowGroup.RowGroupHeader.Columns = make([]*parquet.ColumnChunk, 0)
+		chunkNames := make([]string, 0)
 
 		for k := 0; k < len(pw.SchemaHandler.SchemaElements); k++ {
 			//for _, chunk := range chunkMap {
@@ -397,30 +721,58 @@ func (pw *ParquetWriter) Flush(flag bool) error {
 			if schema.GetNumChildren() > 0 {
 				continue
 			}
-			chunk := chunkMap[pw.SchemaHandler.IndexMap[int32(k)]]
+			name := pw.SchemaHandler.IndexMap[int32(k)]
+			chunk := chunkMap[name]
 			if chunk == nil {
 				continue
 			}
 			rowGroup.Chunks = append(rowGroup.Chunks, chunk)
+			chunkNames = append(chunkNames, name)
 			//rowGroup.RowGroupHeader.TotalByteSize += chunk.ChunkHeader.MetaData.TotalCompressedSize
 			rowGroup.RowGroupHeader.TotalByteSize += chunk.ChunkHeader.MetaData.TotalUncompressedSize
 			rowGroup.RowGroupHeader.Columns = append(rowGroup.RowGroupHeader.Columns, chunk.ChunkHeader)
 		}
 		rowGroup.RowGroupHeader.NumRows = pw.NumRows
 		pw.NumRows = 0
+		rowGroup.RowGroupHeader.SortingColumns = pw.rowGroupSortingColumns(chunkNames)
+
+		columnEncryptors := make([]*encryption.ColumnEncryptor, len(rowGroup.Chunks))
+		if pw.encryptor != nil {
+			paths := make([][]string, len(chunkNames))
+			for k, name := range chunkNames {
+				paths[k] = common.StrToPath(pw.SchemaHandler.InPathToExPath[name])[1:]
+			}
+			if err = pw.encryptor.CheckColumns(paths); err != nil {
+				return err
+			}
+			rowGroupOrdinal := len(pw.Footer.RowGroups)
+			for k := range rowGroup.Chunks {
+				if columnEncryptors[k], err = pw.encryptor.ColumnEncryptor(paths[k], rowGroupOrdinal, k); err != nil {
+					return err
+				}
+			}
+			ordinal := int16(rowGroupOrdinal)
+			rowGroup.RowGroupHeader.Ordinal = &ordinal
+		}
 
 		for k := 0; k < len(rowGroup.Chunks); k++ {
 			rowGroup.Chunks[k].ChunkHeader.MetaData.DataPageOffset = -1
 			rowGroup.Chunks[k].ChunkHeader.FileOffset = pw.Offset
 
 			pageCount := len(rowGroup.Chunks[k].Pages)
+			dataPageCount := 0
+			for l := 0; l < pageCount; l++ {
+				if rowGroup.Chunks[k].Pages[l].Header.Type != parquet.PageType_DICTIONARY_PAGE {
+					dataPageCount++
+				}
+			}
 
-			//add ColumnIndex
+			//add ColumnIndex, which only has entries for data pages
 			columnIndex := parquet.NewColumnIndex()
-			columnIndex.NullPages = make([]bool, pageCount)
-			columnIndex.MinValues = make([][]byte, pageCount)
-			columnIndex.MaxValues = make([][]byte, pageCount)
-			columnIndex.BoundaryOrder = parquet.BoundaryOrder_UNORDERED
+			columnIndex.NullPages = make([]bool, dataPageCount)
+			columnIndex.MinValues = make([][]byte, dataPageCount)
+			columnIndex.MaxValues = make([][]byte, dataPageCount)
+			columnIndex.BoundaryOrder = boundaryOrder(rowGroup.Chunks[k].Pages)
 			pw.ColumnIndexes = append(pw.ColumnIndexes, columnIndex)
 
 			//add OffsetIndex
@@ -429,6 +781,7 @@ func (pw *ParquetWriter) Flush(flag bool) error {
 			pw.OffsetIndexes = append(pw.OffsetIndexes, offsetIndex)
 
 			firstRowIndex := int64(0)
+			dataPageIndex := 0
 
 			for l := 0; l < pageCount; l++ {
 				if rowGroup.Chunks[k].Pages[l].Header.Type == parquet.PageType_DICTIONARY_PAGE {
@@ -440,10 +793,18 @@ func (pw *ParquetWriter) Flush(flag bool) error {
 				}
 
 				page := rowGroup.Chunks[k].Pages[l]
+				data := page.RawData
+				if columnEncryptors[k] != nil {
+					if data, err = encryptPage(columnEncryptors[k], page, dataPageIndex); err != nil {
+						return err
+					}
+					rowGroup.Chunks[k].ChunkHeader.MetaData.TotalCompressedSize += int64(len(data) - len(page.RawData))
+				}
+
 				//only record DataPage
 				if page.Header.Type != parquet.PageType_DICTIONARY_PAGE {
 					if page.Header.DataPageHeader == nil && page.Header.DataPageHeaderV2 == nil {
-						panic(errors.New("unsupported data page: " + page.Header.String()))
+						return errors.New("unsupported data page: " + page.Header.String())
 					}
 
 					var minVal []byte
@@ -460,15 +821,18 @@ func (pw *ParquetWriter) Flush(flag bool) error {
 						nullCount = page.Header.DataPageHeaderV2.Statistics.NullCount
 					}
 
-					columnIndex.MinValues[l] = minVal
-					columnIndex.MaxValues[l] = maxVal
+					columnIndex.MinValues[dataPageIndex] = minVal
+					columnIndex.MaxValues[dataPageIndex] = maxVal
 					// Statistics.NullCount is nil when statistics are omitted for the column otherwise for all column page headers it will be populated.
 					if nullCount != nil {
 						if columnIndex.NullCounts == nil {
-							columnIndex.NullCounts = make([]int64, pageCount)
+							columnIndex.NullCounts = make([]int64, dataPageCount)
 						}
-						columnIndex.NullCounts[l] = *nullCount
+						columnIndex.NullCounts[dataPageIndex] = *nullCount
+						// a page with statistics but without min/max only has null values
+						columnIndex.NullPages[dataPageIndex] = minVal == nil && maxVal == nil
 					}
+					dataPageIndex++
 
 					pageLocation := parquet.NewPageLocation()
 					pageLocation.Offset = pw.Offset
@@ -477,10 +841,13 @@ func (pw *ParquetWriter) Flush(flag bool) error {
 
 					offsetIndex.PageLocations = append(offsetIndex.PageLocations, pageLocation)
 
-					firstRowIndex += int64(page.Header.DataPageHeader.NumValues)
+					if page.Header.DataPageHeaderV2 != nil {
+						firstRowIndex += int64(page.Header.DataPageHeaderV2.NumRows)
+					} else {
+						firstRowIndex += int64(page.Header.DataPageHeader.NumValues)
+					}
 				}
 
-				data := rowGroup.Chunks[k].Pages[l].RawData
 				if _, err = pw.PFile.Write(data); err != nil {
 					return err
 				}
@@ -488,6 +855,32 @@ func (pw *ParquetWriter) Flush(flag bool) error {
 			}
 		}
 
+		//write the bloom filters after the chunks of the row group
+		for k, name := range chunkNames {
+			hashes, ok := pw.BloomFilterHashes[name]
+			if !ok {
+				continue
+			}
+			filter := bloomfilter.New(bloomfilter.OptimalNumBytes(int64(len(hashes)), rowGroup.Chunks[k].Pages[0].Info.BloomFilterFPP))
+			for hash := range hashes {
+				filter.Insert(hash)
+			}
+			data, err := pw.marshalBloomFilter(filter, columnEncryptors[k])
+			if err != nil {
+				return err
+			}
+			if _, err = pw.PFile.Write(data); err != nil {
+				return err
+			}
+			offset := pw.Offset
+			rowGroup.Chunks[k].ChunkHeader.MetaData.BloomFilterOffset = &offset
+			pw.Offset += int64(len(data))
+		}
+		pw.BloomFilterHashes = make(map[string]map[uint64]bool)
+
+		if pw.encryptor != nil {
+			pw.columnEncryptors = append(pw.columnEncryptors, columnEncryptors)
+		}
 		pw.Footer.RowGroups = append(pw.Footer.RowGroups, rowGroup.RowGroupHeader)
 		pw.Size = 0
 		pw.PagesMapBuf = make(map[string][]*layout.Page)
@@ -498,3 +891,161 @@ func (pw *ParquetWriter) Flush(flag bool) error {
 	return nil
 
 }
+
+// Tag of a column with the encoding selected from its first table
+func (pw *ParquetWriter) autoEncodingInfo(name string, table *layout.Table, compressType parquet.CompressionCodec) *common.Tag {
+	if pw.autoEncodingInfos == nil {
+		pw.autoEncodingInfos = make(map[string]*common.Tag)
+	}
+	if info, ok := pw.autoEncodingInfos[name]; ok {
+		return info
+	}
+	info := *table.Info
+	info.Encoding = layout.SelectEncoding(table, int32(pw.PageSize), compressType)
+	pw.autoEncodingInfos[name] = &info
+	return &info
+}
+
+// Codec of a column and its tag with the compression level, from ColumnCompressions, the tag or CompressionType
+func (pw *ParquetWriter) columnCompression(name string, info *common.Tag) (parquet.CompressionCodec, *common.Tag, error) {
+	codec, level := pw.CompressionType, info.CompressionLevel
+	if info.Compression != nil {
+		codec = *info.Compression
+	}
+	if columnCompression, ok := pw.ColumnCompressions[pw.columnPath(name)]; ok {
+		codec, level = columnCompression.Codec, columnCompression.Level
+	}
+	if err := compress.CheckLevel(codec, level); err != nil {
+		return codec, nil, fmt.Errorf("column %v: %v", pw.columnPath(name), err)
+	}
+	if level != info.CompressionLevel {
+		levelInfo := *info
+		levelInfo.CompressionLevel = level
+		info = &levelInfo
+	}
+	return codec, info, nil
+}
+
+// Check that the paths of ColumnCompressions are columns of the schema
+func (pw *ParquetWriter) checkColumnCompressions() error {
+	if len(pw.ColumnCompressions) == 0 {
+		return nil
+	}
+	paths := make(map[string]bool, len(pw.SchemaHandler.ValueColumns))
+	for _, name := range pw.SchemaHandler.ValueColumns {
+		paths[pw.columnPath(name)] = true
+	}
+	for path := range pw.ColumnCompressions {
+		if !paths[path] {
+			return fmt.Errorf("unknown column %v in ColumnCompressions", path)
+		}
+	}
+	return nil
+}
+
+// Path of a column without the root, as the keys of ColumnCompressions
+func (pw *ParquetWriter) columnPath(name string) string {
+	return strings.Join(common.StrToPath(pw.SchemaHandler.InPathToExPath[name])[1:], ".")
+}
+
+// Sort keys of SortingColumns, by the paths of the columns in the schema handler
+func (pw *ParquetWriter) sortKeys() ([]layout.SortKey, error) {
+	if len(pw.SortingColumns) == 0 {
+		return nil, nil
+	}
+	names := make(map[string]string, len(pw.SchemaHandler.ValueColumns))
+	for _, name := range pw.SchemaHandler.ValueColumns {
+		names[pw.columnPath(name)] = name
+	}
+	keys := make([]layout.SortKey, len(pw.SortingColumns))
+	for i, column := range pw.SortingColumns {
+		name, ok := names[column.Path]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %v in SortingColumns", column.Path)
+		}
+		if maxRL, _ := pw.SchemaHandler.MaxRepetitionLevel(common.StrToPath(name)); maxRL > 0 {
+			return nil, fmt.Errorf("repeated column %v in SortingColumns", column.Path)
+		}
+		keys[i] = layout.SortKey{Path: name, Descending: column.Descending, NullsFirst: column.NullsFirst}
+	}
+	return keys, nil
+}
+
+// SortingColumns of a row group with the columns of its chunks
+func (pw *ParquetWriter) rowGroupSortingColumns(chunkNames []string) []*parquet.SortingColumn {
+	var res []*parquet.SortingColumn
+	for _, column := range pw.SortingColumns {
+		for i, name := range chunkNames {
+			if pw.columnPath(name) == column.Path {
+				res = append(res, &parquet.SortingColumn{ColumnIdx: int32(i), Descending: column.Descending, NullsFirst: column.NullsFirst})
+				break
+			}
+		}
+	}
+	return res
+}
+
+// Boundary order of the ColumnIndex of the pages of a chunk, from the min/max values of its data pages
+func boundaryOrder(pages []*layout.Page) parquet.BoundaryOrder {
+	ascending, descending := true, true
+	var prev *layout.Page
+	var funcTable common.FuncTable
+	for _, page := range pages {
+		//the null pages are ignored
+		if page.Header.Type == parquet.PageType_DICTIONARY_PAGE || page.MinVal == nil || page.MaxVal == nil {
+			continue
+		}
+		if prev == nil {
+			funcTable = common.FindFuncTable(page.Schema.Type, page.Schema.ConvertedType, page.Schema.LogicalType)
+		} else {
+			if funcTable.LessThan(page.MinVal, prev.MinVal) || funcTable.LessThan(page.MaxVal, prev.MaxVal) {
+				ascending = false
+			}
+			if funcTable.LessThan(prev.MinVal, page.MinVal) || funcTable.LessThan(prev.MaxVal, page.MaxVal) {
+				descending = false
+			}
+		}
+		prev = page
+	}
+	if prev == nil {
+		return parquet.BoundaryOrder_UNORDERED
+	} else if ascending {
+		return parquet.BoundaryOrder_ASCENDING
+	} else if descending {
+		return parquet.BoundaryOrder_DESCENDING
+	}
+	return parquet.BoundaryOrder_UNORDERED
+}
+
+// Limit of the dictionary of a column, from its tag or the writer
+func dictionaryLimit(columnLimit, writerLimit, defaultLimit int64) int64 {
+	limit := columnLimit
+	if limit == 0 {
+		limit = writerLimit
+	}
+	if limit == 0 {
+		return defaultLimit
+	} else if limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+// Marshal a bloom filter, its header and bitset are separate modules when the column is encrypted
+func (pw *ParquetWriter) marshalBloomFilter(filter *bloomfilter.SplitBlockFilter, encryptor *encryption.ColumnEncryptor) ([]byte, error) {
+	if encryptor == nil {
+		return filter.Marshal()
+	}
+	headerBuf, err := filter.MarshalHeader()
+	if err != nil {
+		return nil, err
+	}
+	if headerBuf, err = encryptor.EncryptModule(encryption.ModuleBloomFilterHeader, 0, headerBuf); err != nil {
+		return nil, err
+	}
+	bitset, err := encryptor.EncryptModule(encryption.ModuleBloomFilterBitset, 0, filter.MarshalBitset())
+	if err != nil {
+		return nil, err
+	}
+	return append(headerBuf, bitset...), nil
+}
@@ -2,15 +2,18 @@ package writer
 
 import (
 	"bytes"
-	"context"
 	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/stretchr/testify/assert"
 	"github.com/xitongsys/parquet-go-source/buffer"
 	"github.com/xitongsys/parquet-go-source/writerfile"
+	"github.com/xitongsys/parquet-go/compress"
+	"github.com/xitongsys/parquet-go/layout"
 	"github.com/xitongsys/parquet-go/parquet"
 	"github.com/xitongsys/parquet-go/reader"
 	"github.com/xitongsys/parquet-go/source"
@@ -68,7 +71,7 @@ func TestNullCountsFromColumnIndex(t *testing.T) {
 		{false, nil},
 	}
 	for i, chunk := range chunks {
-		colIdx, err := readColumnIndex(pr.PFile, *chunk.ColumnIndexOffset)
+		colIdx, err := reader.ReadColumnIndex(pr.PFile, chunk)
 		assert.NoError(t, err)
 		assert.Equal(t, expects[i].IsSetNullCounts, colIdx.IsSetNullCounts())
 		assert.Equal(t, expects[i].NullCounts, colIdx.GetNullCounts())
@@ -114,29 +117,17 @@ func TestAllNullCountsFromColumnIndex(t *testing.T) {
 	columns := pr.Footer.RowGroups[0].GetColumns()
 	assert.Equal(t, 2, len(columns))
 
-	colIdx, err := readColumnIndex(pr.PFile, *columns[0].ColumnIndexOffset)
+	colIdx, err := reader.ReadColumnIndex(pr.PFile, columns[0])
 	assert.NoError(t, err)
 	assert.Equal(t, true, colIdx.IsSetNullCounts())
 	assert.Equal(t, []int64{0}, colIdx.GetNullCounts())
 
-	colIdx, err = readColumnIndex(pr.PFile, *columns[1].ColumnIndexOffset)
+	colIdx, err = reader.ReadColumnIndex(pr.PFile, columns[1])
 	assert.NoError(t, err)
 	assert.Equal(t, true, colIdx.IsSetNullCounts())
 	assert.Equal(t, []int64{6}, colIdx.GetNullCounts())
 }
 
-func readColumnIndex(pf source.ParquetFile, offset int64) (*parquet.ColumnIndex, error) {
-	colIdx := parquet.NewColumnIndex()
-	tpf := thrift.NewTCompactProtocolFactoryConf(nil)
-	triftReader := source.ConvertToThriftReader(pf, offset)
-	protocol := tpf.GetProtocol(triftReader)
-	err := colIdx.Read(context.Background(), protocol)
-	if err != nil {
-		return nil, err
-	}
-	return colIdx, nil
-}
-
 func val(x int64) *int64 {
 	y := x
 	return &y
@@ -237,3 +228,457 @@ func TestNewWriterWithInvaidFile(t *testing.T) {
 	assert.Nil(t, pw)
 	assert.ErrorIs(t, err, testWriteErr)
 }
+
+func TestDataPageV2(t *testing.T) {
+	type Entry struct {
+		ID    int64    `parquet:"name=id, type=INT64"`
+		Name  *string  `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		Tags  []int32  `parquet:"name=tags, type=INT32, repetitiontype=REPEATED"`
+		Score *float64 `parquet:"name=score, type=DOUBLE"`
+	}
+
+	entries := make([]Entry, 1000)
+	numNulls := 0
+	for i := range entries {
+		entries[i].ID = int64(i)
+		if i%4 != 0 {
+			name := fmt.Sprintf("name%v", i%10)
+			entries[i].Name = &name
+		} else {
+			numNulls++
+		}
+		for j := 0; j < i%3; j++ {
+			entries[i].Tags = append(entries[i].Tags, int32(i*j))
+		}
+		if i%5 != 0 {
+			score := float64(i) / 2
+			entries[i].Score = &score
+		}
+	}
+
+	for _, codec := range []parquet.CompressionCodec{parquet.CompressionCodec_UNCOMPRESSED, parquet.CompressionCodec_SNAPPY, parquet.CompressionCodec_GZIP} {
+		t.Run(codec.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			pw, err := NewParquetWriterFromWriter(&buf, new(Entry), 2)
+			assert.NoError(t, err)
+			pw.CompressionType = codec
+			pw.DataPageVersion = 2
+			pw.PageSize = 256
+			for _, entry := range entries {
+				assert.NoError(t, pw.Write(entry))
+			}
+			assert.NoError(t, pw.WriteStop())
+
+			pf, err := buffer.NewBufferFile(buf.Bytes())
+			assert.NoError(t, err)
+			pr, err := reader.NewParquetReader(pf, new(Entry), 1)
+			assert.NoError(t, err)
+			res := make([]Entry, len(entries))
+			assert.NoError(t, pr.Read(&res))
+			assert.Equal(t, entries, res)
+
+			//the skipped pages are read without their values
+			pr, err = reader.NewParquetReader(pf, new(Entry), 1)
+			assert.NoError(t, err)
+			assert.NoError(t, pr.SkipRows(500))
+			res = make([]Entry, 10)
+			assert.NoError(t, pr.Read(&res))
+			assert.Equal(t, entries[500:510], res)
+
+			pr, err = reader.NewParquetReader(pf, nil, 1)
+			assert.NoError(t, err)
+			for _, chunk := range pr.Footer.RowGroups[0].Columns {
+				offsetIndex, err := reader.ReadOffsetIndex(pf, chunk)
+				assert.NoError(t, err)
+				assert.Greater(t, len(offsetIndex.PageLocations), 1)
+
+				firstRowIndex, chunkNulls := int64(0), int32(0)
+				for _, location := range offsetIndex.PageLocations {
+					assert.Equal(t, firstRowIndex, location.FirstRowIndex)
+					thriftReader := thrift.NewTBufferedTransport(thrift.NewStreamTransportR(bytes.NewReader(buf.Bytes()[location.Offset:])), 1024)
+					page, _, numRows, err := layout.ReadPage(thriftReader, pr.SchemaHandler, chunk.MetaData)
+					assert.NoError(t, err)
+
+					header := page.Header.DataPageHeaderV2
+					if assert.NotNil(t, header) {
+						assert.Equal(t, parquet.PageType_DATA_PAGE_V2, page.Header.Type)
+						assert.Equal(t, int64(header.NumRows), numRows)
+						assert.Equal(t, int32(0), page.DataTable.RepetitionLevels[0])
+						if chunk.MetaData.PathInSchema[0] == "name" {
+							assert.Equal(t, parquet.Encoding_RLE_DICTIONARY, header.Encoding)
+						}
+						firstRowIndex += int64(header.NumRows)
+						chunkNulls += header.NumNulls
+					}
+				}
+				assert.Equal(t, int64(len(entries)), firstRowIndex)
+				if chunk.MetaData.PathInSchema[0] == "name" {
+					assert.Equal(t, int32(numNulls), chunkNulls)
+				}
+			}
+		})
+	}
+}
+
+func TestDictionaryFallback(t *testing.T) {
+	type Entry struct {
+		Name  string `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+		ID    int64  `parquet:"name=id, type=INT64, encoding=PLAIN_DICTIONARY, maxdictionaryentries=100, fallbackencoding=DELTA_BINARY_PACKED"`
+		Key   string `parquet:"name=key, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY, maxdictionarysize=-1"`
+		Small int32  `parquet:"name=small, type=INT32, encoding=PLAIN_DICTIONARY"`
+	}
+
+	entries := make([]Entry, 2000)
+	for i := range entries {
+		entries[i] = Entry{
+			Name:  fmt.Sprintf("name%v", i),
+			ID:    int64(i),
+			Key:   fmt.Sprintf("key%v", i),
+			Small: int32(i % 10),
+		}
+	}
+
+	for _, version := range []int32{1, 2} {
+		t.Run(fmt.Sprintf("v%v", version), func(t *testing.T) {
+			var buf bytes.Buffer
+			pw, err := NewParquetWriterFromWriter(&buf, new(Entry), 1)
+			assert.NoError(t, err)
+			pw.DataPageVersion = version
+			pw.PageSize = 256
+			pw.MaxDictionarySize = 1024
+			for _, entry := range entries {
+				assert.NoError(t, pw.Write(entry))
+			}
+			assert.NoError(t, pw.WriteStop())
+
+			pf, err := buffer.NewBufferFile(buf.Bytes())
+			assert.NoError(t, err)
+			pr, err := reader.NewParquetReader(pf, new(Entry), 1)
+			assert.NoError(t, err)
+			res := make([]Entry, len(entries))
+			assert.NoError(t, pr.Read(&res))
+			assert.Equal(t, entries, res)
+
+			dataPageType, dictEncoding := parquet.PageType_DATA_PAGE, parquet.Encoding_PLAIN_DICTIONARY
+			if version == 2 {
+				dataPageType, dictEncoding = parquet.PageType_DATA_PAGE_V2, parquet.Encoding_RLE_DICTIONARY
+			}
+			pageCounts := func(metaData *parquet.ColumnMetaData) map[parquet.Encoding]int32 {
+				res := make(map[parquet.Encoding]int32)
+				for _, stats := range metaData.EncodingStats {
+					if stats.PageType == parquet.PageType_DICTIONARY_PAGE {
+						assert.Equal(t, parquet.Encoding_PLAIN, stats.Encoding)
+						assert.Equal(t, int32(1), stats.Count)
+					} else {
+						assert.Equal(t, dataPageType, stats.PageType)
+						res[stats.Encoding] += stats.Count
+					}
+				}
+				return res
+			}
+
+			columns := pr.Footer.RowGroups[0].Columns
+			for _, fallback := range []struct {
+				column   int
+				encoding parquet.Encoding
+			}{{0, parquet.Encoding_PLAIN}, {1, parquet.Encoding_DELTA_BINARY_PACKED}} {
+				metaData := columns[fallback.column].MetaData
+				counts := pageCounts(metaData)
+				assert.Len(t, counts, 2)
+				assert.Greater(t, counts[dictEncoding], int32(0))
+				assert.Greater(t, counts[fallback.encoding], int32(0))
+				encodings := []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN, dictEncoding}
+				if fallback.encoding != parquet.Encoding_PLAIN {
+					encodings = append(encodings, fallback.encoding)
+				}
+				assert.ElementsMatch(t, encodings, metaData.Encodings)
+				//the dictionary exceeds its limits by less than a page
+				assert.Less(t, metaData.DataPageOffset-metaData.GetDictionaryPageOffset(), int64(1024+512))
+			}
+
+			for _, column := range []int{2, 3} {
+				metaData := columns[column].MetaData
+				counts := pageCounts(metaData)
+				assert.Len(t, counts, 1)
+				assert.Greater(t, counts[dictEncoding], int32(0))
+				assert.ElementsMatch(t, []parquet.Encoding{parquet.Encoding_RLE, parquet.Encoding_PLAIN, dictEncoding}, metaData.Encodings)
+			}
+		})
+	}
+}
+
+func TestAutoEncoding(t *testing.T) {
+	type Entry struct {
+		ID       int64   `parquet:"name=id, type=INT64"`
+		Category *string `parquet:"name=category, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Random   int64   `parquet:"name=random, type=INT64"`
+		Key      string  `parquet:"name=key, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Measure  float64 `parquet:"name=measure, type=DOUBLE"`
+		Flag     bool    `parquet:"name=flag, type=BOOLEAN"`
+		Tagged   int32   `parquet:"name=tagged, type=INT32, encoding=PLAIN_DICTIONARY"`
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	entries := make([]Entry, 5000)
+	for i := range entries {
+		entries[i] = Entry{
+			ID:      int64(1000000 + i),
+			Random:  rnd.Int63(),
+			Key:     fmt.Sprintf("key%08d", i),
+			Measure: 20 + math.Sin(float64(i)/100),
+			Flag:    i%3 == 0,
+			Tagged:  int32(i),
+		}
+		if i%7 != 0 {
+			category := []string{"red", "green", "blue"}[i%3]
+			entries[i].Category = &category
+		}
+	}
+
+	var buf bytes.Buffer
+	pw, err := NewParquetWriterFromWriter(&buf, new(Entry), 2)
+	assert.NoError(t, err)
+	pw.AutoEncoding = true
+	for _, entry := range entries {
+		assert.NoError(t, pw.Write(entry))
+	}
+	assert.NoError(t, pw.WriteStop())
+
+	pf, err := buffer.NewBufferFile(buf.Bytes())
+	assert.NoError(t, err)
+	pr, err := reader.NewParquetReader(pf, new(Entry), 1)
+	assert.NoError(t, err)
+	res := make([]Entry, len(entries))
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, entries, res)
+
+	expected := []parquet.Encoding{
+		parquet.Encoding_DELTA_BINARY_PACKED,
+		parquet.Encoding_PLAIN_DICTIONARY,
+		parquet.Encoding_PLAIN,
+		parquet.Encoding_DELTA_BYTE_ARRAY,
+		parquet.Encoding_BYTE_STREAM_SPLIT,
+		parquet.Encoding_PLAIN,
+		parquet.Encoding_PLAIN_DICTIONARY,
+	}
+	for _, rowGroup := range pr.Footer.RowGroups {
+		for i, chunk := range rowGroup.Columns {
+			assert.NotEmpty(t, chunk.MetaData.EncodingStats)
+			for _, stats := range chunk.MetaData.EncodingStats {
+				if stats.PageType == parquet.PageType_DATA_PAGE {
+					assert.Equal(t, expected[i], stats.Encoding, "%v", chunk.MetaData.PathInSchema)
+				}
+			}
+		}
+	}
+}
+
+func TestColumnCompression(t *testing.T) {
+	type Address struct {
+		City string `parquet:"name=city, type=BYTE_ARRAY, convertedtype=UTF8"`
+	}
+	type Entry struct {
+		ID       int64   `parquet:"name=id, type=INT64"`
+		Text     string  `parquet:"name=text, type=BYTE_ARRAY, convertedtype=UTF8, compression=zstd, compressionlevel=19"`
+		Hot      int32   `parquet:"name=hot, type=INT32, compression=LZ4_RAW"`
+		Raw      int32   `parquet:"name=raw, type=INT32, compression=gzip"`
+		Category string  `parquet:"name=category, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY, compression=gzip, compressionlevel=9"`
+		Note     string  `parquet:"name=note, type=BYTE_ARRAY, convertedtype=UTF8"`
+		Address  Address `parquet:"name=address"`
+	}
+
+	entries := make([]Entry, 1000)
+	for i := range entries {
+		entries[i] = Entry{
+			ID:       int64(i),
+			Text:     fmt.Sprintf("text of the entry %v", i),
+			Hot:      int32(i * 3),
+			Raw:      int32(i * 5),
+			Category: []string{"red", "green", "blue"}[i%3],
+			Note:     fmt.Sprintf("note %v", i%17),
+			Address:  Address{City: fmt.Sprintf("city%v", i%10)},
+		}
+	}
+	newWriter := func(buf *bytes.Buffer, columnCompressions map[string]ColumnCompression) *ParquetWriter {
+		pw, err := NewParquetWriterFromWriter(buf, new(Entry), 2)
+		assert.NoError(t, err)
+		pw.PageSize = 1024
+		pw.ColumnCompressions = columnCompressions
+		return pw
+	}
+
+	var buf bytes.Buffer
+	pw := newWriter(&buf, map[string]ColumnCompression{
+		"note":         {Codec: parquet.CompressionCodec_BROTLI, Level: 11},
+		"raw":          {Codec: parquet.CompressionCodec_UNCOMPRESSED},
+		"address.city": {Codec: parquet.CompressionCodec_ZSTD, Level: 1},
+	})
+	for _, entry := range entries {
+		assert.NoError(t, pw.Write(entry))
+	}
+	assert.NoError(t, pw.WriteStop())
+
+	pf, err := buffer.NewBufferFile(buf.Bytes())
+	assert.NoError(t, err)
+	pr, err := reader.NewParquetReader(pf, new(Entry), 1)
+	assert.NoError(t, err)
+	res := make([]Entry, len(entries))
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, entries, res)
+
+	expected := []parquet.CompressionCodec{
+		parquet.CompressionCodec_SNAPPY,
+		parquet.CompressionCodec_ZSTD,
+		parquet.CompressionCodec_LZ4_RAW,
+		parquet.CompressionCodec_UNCOMPRESSED,
+		parquet.CompressionCodec_GZIP,
+		parquet.CompressionCodec_BROTLI,
+		parquet.CompressionCodec_ZSTD,
+	}
+	for _, rowGroup := range pr.Footer.RowGroups {
+		for i, chunk := range rowGroup.Columns {
+			assert.Equal(t, expected[i], chunk.MetaData.Codec, "%v", chunk.MetaData.PathInSchema)
+		}
+	}
+
+	for _, columnCompressions := range []map[string]ColumnCompression{
+		{"address": {Codec: parquet.CompressionCodec_ZSTD}},
+		{"id": {Codec: parquet.CompressionCodec_ZSTD, Level: 23}},
+		{"id": {Codec: parquet.CompressionCodec_SNAPPY, Level: 1}},
+		{"id": {Codec: parquet.CompressionCodec_LZO}},
+	} {
+		pw := newWriter(new(bytes.Buffer), columnCompressions)
+		assert.NoError(t, pw.Write(entries[0]))
+		assert.Error(t, pw.WriteStop(), "%v", columnCompressions)
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	type Entry struct {
+		ID       int64  `parquet:"name=id, type=INT64"`
+		Category string `parquet:"name=category, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	}
+
+	zstd, err := compress.LookupCodec(parquet.CompressionCodec_ZSTD)
+	assert.NoError(t, err)
+	defer compress.RegisterCodec(parquet.CompressionCodec_ZSTD, zstd)
+
+	var calls int
+	var compressErr error
+	compress.RegisterCodec(parquet.CompressionCodec_ZSTD, &compress.Compressor{
+		CompressTo: func(dst, src []byte) ([]byte, error) {
+			calls++
+			if compressErr != nil {
+				return nil, compressErr
+			}
+			return zstd.CompressTo(dst, src)
+		},
+		Uncompress: zstd.Uncompress,
+	})
+
+	write := func() ([]byte, error) {
+		var buf bytes.Buffer
+		pw, err := NewParquetWriterFromWriter(&buf, new(Entry), 1)
+		assert.NoError(t, err)
+		pw.CompressionType = parquet.CompressionCodec_ZSTD
+		for i := 0; i < 100; i++ {
+			assert.NoError(t, pw.Write(Entry{ID: int64(i), Category: fmt.Sprint(i % 3)}))
+		}
+		err = pw.WriteStop()
+		return buf.Bytes(), err
+	}
+
+	data, err := write()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	pf, err := buffer.NewBufferFile(data)
+	assert.NoError(t, err)
+	pr, err := reader.NewParquetReader(pf, new(Entry), 1)
+	assert.NoError(t, err)
+	res := make([]Entry, 100)
+	assert.NoError(t, pr.Read(&res))
+	assert.Equal(t, "2", res[98].Category)
+
+	compressErr = errors.New("device error")
+	_, err = write()
+	assert.ErrorContains(t, err, "device error")
+}
+
+func TestSortingColumns(t *testing.T) {
+	type Entry struct {
+		Group  *string `parquet:"name=group, type=BYTE_ARRAY, convertedtype=UTF8"`
+		ID     int64   `parquet:"name=id, type=INT64"`
+		Random int64   `parquet:"name=random, type=INT64"`
+		Tags   []int32 `parquet:"name=tags, type=INT32, repetitiontype=REPEATED"`
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	entries := make([]Entry, 5000)
+	for i, j := range rnd.Perm(len(entries)) {
+		entries[i] = Entry{ID: int64(j), Random: rnd.Int63(), Tags: []int32{int32(j)}}
+		if j%5 != 0 {
+			group := []string{"a", "b"}[j%2]
+			entries[i].Group = &group
+		}
+	}
+
+	var buf bytes.Buffer
+	pw, err := NewParquetWriterFromWriter(&buf, new(Entry), 2)
+	assert.NoError(t, err)
+	pw.PageSize = 1024
+	pw.SortingColumns = []SortingColumn{{Path: "group", NullsFirst: true}, {Path: "id", Descending: true}}
+	for _, entry := range entries {
+		assert.NoError(t, pw.Write(entry))
+	}
+	assert.NoError(t, pw.WriteStop())
+
+	pf, err := buffer.NewBufferFile(buf.Bytes())
+	assert.NoError(t, err)
+	pr, err := reader.NewParquetReader(pf, new(Entry), 1)
+	assert.NoError(t, err)
+	res := make([]Entry, len(entries))
+	assert.NoError(t, pr.Read(&res))
+	for i := 1; i < len(res); i++ {
+		a, b := res[i-1], res[i]
+		if a.Group == nil && b.Group == nil || a.Group != nil && b.Group != nil && *a.Group == *b.Group {
+			assert.Greater(t, a.ID, b.ID)
+		} else {
+			assert.True(t, a.Group == nil || b.Group != nil && *a.Group < *b.Group)
+		}
+		assert.Equal(t, []int32{int32(b.ID)}, b.Tags)
+	}
+
+	assert.Len(t, pr.Footer.RowGroups, 1)
+	rowGroup := pr.Footer.RowGroups[0]
+	assert.Equal(t, []*parquet.SortingColumn{{ColumnIdx: 0, NullsFirst: true}, {ColumnIdx: 1, Descending: true}}, rowGroup.SortingColumns)
+	expected := []parquet.BoundaryOrder{parquet.BoundaryOrder_ASCENDING, parquet.BoundaryOrder_UNORDERED, parquet.BoundaryOrder_UNORDERED, parquet.BoundaryOrder_UNORDERED}
+	for i, chunk := range rowGroup.Columns {
+		columnIndex, err := reader.ReadColumnIndex(pr.PFile, chunk)
+		assert.NoError(t, err)
+		assert.Equal(t, expected[i], columnIndex.BoundaryOrder, "%v", chunk.MetaData.PathInSchema)
+	}
+
+	pw, err = NewParquetWriterFromWriter(&buf, new(Entry), 1)
+	assert.NoError(t, err)
+	pw.SortingColumns = []SortingColumn{{Path: "tags"}}
+	assert.NoError(t, pw.Write(entries[0]))
+	assert.EqualError(t, pw.WriteStop(), "repeated column tags in SortingColumns")
+}
+
+func TestBoundaryOrder(t *testing.T) {
+	int64Type := parquet.Type_INT64
+	pages := func(values ...interface{}) []*layout.Page {
+		var res []*layout.Page
+		for i := 0; i < len(values); i += 2 {
+			page := layout.NewDataPage()
+			page.Schema = &parquet.SchemaElement{Type: &int64Type}
+			page.MinVal, page.MaxVal = values[i], values[i+1]
+			res = append(res, page)
+		}
+		return res
+	}
+	assert.Equal(t, parquet.BoundaryOrder_ASCENDING, boundaryOrder(pages(int64(1), int64(3), nil, nil, int64(2), int64(5))))
+	assert.Equal(t, parquet.BoundaryOrder_DESCENDING, boundaryOrder(pages(int64(4), int64(5), int64(1), int64(5))))
+	assert.Equal(t, parquet.BoundaryOrder_UNORDERED, boundaryOrder(pages(int64(1), int64(3), int64(0), int64(4))))
+	assert.Equal(t, parquet.BoundaryOrder_UNORDERED, boundaryOrder(pages(nil, nil)))
+}